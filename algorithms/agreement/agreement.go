@@ -0,0 +1,115 @@
+// Package agreement implements Byzantine agreement protocols on complete graphs with synchronous rounds:
+// the oral messages algorithm OM(m) of Lamport, Shostak and Pease and the Phase-King algorithm.
+//
+// Byzantine processes are set up in the network, which rewrites or suppresses their messages,
+// so they run the same work functions as the correct ones. Values are the last arguments of messages,
+// as adversary strategies expect. Rounds last the greatest link latency, so every message sent
+// at the beginning of a round is delivered by its end. Decisions of the correct processes are reported
+// to the network tracer as decide events in the slot 0, so they can be checked by World.CheckAgreement.
+package agreement
+
+import (
+	"fmt"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// State is the outcome of a process, it is embedded into contexts of all the algorithms.
+// Input is the initial value of the process, if it has one, Decision is the value decided by it.
+type State struct {
+	Input    int32
+	HasInput bool
+	Decision int32
+	Decided  bool
+}
+
+// Result returns the outcome of the process.
+func (s *State) Result() *State {
+	return s
+}
+
+// Outcome is implemented by contexts of all the agreement algorithms.
+type Outcome interface {
+	Result() *State
+}
+
+// decide records the decision of the process. Only decisions of the correct processes are reported.
+func (s *State) decide(dp *process.Process, name string, value int32) {
+	if s.Decided {
+		return
+	}
+	s.Decision, s.Decided = value, true
+	if dp.Network.Byzantine(dp.Node) != nil {
+		return
+	}
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Decide, From: dp.Node, To: dp.Node,
+		Message: fmt.Sprintf("%v 0 %v", name, value)})
+	logging.Infof("[%v]: decided %v", dp.Node, value)
+}
+
+// Verify checks the outcomes of the correct processes with the context key: all of them decide the same value
+// and, if all the correct processes with inputs have the same one, they decide it. Byzantine processes are ignored.
+// It returns the decided value.
+func Verify(ps []*process.Process, key string) (int32, error) {
+	var res, input int32
+	decided, inputs, valid := false, false, true
+	for _, dp := range ps {
+		if dp == nil || dp.Network.Byzantine(dp.Node) != nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return 0, fmt.Errorf("process %v has no agreement context %v", dp.Node, key)
+		}
+		s := o.Result()
+		if !s.Decided {
+			return 0, fmt.Errorf("process %v has not decided", dp.Node)
+		}
+		if decided && s.Decision != res {
+			return 0, fmt.Errorf("process %v decided %v, others decided %v", dp.Node, s.Decision, res)
+		}
+		res, decided = s.Decision, true
+		if s.HasInput {
+			if inputs && s.Input != input {
+				valid = false
+			}
+			input, inputs = s.Input, true
+		}
+	}
+	if inputs && valid && res != input {
+		return 0, fmt.Errorf("correct processes decided %v, their input is %v", res, input)
+	}
+	return res, nil
+}
+
+// round returns the length of a round: the value set in the context, if any,
+// or the greatest latency of links between the processes otherwise.
+func round(dp *process.Process, r int64) int64 {
+	if r > 0 {
+		return r
+	}
+	var res int64 = 1
+	all := dp.Network.Nodes()
+	for _, u := range all {
+		for _, v := range all {
+			if l := int64(dp.Network.GetLink(u, v)); l > res {
+				res = l
+			}
+		}
+	}
+	return res
+}
+
+// majority returns the value held by more than a half of the values and false if there is none.
+func majority(values []int32) (int32, bool) {
+	counts := make(map[int32]int)
+	for _, v := range values {
+		counts[v]++
+		if 2*counts[v] > len(values) {
+			return v, true
+		}
+	}
+	return 0, false
+}
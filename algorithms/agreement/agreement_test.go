@@ -0,0 +1,143 @@
+package agreement
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/byzantine"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+		want   int32
+	}{
+		{"OM", "../../configs/agreement/om.data", "OM", 1},
+		{"PhaseKing", "../../configs/agreement/phaseking.data", "PhaseKing", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a *world.Agreement
+			w := sample.Run(t, tt.config, 1000, false, func(w *world.World) { a = w.CheckAgreement() })
+			got, err := Verify(w.ProcessesList, tt.key)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+			if c := a.Conflicts(); len(c) > 0 {
+				t.Errorf("Agreement.Conflicts() = %v", c)
+			}
+		})
+	}
+}
+
+// agree runs n processes with random latencies and inputs, f random processes of them are Byzantine
+// with the strategy, and checks the agreement and the validity of the decisions of the correct ones.
+func agree(t *testing.T, function string, key string, n int32, f int32, strategy string, rng *rand.Rand) {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 10000})
+	defer w.Stop()
+	a := w.CheckAgreement()
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte(function))
+	}
+	for i := int32(0); i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			w.Network.CreateLink(i, j, true, 1+rng.Int31n(3))
+		}
+	}
+	for _, i := range rng.Perm(int(n))[:f] {
+		if err := w.SetByzantine(int32(i), int32(i), strategy); err != nil {
+			t.Fatalf("World.SetByzantine() error = %v", err)
+		}
+	}
+	if function == "OM" {
+		w.Network.SendMessage(-1, -1, messages.NewMessageByType("OM_INIT", rng.Int31n(2)))
+	} else {
+		for i := int32(0); i < n; i++ {
+			w.Network.SendMessage(-1, i, messages.NewMessageByType("PHASEKING_INIT", rng.Int31n(2)))
+		}
+	}
+	w.Wait(1000)
+
+	if _, err := Verify(w.ProcessesList, key); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if c := a.Conflicts(); len(c) > 0 {
+		t.Errorf("Agreement.Conflicts() = %v", c)
+	}
+}
+
+func TestTraitors(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+		sizes    []int32
+		bound    func(n int32) int32
+	}{
+		{"OM", "OM", []int32{1, 2, 4, 7, 10}, func(n int32) int32 { return (n - 1) / 3 }},
+		{"PHASEKING", "PhaseKing", []int32{1, 3, 5, 9, 13}, func(n int32) int32 { return (n - 1) / 4 }},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range tt.sizes {
+			for _, s := range byzantine.Strategies() {
+				for i := 0; i < 5; i++ {
+					t.Run(fmt.Sprintf("%v%v/%v/%v", tt.function, n, s, i), func(t *testing.T) {
+						agree(t, tt.function, tt.key, n, tt.bound(n), s, rng)
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		states    []State
+		byzantine []int32
+		want      int32
+		wantErr   bool
+	}{
+		{"Agreed", []State{{Input: 1, HasInput: true, Decision: 1, Decided: true}, {Decision: 1, Decided: true}}, nil, 1, false},
+		{"Disagreed", []State{{Decision: 1, Decided: true}, {Decision: 0, Decided: true}}, nil, 0, true},
+		{"Undecided", []State{{Decision: 1, Decided: true}, {}}, nil, 0, true},
+		{"Invalid", []State{{Input: 1, HasInput: true, Decision: 0, Decided: true}}, nil, 0, true},
+		{"DifferentInputs", []State{{Input: 1, HasInput: true, Decided: true}, {Input: 0, HasInput: true, Decided: true}}, nil, 0, false},
+		{"Byzantine", []State{{Decision: 1, Decided: true}, {}}, []int32{1}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{})
+			defer w.Stop()
+			for i, s := range tt.states {
+				w.CreateProcess(int32(i))
+				w.AssignWorkFunction(int32(i), []byte("OM"))
+				w.ProcessesList[i].Context["OM"].(*OM).State = s
+			}
+			for _, i := range tt.byzantine {
+				w.SetByzantine(i, i, byzantine.Silent)
+			}
+			got, err := Verify(w.ProcessesList, "OM")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
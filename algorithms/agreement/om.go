@@ -0,0 +1,158 @@
+package agreement
+
+import (
+	"fmt"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// OM is a context of the oral messages algorithm OM(m).
+// Commander is the node of the commander, M is the number of traitors tolerated, a third of the processes by default,
+// Round is the length of a round in ticks, the greatest link latency by default,
+// and Default is the value decided when there is no majority.
+type OM struct {
+	State
+	Commander int32
+	M         int32
+	Round     int64
+	Default   int32
+	started   bool
+	values    map[string]int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "OM",
+		Description: "Lamport–Shostak–Pease oral messages algorithm OM(m) on a complete graph, OM_INIT starts it with the value of the commander as an argument",
+		Work:        OMWorkFunction,
+		Contexts: map[string]context.Factory{
+			"OM": func(int32) context.Context {
+				return &OM{M: -1, values: make(map[string]int32)}
+			},
+		},
+	})
+}
+
+// traitors returns the number of traitors tolerated.
+func (ctx *OM) traitors(dp *process.Process) int32 {
+	if ctx.M >= 0 {
+		return ctx.M
+	}
+	return (int32(len(dp.Network.Nodes())) - 1) / 3
+}
+
+// key returns the key of the path in the tree of values.
+func key(path []int32) string {
+	return fmt.Sprint(path)
+}
+
+// valid reports whether the path of a message sent by the process is a chain of distinct relays
+// from the commander to the sender, not longer than m+1 and not passing through the receiver.
+func (ctx *OM) valid(dp *process.Process, path []int32, from int32) bool {
+	if len(path) == 0 || int32(len(path)) > ctx.traitors(dp)+1 || path[0] != ctx.Commander || path[len(path)-1] != from {
+		return false
+	}
+	seen := map[int32]bool{dp.Node: true}
+	for _, v := range path {
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+// relay sends the value received along the path to the processes which are not on the path yet.
+func (ctx *OM) relay(dp *process.Process, path []int32, value int32) {
+	seen := make(map[int32]bool)
+	for _, v := range path {
+		seen[v] = true
+	}
+	args := append(append([]int32{}, path...), value)
+	for _, v := range dp.Network.Nodes() {
+		if !seen[v] {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("OM_VALUE", args...))
+		}
+	}
+}
+
+// resolve returns the value the process attributes to the last relay of the path: the value received along
+// the path itself for the paths of m+1 relays, or the majority of it and the values the other processes
+// report to have received along the path otherwise.
+func (ctx *OM) resolve(dp *process.Process, path []int32) int32 {
+	v, ok := ctx.values[key(path)]
+	if !ok {
+		v = ctx.Default
+	}
+	if int32(len(path)) > ctx.traitors(dp) {
+		return v
+	}
+	seen := map[int32]bool{dp.Node: true}
+	for _, u := range path {
+		seen[u] = true
+	}
+	values := []int32{v}
+	for _, u := range dp.Network.Nodes() {
+		if !seen[u] {
+			values = append(values, ctx.resolve(dp, append(append([]int32{}, path...), u)))
+		}
+	}
+	if res, ok := majority(values); ok {
+		return res
+	}
+	return ctx.Default
+}
+
+// OMWorkFunction handles OM_INIT, OM_VALUE and OM_DECIDE messages.
+// The commander sends its value to the lieutenants, which relay every value received along a path of relays
+// to the processes not on the path, until paths of m+1 relays. After m+1 rounds every lieutenant decides
+// the value it attributes to the commander by recursive majorities over the tree of received values.
+func OMWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("OM"), s) {
+		return false
+	}
+	ctx := process.Ctx[*OM](dp, "OM")
+	switch string(s) {
+	case "OM_INIT":
+		v := m.GetInt32()
+		if ctx.started {
+			break
+		}
+		ctx.started = true
+		if dp.Node == ctx.Commander {
+			ctx.Input, ctx.HasInput = v, true
+			ctx.relay(dp, []int32{dp.Node}, v)
+			ctx.decide(dp, "OM", v)
+			break
+		}
+		deadline := int64(ctx.traitors(dp)+1)*round(dp, ctx.Round) + 1
+		dp.Network.SendTimeout(dp.Node, deadline, messages.NewMessageByType("OM_DECIDE"))
+	case "OM_VALUE":
+		args := make([]int32, 0)
+		for m.Ptr < len(m.Body) {
+			args = append(args, m.GetInt32())
+		}
+		if len(args) < 2 || ctx.Decided {
+			break
+		}
+		path, value := args[:len(args)-1], args[len(args)-1]
+		if !ctx.valid(dp, path, m.From) {
+			break
+		}
+		if _, ok := ctx.values[key(path)]; ok {
+			break
+		}
+		ctx.values[key(path)] = value
+		if int32(len(path)) <= ctx.traitors(dp) {
+			ctx.relay(dp, append(append([]int32{}, path...), dp.Node), value)
+		}
+	case "OM_DECIDE":
+		ctx.decide(dp, "OM", ctx.resolve(dp, []int32{ctx.Commander}))
+	}
+	return true
+}
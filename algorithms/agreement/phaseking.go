@@ -0,0 +1,144 @@
+package agreement
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// PhaseKing is a context of the Phase-King algorithm for binary values.
+// F is the number of traitors tolerated, a quarter of the processes by default,
+// Round is the length of a round in ticks, the greatest link latency by default.
+type PhaseKing struct {
+	State
+	F          int32
+	Round      int64
+	preference int32
+	round      int32
+	majority   int32
+	count      int
+	values     map[int32]map[int32]int32
+	kings      map[int32]int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "PHASEKING",
+		Description: "Phase-King binary Byzantine agreement on a complete graph, PHASEKING_INIT starts it with the input value as an argument",
+		Work:        PhaseKingWorkFunction,
+		Contexts: map[string]context.Factory{
+			"PhaseKing": func(int32) context.Context {
+				return &PhaseKing{F: -1, values: make(map[int32]map[int32]int32), kings: make(map[int32]int32)}
+			},
+		},
+	})
+}
+
+// traitors returns the number of traitors tolerated.
+func (ctx *PhaseKing) traitors(dp *process.Process) int32 {
+	if ctx.F >= 0 {
+		return ctx.F
+	}
+	return (int32(len(dp.Network.Nodes())) - 1) / 4
+}
+
+// binary normalizes the value.
+func binary(v int32) int32 {
+	if v != 0 {
+		return 1
+	}
+	return 0
+}
+
+// wait schedules the end of the current round, a tick after all the messages of the round are delivered.
+func (ctx *PhaseKing) wait(dp *process.Process) {
+	dp.Network.SendTimeout(dp.Node, round(dp, ctx.Round)+1, messages.NewMessageByType("PHASEKING_ROUND", ctx.round))
+}
+
+// king returns the king of the phase, the kings of f+1 phases are distinct.
+func king(dp *process.Process, phase int32) int32 {
+	all := dp.Network.Nodes()
+	return all[int(phase)%len(all)]
+}
+
+// broadcast sends the message to all the processes, including the sender.
+func broadcast(dp *process.Process, t string, args ...int32) {
+	for _, v := range dp.Network.Nodes() {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType(t, args...))
+	}
+}
+
+// PhaseKingWorkFunction handles PHASEKING_INIT, PHASEKING_VALUE, PHASEKING_KING and PHASEKING_ROUND messages.
+// Every one of f+1 phases consists of two rounds. In the first one the processes exchange their preferences
+// and find the majority value. In the second one the king of the phase sends its majority value, which
+// the processes prefer unless their majority is held by more than n/2+f processes. The processes decide
+// their preferences after the last phase.
+func PhaseKingWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("PHASEKING"), s) {
+		return false
+	}
+	ctx := process.Ctx[*PhaseKing](dp, "PhaseKing")
+	switch string(s) {
+	case "PHASEKING_INIT":
+		v := m.GetInt32()
+		if ctx.HasInput {
+			break
+		}
+		ctx.Input, ctx.HasInput = v, true
+		ctx.preference = binary(v)
+		broadcast(dp, "PHASEKING_VALUE", 0, ctx.preference)
+		ctx.wait(dp)
+	case "PHASEKING_VALUE":
+		phase, v := m.GetInt32(), m.GetInt32()
+		if ctx.values[phase] == nil {
+			ctx.values[phase] = make(map[int32]int32)
+		}
+		if _, ok := ctx.values[phase][m.From]; !ok {
+			ctx.values[phase][m.From] = binary(v)
+		}
+	case "PHASEKING_KING":
+		phase, v := m.GetInt32(), m.GetInt32()
+		if phase >= 0 && king(dp, phase) == m.From {
+			ctx.kings[phase] = binary(v)
+		}
+	case "PHASEKING_ROUND":
+		if m.GetInt32() != ctx.round || ctx.Decided {
+			break
+		}
+		phase := ctx.round / 2
+		if ctx.round%2 == 0 {
+			counts := [2]int{}
+			for _, v := range ctx.values[phase] {
+				counts[v]++
+			}
+			ctx.majority, ctx.count = 0, counts[0]
+			if counts[1] > counts[0] {
+				ctx.majority, ctx.count = 1, counts[1]
+			}
+			if king(dp, phase) == dp.Node {
+				broadcast(dp, "PHASEKING_KING", phase, ctx.majority)
+			}
+			ctx.round++
+			ctx.wait(dp)
+			break
+		}
+		ctx.preference = ctx.majority
+		if v, ok := ctx.kings[phase]; ok {
+			ctx.preference = v
+		}
+		if 2*ctx.count > len(dp.Network.Nodes())+2*int(ctx.traitors(dp)) {
+			ctx.preference = ctx.majority
+		}
+		if phase >= ctx.traitors(dp) {
+			ctx.decide(dp, "PHASEKING", ctx.preference)
+			break
+		}
+		ctx.round++
+		broadcast(dp, "PHASEKING_VALUE", phase+1, ctx.preference)
+		ctx.wait(dp)
+	}
+	return true
+}
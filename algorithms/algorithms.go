@@ -0,0 +1,17 @@
+// Package algorithms links all the algorithm packages into the model.
+// Each package registers its work functions in init, so adding an algorithm
+// only requires importing its package here.
+package algorithms
+
+import (
+	// Algorithms are registered by their init functions.
+	_ "github.com/trmigor/distr-model/algorithms/agreement"
+	_ "github.com/trmigor/distr-model/algorithms/broadcast"
+	_ "github.com/trmigor/distr-model/algorithms/consensus"
+	_ "github.com/trmigor/distr-model/algorithms/election"
+	_ "github.com/trmigor/distr-model/algorithms/gossip"
+	_ "github.com/trmigor/distr-model/algorithms/mutex"
+	_ "github.com/trmigor/distr-model/algorithms/routing"
+	_ "github.com/trmigor/distr-model/algorithms/setx"
+	_ "github.com/trmigor/distr-model/algorithms/spantree"
+)
@@ -0,0 +1,116 @@
+// Package broadcast implements broadcast algorithms: best-effort, reliable (eager relay), FIFO and causal
+// broadcast on connected graphs and total-order broadcast by a sequencer and by Lamport timestamps
+// on complete graphs.
+//
+// A process broadcasts the argument of every <NAME>_BROADCAST message it receives. Messages are identified
+// by their origins and sequence numbers starting from 1, values are the last arguments of messages.
+// Broadcasts and deliveries are reported to the network tracer as broadcast and accept events,
+// so that the properties guaranteed by the algorithms can be checked by World.CheckBroadcast.
+package broadcast
+
+import (
+	"fmt"
+
+	props "github.com/trmigor/distr-model/internal/broadcast"
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Delivery is a broadcast message delivered to a process.
+type Delivery struct {
+	Origin int32
+	Seq    int32
+	Value  int32
+}
+
+// Log is the state of the process regarding broadcasts, it is embedded into contexts of all the algorithms.
+// Seq is the number of messages broadcast by the process, Delivered holds the messages delivered to it in order.
+type Log struct {
+	Seq       int32
+	Delivered []Delivery
+}
+
+func newLog() Log {
+	return Log{Delivered: make([]Delivery, 0)}
+}
+
+// Result returns the state of the process regarding broadcasts.
+func (l *Log) Result() *Log {
+	return l
+}
+
+// Outcome is implemented by contexts of all the broadcast algorithms.
+type Outcome interface {
+	Result() *Log
+}
+
+// broadcast starts a new message of the process and reports it. It returns the sequence number of the message.
+func (l *Log) broadcast(dp *process.Process, name string, value int32) int32 {
+	l.Seq++
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Broadcast, From: dp.Node, To: dp.Node,
+		Message: fmt.Sprintf("%v %v %v %v", name, dp.Node, l.Seq, value)})
+	return l.Seq
+}
+
+// deliver delivers the message to the process and reports it.
+func (l *Log) deliver(dp *process.Process, name string, origin int32, seq int32, value int32) {
+	l.Delivered = append(l.Delivered, Delivery{origin, seq, value})
+	logging.Infof("[%v]: delivered %v from %v", dp.Node, value, origin)
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Accept, From: dp.Node, To: dp.Node,
+		Message: fmt.Sprintf("%v %v %v %v", name, origin, seq, value)})
+}
+
+// Verify checks that all the processes with the context key have delivered the same messages
+// and returns the number of them.
+func Verify(ps []*process.Process, key string) (int, error) {
+	res := -1
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return 0, fmt.Errorf("process %v has no broadcast context %v", dp.Node, key)
+		}
+		n := len(o.Result().Delivered)
+		if res >= 0 && n != res {
+			return 0, fmt.Errorf("process %v delivered %v messages, others delivered %v", dp.Node, n, res)
+		}
+		res = n
+	}
+	if res < 0 {
+		res = 0
+	}
+	return res, nil
+}
+
+// id identifies a broadcast message.
+type id struct {
+	origin, seq int32
+}
+
+// send sends the message to the process itself and to all its neighbours.
+func send(dp *process.Process, m *messages.Message) {
+	dp.Network.SendMessage(dp.Node, dp.Node, m)
+	relay(dp, m.Body, dp.Node)
+}
+
+// relay sends the message body to all the neighbours of the process except the one it has been received from.
+func relay(dp *process.Process, body []byte, from int32) {
+	for _, v := range dp.Neighbours() {
+		if v != from {
+			dp.Network.SendBytes(dp.Node, v, body)
+		}
+	}
+}
+
+func init() {
+	props.Guarantee("BEB", props.Integrity, props.Validity)
+	props.Guarantee("RB", props.Integrity, props.Validity, props.Agreement)
+	props.Guarantee("FIFO", props.Integrity, props.Validity, props.Agreement, props.FIFO)
+	props.Guarantee("CAUSAL", props.Integrity, props.Validity, props.Agreement, props.FIFO, props.Causal)
+	props.Guarantee("SEQUENCER", props.Integrity, props.Validity, props.Agreement, props.FIFO, props.Total)
+	props.Guarantee("TOTAL", props.Integrity, props.Validity, props.Agreement, props.FIFO, props.Total)
+}
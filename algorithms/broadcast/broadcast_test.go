@@ -0,0 +1,169 @@
+package broadcast
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/broadcast"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+		want   int
+	}{
+		{"BEB", "../../configs/broadcast/beb.data", "BEB", 3},
+		{"RB", "../../configs/broadcast/rb.data", "RB", 3},
+		{"FIFO", "../../configs/broadcast/fifo.data", "FIFO", 4},
+		{"Causal", "../../configs/broadcast/causal.data", "Causal", 2},
+		{"Sequencer", "../../configs/broadcast/sequencer.data", "Sequencer", 4},
+		{"Total", "../../configs/broadcast/total.data", "Total", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *broadcast.Checker
+			w := sample.Run(t, tt.config, 1000, false, func(w *world.World) { c = w.CheckBroadcast() })
+			got, err := Verify(w.ProcessesList, tt.key)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+			if v := c.Violations(w.Nodes()); len(v) > 0 {
+				t.Errorf("Checker.Violations() = %v", v)
+			}
+		})
+	}
+}
+
+// values returns the values delivered to the process in order.
+func values(l *Log) []int32 {
+	res := make([]int32, 0)
+	for _, d := range l.Delivered {
+		res = append(res, d.Value)
+	}
+	return res
+}
+
+// TestOrder runs the samples of ordered broadcasts by the weaker algorithms, which deliver messages out of order.
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		from, to string
+		key      string
+		node     int32
+		want     []int32
+	}{
+		{"FIFO", "../../configs/broadcast/fifo.data", "FIFO", "RB", "RB", 4, []int32{32, 33, 31, 10}},
+		{"Causal", "../../configs/broadcast/causal.data", "CAUSAL", "FIFO", "FIFO", 3, []int32{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := filepath.Join(t.TempDir(), "config.data")
+			if err := ioutil.WriteFile(config, []byte(strings.ReplaceAll(string(data), tt.from, tt.to)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			w := world.NewWithOptions(world.Options{Seed: 1, MaxTicks: 1000})
+			defer w.Stop()
+			if !w.ParseConfig([]byte(config)) {
+				t.Fatalf("World.ParseConfig() = false")
+			}
+			l := w.ProcessesList[tt.node].Context[tt.key].(Outcome).Result()
+			if got := values(l); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("process %v delivered %v, want %v", tt.node, got, tt.want)
+			}
+		})
+	}
+}
+
+// run broadcasts random values from random processes at random moments on a random graph,
+// complete if required, with latencies changing meanwhile and checks the properties guaranteed by the algorithm.
+func run(t *testing.T, function string, key string, n int32, complete bool, rng *rand.Rand) {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 100000})
+	defer w.Stop()
+	c := w.CheckBroadcast()
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte(function))
+	}
+	links := make([][2]int32, 0)
+	for i := int32(1); i < n; i++ {
+		links = append(links, [2]int32{i, rng.Int31n(i)})
+	}
+	for i := int32(0); i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if complete || rng.Intn(3) == 0 {
+				links = append(links, [2]int32{i, j})
+			}
+		}
+	}
+	for _, l := range links {
+		w.Network.CreateLink(l[0], l[1], true, 1+rng.Int31n(5))
+	}
+	broadcasts := 1 + rng.Intn(3*int(n))
+	for i := 0; i < broadcasts; i++ {
+		w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType(function+"_BROADCAST", int32(i)))
+		w.Wait(int64(rng.Intn(4)))
+		if len(links) > 0 {
+			l := links[rng.Intn(len(links))]
+			w.Network.CreateLink(l[0], l[1], true, 1+rng.Int31n(5))
+		}
+	}
+	w.Wait(1000)
+
+	got, err := Verify(w.ProcessesList, key)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got != broadcasts {
+		t.Errorf("Verify() = %v, want %v", got, broadcasts)
+	}
+	if v := c.Violations(w.Nodes()); len(v) > 0 {
+		t.Errorf("Checker.Violations() = %v", v)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+		complete bool
+	}{
+		{"BEB", "BEB", true},
+		{"RB", "RB", false},
+		{"FIFO", "FIFO", false},
+		{"CAUSAL", "Causal", false},
+		{"SEQUENCER", "Sequencer", true},
+		{"TOTAL", "Total", true},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 3, 5, 8} {
+			for i := 0; i < 5; i++ {
+				t.Run(fmt.Sprintf("%v%v/%v", tt.function, n, i), func(t *testing.T) {
+					run(t, tt.function, tt.key, n, tt.complete, rng)
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package broadcast
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// BEB is a context of best-effort broadcast.
+type BEB struct {
+	Log
+}
+
+// RB is a context of reliable broadcast.
+type RB struct {
+	Log
+	seen map[id]bool
+}
+
+// FIFO is a context of FIFO reliable broadcast.
+type FIFO struct {
+	Log
+	seen    map[id]bool
+	next    map[int32]int32
+	pending map[id]int32
+}
+
+// Causal is a context of causal reliable broadcast.
+type Causal struct {
+	Log
+	seen      map[id]bool
+	delivered map[int32]int32
+	pending   []causalMessage
+}
+
+// causalMessage is a message waiting for the messages of its causal past.
+type causalMessage struct {
+	origin, seq int32
+	past        []int32
+	value       int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "BEB",
+		Description: "best-effort broadcast to the neighbours, BEB_BROADCAST broadcasts its argument",
+		Work:        BEBWorkFunction,
+		Contexts: map[string]context.Factory{
+			"BEB": func(int32) context.Context { return &BEB{Log: newLog()} },
+		},
+	})
+	registry.Register(registry.Algorithm{
+		Name:        "RB",
+		Description: "reliable broadcast by eager relay on a connected graph, RB_BROADCAST broadcasts its argument",
+		Work:        RBWorkFunction,
+		Contexts: map[string]context.Factory{
+			"RB": func(int32) context.Context { return &RB{Log: newLog(), seen: make(map[id]bool)} },
+		},
+	})
+	registry.Register(registry.Algorithm{
+		Name:        "FIFO",
+		Description: "FIFO reliable broadcast on a connected graph, FIFO_BROADCAST broadcasts its argument",
+		Work:        FIFOWorkFunction,
+		Contexts: map[string]context.Factory{
+			"FIFO": func(int32) context.Context {
+				return &FIFO{Log: newLog(), seen: make(map[id]bool), next: make(map[int32]int32), pending: make(map[id]int32)}
+			},
+		},
+	})
+	registry.Register(registry.Algorithm{
+		Name:        "CAUSAL",
+		Description: "causal reliable broadcast by vector clocks on a connected graph, CAUSAL_BROADCAST broadcasts its argument",
+		Work:        CausalWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Causal": func(int32) context.Context {
+				return &Causal{Log: newLog(), seen: make(map[id]bool), delivered: make(map[int32]int32)}
+			},
+		},
+	})
+}
+
+// BEBWorkFunction handles BEB_BROADCAST and BEB_MSG messages.
+// The origin sends the message to itself and to its neighbours, which deliver it.
+func BEBWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("BEB"), s) {
+		return false
+	}
+	ctx := process.Ctx[*BEB](dp, "BEB")
+	switch string(s) {
+	case "BEB_BROADCAST":
+		v := m.GetInt32()
+		seq := ctx.broadcast(dp, "BEB", v)
+		send(dp, messages.NewMessageByType("BEB_MSG", dp.Node, seq, v))
+	case "BEB_MSG":
+		origin, seq, v := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		ctx.deliver(dp, "BEB", origin, seq, v)
+	}
+	return true
+}
+
+// RBWorkFunction handles RB_BROADCAST and RB_MSG messages.
+// Every process relays a message to its neighbours the first time it receives the message and delivers it,
+// so the message reaches all the processes of a connected graph unless it is lost.
+func RBWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("RB"), s) {
+		return false
+	}
+	ctx := process.Ctx[*RB](dp, "RB")
+	switch string(s) {
+	case "RB_BROADCAST":
+		v := m.GetInt32()
+		seq := ctx.broadcast(dp, "RB", v)
+		dp.Network.SendMessage(dp.Node, dp.Node, messages.NewMessageByType("RB_MSG", dp.Node, seq, v))
+	case "RB_MSG":
+		origin, seq, v := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		if ctx.seen[id{origin, seq}] {
+			break
+		}
+		ctx.seen[id{origin, seq}] = true
+		relay(dp, m.Body, m.From)
+		ctx.deliver(dp, "RB", origin, seq, v)
+	}
+	return true
+}
+
+// FIFOWorkFunction handles FIFO_BROADCAST and FIFO_MSG messages.
+// Messages are relayed as by reliable broadcast, but the ones of every origin are delivered
+// in the order of their sequence numbers.
+func FIFOWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("FIFO"), s) {
+		return false
+	}
+	ctx := process.Ctx[*FIFO](dp, "FIFO")
+	switch string(s) {
+	case "FIFO_BROADCAST":
+		v := m.GetInt32()
+		seq := ctx.broadcast(dp, "FIFO", v)
+		dp.Network.SendMessage(dp.Node, dp.Node, messages.NewMessageByType("FIFO_MSG", dp.Node, seq, v))
+	case "FIFO_MSG":
+		origin, seq, v := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		if ctx.seen[id{origin, seq}] {
+			break
+		}
+		ctx.seen[id{origin, seq}] = true
+		relay(dp, m.Body, m.From)
+		ctx.pending[id{origin, seq}] = v
+		for {
+			next := id{origin, ctx.next[origin] + 1}
+			value, ok := ctx.pending[next]
+			if !ok {
+				break
+			}
+			delete(ctx.pending, next)
+			ctx.next[origin] = next.seq
+			ctx.deliver(dp, "FIFO", origin, next.seq, value)
+		}
+	}
+	return true
+}
+
+// deliverable reports whether all the messages of the causal past of the message are delivered.
+func (ctx *Causal) deliverable(c causalMessage) bool {
+	if ctx.delivered[c.origin] != c.seq-1 {
+		return false
+	}
+	for node, seq := range c.past {
+		if int32(node) != c.origin && ctx.delivered[int32(node)] < seq {
+			return false
+		}
+	}
+	return true
+}
+
+// CausalWorkFunction handles CAUSAL_BROADCAST and CAUSAL_MSG messages.
+// Messages are relayed as by reliable broadcast along with the vector clock of their origin: the numbers
+// of messages of every origin it has delivered. A message is delivered after all the messages counted
+// by its vector clock and the previous messages of its origin.
+func CausalWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("CAUSAL"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Causal](dp, "Causal")
+	switch string(s) {
+	case "CAUSAL_BROADCAST":
+		v := m.GetInt32()
+		seq := ctx.broadcast(dp, "CAUSAL", v)
+		a := []int32{dp.Node, seq}
+		for i := range dp.Network.QueueMap {
+			a = append(a, ctx.delivered[int32(i)])
+		}
+		a[2+dp.Node] = seq - 1
+		dp.Network.SendMessage(dp.Node, dp.Node, messages.NewMessageByType("CAUSAL_MSG", append(a, v)...))
+	case "CAUSAL_MSG":
+		a := m.GetInt32s()
+		if len(a) < 3 {
+			break
+		}
+		c := causalMessage{origin: a[0], seq: a[1], past: a[2 : len(a)-1], value: a[len(a)-1]}
+		if ctx.seen[id{c.origin, c.seq}] {
+			break
+		}
+		ctx.seen[id{c.origin, c.seq}] = true
+		relay(dp, m.Body, m.From)
+		ctx.pending = append(ctx.pending, c)
+		for delivered := true; delivered; {
+			delivered = false
+			for i, p := range ctx.pending {
+				if ctx.deliverable(p) {
+					ctx.pending = append(ctx.pending[:i], ctx.pending[i+1:]...)
+					ctx.delivered[p.origin] = p.seq
+					ctx.deliver(dp, "CAUSAL", p.origin, p.seq, p.value)
+					delivered = true
+					break
+				}
+			}
+		}
+	}
+	return true
+}
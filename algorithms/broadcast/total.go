@@ -0,0 +1,192 @@
+package broadcast
+
+import (
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Sequencer is a context of total-order broadcast by a sequencer.
+// Sequencer is the node of the process ordering the messages, 0 by default.
+type Sequencer struct {
+	Log
+	Sequencer int32
+	data      map[id]int32
+	orders    map[int32]id
+	next      int32
+	ordered   map[int32]int32
+	number    int32
+}
+
+// Total is a context of total-order broadcast by Lamport timestamps.
+type Total struct {
+	Log
+	clock  int64
+	latest map[int32]int64
+	queue  []stamped
+}
+
+// stamped is a message waiting for the messages with greater timestamps from all the processes.
+type stamped struct {
+	time        int64
+	origin, seq int32
+	value       int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "SEQUENCER",
+		Description: "total-order broadcast by a sequencer on a complete graph, SEQUENCER_BROADCAST broadcasts its argument",
+		Work:        SequencerWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Sequencer": func(int32) context.Context {
+				return &Sequencer{Log: newLog(), data: make(map[id]int32), orders: make(map[int32]id), ordered: make(map[int32]int32)}
+			},
+		},
+	})
+	registry.Register(registry.Algorithm{
+		Name:        "TOTAL",
+		Description: "total-order broadcast by Lamport timestamps and acknowledgements on a complete graph, TOTAL_BROADCAST broadcasts its argument",
+		Work:        TotalWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Total": func(int32) context.Context { return &Total{Log: newLog(), latest: make(map[int32]int64)} },
+		},
+		FIFO: true,
+	})
+}
+
+// all sends the message to all the processes, including the sender.
+func all(dp *process.Process, m *messages.Message) {
+	for _, v := range dp.Network.Nodes() {
+		dp.Network.SendMessage(dp.Node, v, m)
+	}
+}
+
+// order assigns numbers to the messages of the origin received by the sequencer in the order of their sequence numbers.
+func (ctx *Sequencer) order(dp *process.Process, origin int32) {
+	for {
+		next := id{origin, ctx.ordered[origin] + 1}
+		if _, ok := ctx.data[next]; !ok {
+			return
+		}
+		ctx.ordered[origin] = next.seq
+		ctx.number++
+		all(dp, messages.NewMessageByType("SEQUENCER_ORDER", ctx.number, next.origin, next.seq))
+	}
+}
+
+// deliverAll delivers the messages in the order of their numbers as soon as both the messages and the numbers are received.
+func (ctx *Sequencer) deliverAll(dp *process.Process) {
+	for {
+		next, ok := ctx.orders[ctx.next+1]
+		if !ok {
+			return
+		}
+		v, ok := ctx.data[next]
+		if !ok {
+			return
+		}
+		ctx.next++
+		delete(ctx.orders, ctx.next)
+		ctx.deliver(dp, "SEQUENCER", next.origin, next.seq, v)
+	}
+}
+
+// SequencerWorkFunction handles SEQUENCER_BROADCAST, SEQUENCER_MSG and SEQUENCER_ORDER messages.
+// The origin sends a message to all the processes, the sequencer numbers the messages of every origin in order
+// and sends the numbers to all the processes, which deliver the messages in the order of their numbers.
+func SequencerWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("SEQUENCER"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Sequencer](dp, "Sequencer")
+	switch string(s) {
+	case "SEQUENCER_BROADCAST":
+		v := m.GetInt32()
+		seq := ctx.broadcast(dp, "SEQUENCER", v)
+		all(dp, messages.NewMessageByType("SEQUENCER_MSG", dp.Node, seq, v))
+	case "SEQUENCER_MSG":
+		origin, seq, v := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		ctx.data[id{origin, seq}] = v
+		if dp.Node == ctx.Sequencer {
+			ctx.order(dp, origin)
+		}
+		ctx.deliverAll(dp)
+	case "SEQUENCER_ORDER":
+		number, origin, seq := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		if number > ctx.next {
+			ctx.orders[number] = id{origin, seq}
+		}
+		ctx.deliverAll(dp)
+	}
+	return true
+}
+
+// tick advances the Lamport clock of the process by a received timestamp.
+func (ctx *Total) tick(time int64) {
+	if time > ctx.clock {
+		ctx.clock = time
+	}
+	ctx.clock++
+}
+
+// deliverAll delivers the messages from the head of the queue ordered by timestamps and origins,
+// as soon as messages with greater timestamps are received from all the processes: links are FIFO,
+// so no message with a smaller timestamp can arrive later.
+func (ctx *Total) deliverAll(dp *process.Process) {
+	for len(ctx.queue) > 0 {
+		h := ctx.queue[0]
+		for _, v := range dp.Network.Nodes() {
+			if ctx.latest[v] <= h.time {
+				return
+			}
+		}
+		ctx.queue = ctx.queue[1:]
+		ctx.deliver(dp, "TOTAL", h.origin, h.seq, h.value)
+	}
+}
+
+// TotalWorkFunction handles TOTAL_BROADCAST, TOTAL_MSG and TOTAL_ACK messages.
+// The origin sends a message stamped with its Lamport time to all the processes, which acknowledge it to all
+// the processes with their Lamport time. Messages are delivered in the order of their timestamps and origins.
+func TotalWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("TOTAL"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Total](dp, "Total")
+	switch string(s) {
+	case "TOTAL_BROADCAST":
+		v := m.GetInt32()
+		seq := ctx.broadcast(dp, "TOTAL", v)
+		ctx.clock++
+		all(dp, messages.NewMessageByType("TOTAL_MSG", int32(ctx.clock), dp.Node, seq, v))
+	case "TOTAL_MSG":
+		time, origin, seq, v := int64(m.GetInt32()), m.GetInt32(), m.GetInt32(), m.GetInt32()
+		ctx.tick(time)
+		if time > ctx.latest[m.From] {
+			ctx.latest[m.From] = time
+		}
+		ctx.queue = append(ctx.queue, stamped{time, origin, seq, v})
+		sort.Slice(ctx.queue, func(i, j int) bool {
+			a, b := ctx.queue[i], ctx.queue[j]
+			return a.time < b.time || a.time == b.time && a.origin < b.origin
+		})
+		all(dp, messages.NewMessageByType("TOTAL_ACK", int32(ctx.clock)))
+		ctx.deliverAll(dp)
+	case "TOTAL_ACK":
+		time := int64(m.GetInt32())
+		ctx.tick(time)
+		if time > ctx.latest[m.From] {
+			ctx.latest[m.From] = time
+		}
+		ctx.deliverAll(dp)
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+// Package consensus implements consensus protocols on complete graphs:
+// single-decree Paxos, Multi-Paxos and Raft leader election with log replication.
+//
+// Values are proposed to any processes by <NAME>_PROPOSE messages with the value as an argument
+// and decided in slots of a replicated log: single-decree Paxos decides the slot 0 only.
+// Values are commands, so a value is decided at most once. Decisions are reported to the network tracer
+// as decide events, so they can be checked by World.CheckAgreement while the model runs.
+// Messages may be lost, the protocols retry after timeouts.
+package consensus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Noop is the value filling the slots of Multi-Paxos left empty by deposed leaders.
+const Noop = math.MinInt32
+
+// Replica is the state of the replicated log of a process, it is embedded into contexts of all the algorithms.
+// Log holds the values decided by the process in every slot, Proposed holds the values proposed to the process.
+type Replica struct {
+	Log      map[int32]int32
+	Proposed []int32
+}
+
+func newReplica() Replica {
+	return Replica{Log: make(map[int32]int32), Proposed: make([]int32, 0)}
+}
+
+// Result returns the state of the replicated log.
+func (r *Replica) Result() *Replica {
+	return r
+}
+
+// Outcome is implemented by contexts of all the consensus algorithms.
+type Outcome interface {
+	Result() *Replica
+}
+
+// decided reports whether the process knows the value decided in the slot.
+func (r *Replica) decided(slot int32) bool {
+	_, ok := r.Log[slot]
+	return ok
+}
+
+// contains reports whether the value is decided in any slot.
+func (r *Replica) contains(value int32) bool {
+	for _, v := range r.Log {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// decide records the value decided in the slot. Decisions are recorded once,
+// but every one of them is reported, so that contradicting decisions can be found by the tracer.
+func (r *Replica) decide(dp *process.Process, name string, slot int32, value int32) {
+	e := trace.Event{Tick: dp.Network.Tick, Kind: trace.Decide, From: dp.Node, To: dp.Node,
+		Message: fmt.Sprintf("%v %v %v", name, slot, value)}
+	dp.Network.Record(e)
+	if r.decided(slot) {
+		return
+	}
+	r.Log[slot] = value
+	logging.Infof("[%v]: decided %v in slot %v", dp.Node, format(value), slot)
+}
+
+// format returns the value for logs.
+func format(value int32) string {
+	if value == Noop {
+		return "noop"
+	}
+	return fmt.Sprint(value)
+}
+
+// Verify checks the replicated logs of the processes with the context key: the values decided in every slot
+// agree, every decided value has been proposed and no value is decided in several slots.
+// It returns the decided values by slots.
+func Verify(ps []*process.Process, key string) (map[int32]int32, error) {
+	res := make(map[int32]int32)
+	proposed := make(map[int32]bool)
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return nil, fmt.Errorf("process %v has no consensus context %v", dp.Node, key)
+		}
+		r := o.Result()
+		for _, v := range r.Proposed {
+			proposed[v] = true
+		}
+		for slot, v := range r.Log {
+			if d, ok := res[slot]; ok && d != v {
+				return nil, fmt.Errorf("process %v decided %v in slot %v, others decided %v", dp.Node, format(v), slot, format(d))
+			}
+			res[slot] = v
+		}
+	}
+	slots := make(map[int32]int32)
+	for slot, v := range res {
+		if v == Noop {
+			continue
+		}
+		if !proposed[v] {
+			return nil, fmt.Errorf("value %v decided in slot %v has not been proposed", v, slot)
+		}
+		if s, ok := slots[v]; ok {
+			return nil, fmt.Errorf("value %v is decided in slots %v and %v", v, s, slot)
+		}
+		slots[v] = slot
+	}
+	return res, nil
+}
+
+// majority returns the size of a quorum of the processes.
+func majority(dp *process.Process) int {
+	return len(dp.Network.Nodes())/2 + 1
+}
+
+// broadcast sends the message to all the processes, including the sender.
+func broadcast(dp *process.Process, t string, args ...int32) {
+	for _, v := range dp.Network.Nodes() {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType(t, args...))
+	}
+}
+
+// timeout returns the time of waiting for answers: the value set in the context, if any,
+// or twice the greatest link latency otherwise.
+func timeout(dp *process.Process, t int64) int64 {
+	if t > 0 {
+		return t
+	}
+	var res int64 = 1
+	for _, v := range dp.Neighbours() {
+		if l := 2 * int64(dp.Network.GetLink(dp.Node, v)); l > res {
+			res = l
+		}
+	}
+	return res
+}
+
+// remove deletes the first occurrence of the value from the list.
+func remove(values []int32, value int32) []int32 {
+	for i, v := range values {
+		if v == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+// sorted returns the sorted slots of the map, so that the processes act deterministically.
+func sorted[V any](m map[int32]V) []int32 {
+	res := make([]int32, 0, len(m))
+	for slot := range m {
+		res = append(res, slot)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
@@ -0,0 +1,162 @@
+package consensus
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+// values returns the decided values except Noop.
+func values(log map[int32]int32) []int32 {
+	res := make([]int32, 0)
+	for _, slot := range sorted(log) {
+		if log[slot] != Noop {
+			res = append(res, log[slot])
+		}
+	}
+	return res
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		key      string
+		periodic bool
+		want     []int32
+	}{
+		{"Paxos", "../../configs/consensus/paxos.data", "Paxos", false, []int32{20}},
+		{"MultiPaxos", "../../configs/consensus/multipaxos.data", "MultiPaxos", false, []int32{3, 1, 2, 4}},
+		{"Raft", "../../configs/consensus/raft.data", "Raft", true, []int32{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a *world.Agreement
+			w := sample.Run(t, tt.config, 1000, tt.periodic, func(w *world.World) { a = w.CheckAgreement() })
+			log, err := Verify(w.ProcessesList, tt.key)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got := values(log); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+			if c := a.Conflicts(); len(c) > 0 {
+				t.Errorf("Agreement.Conflicts() = %v", c)
+			}
+		})
+	}
+}
+
+// agree runs n processes with values proposed to random processes at random moments under message loss,
+// then lets them finish without losses and checks that all the values are decided by all the processes.
+func agree(t *testing.T, function string, key string, n int32, rate float64, rng *rand.Rand) {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 100000})
+	defer w.Stop()
+	a := w.CheckAgreement()
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte(function))
+	}
+	for i := int32(0); i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			w.Network.CreateLink(i, j, true, 1+rng.Int31n(5))
+		}
+	}
+	proposals := 1 + rng.Intn(2*int(n))
+	w.Network.SendMessage(-1, -1, messages.NewMessageByType(function+"_INIT"))
+	for i := 0; i < proposals; i++ {
+		w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType(function+"_PROPOSE", int32(i)))
+		w.Wait(int64(rng.Intn(4)))
+	}
+	w.Network.SetErrorRate(rate)
+	w.Wait(3000)
+	w.Network.SetErrorRate(0)
+	w.Wait(3000)
+
+	log, err := Verify(w.ProcessesList, key)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if c := a.Conflicts(); len(c) > 0 {
+		t.Fatalf("Agreement.Conflicts() = %v", c)
+	}
+	want := proposals
+	if function == "PAXOS" {
+		want = 1
+	}
+	if got := len(values(log)); got != want {
+		t.Errorf("Verify() = %v, want %v values", log, want)
+	}
+	for _, dp := range w.ProcessesList {
+		if got := dp.Context[key].(Outcome).Result().Log; !reflect.DeepEqual(got, log) {
+			t.Errorf("process %v decided %v, want %v", dp.Node, got, log)
+		}
+	}
+}
+
+func TestLoss(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+	}{
+		{"PAXOS", "Paxos"},
+		{"MULTIPAXOS", "MultiPaxos"},
+		{"RAFT", "Raft"},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 3, 5, 7} {
+			for _, rate := range []float64{0, 0.2, 0.4} {
+				t.Run(fmt.Sprintf("%v%v/%v", tt.function, n, rate), func(t *testing.T) {
+					agree(t, tt.function, tt.key, n, rate, rng)
+				})
+			}
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas []Replica
+		want     map[int32]int32
+		wantErr  bool
+	}{
+		{
+			"Agreed",
+			[]Replica{{Log: map[int32]int32{0: 5, 1: Noop}, Proposed: []int32{5}}, {Log: map[int32]int32{0: 5, 2: 7}, Proposed: []int32{7}}},
+			map[int32]int32{0: 5, 1: Noop, 2: 7},
+			false,
+		},
+		{"Disagreed", []Replica{{Log: map[int32]int32{0: 5}, Proposed: []int32{5, 7}}, {Log: map[int32]int32{0: 7}}}, nil, true},
+		{"NotProposed", []Replica{{Log: map[int32]int32{0: 5}, Proposed: []int32{7}}}, nil, true},
+		{"Twice", []Replica{{Log: map[int32]int32{0: 5, 1: 5}, Proposed: []int32{5}}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{})
+			defer w.Stop()
+			for i, r := range tt.replicas {
+				w.CreateProcess(int32(i))
+				w.AssignWorkFunction(int32(i), []byte("PAXOS"))
+				w.ProcessesList[i].Context["Paxos"].(*Paxos).Replica = r
+			}
+			got, err := Verify(w.ProcessesList, "Paxos")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
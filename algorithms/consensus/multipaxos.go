@@ -0,0 +1,311 @@
+package consensus
+
+import (
+	"math"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// acceptance is a value accepted in a slot with the ballot.
+type acceptance struct {
+	ballot, value int32
+}
+
+// MultiPaxos is a context of the Multi-Paxos algorithm. Every process is a proposer, an acceptor and a learner.
+// Timeout is the time of waiting for answers in ticks, twice the greatest link latency by default.
+type MultiPaxos struct {
+	Replica
+	Timeout  int64
+	pending  []int32
+	leading  bool
+	leader   bool
+	round    int32
+	ballot   int32
+	first    int32
+	promises map[int32]bool
+	found    map[int32]acceptance
+	next     int32
+	inflight map[int32]int32
+	votes    map[int32]map[int32]bool
+	learned  map[int32]map[int32]bool
+	attempts int64
+	epoch    int32
+	promised int32
+	accepted map[int32]acceptance
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "MULTIPAXOS",
+		Description: "Multi-Paxos on a complete graph, MULTIPAXOS_PROPOSE proposes its argument",
+		Work:        MultiPaxosWorkFunction,
+		Contexts: map[string]context.Factory{
+			"MultiPaxos": func(int32) context.Context {
+				return &MultiPaxos{
+					Replica:  newReplica(),
+					pending:  make([]int32, 0),
+					learned:  make(map[int32]map[int32]bool),
+					promised: -1,
+					accepted: make(map[int32]acceptance),
+				}
+			},
+		},
+	})
+}
+
+// wait starts a new timeout, cancelling the previous one. Retries of the first phase are delayed more and more
+// and differently by processes, so that competing proposers do not preempt each other forever.
+func (ctx *MultiPaxos) wait(dp *process.Process) {
+	ctx.epoch++
+	delay := timeout(dp, ctx.Timeout)*ctx.attempts + int64(dp.Node)
+	dp.Network.SendTimeout(dp.Node, delay, messages.NewMessageByType("MULTIPAXOS_TIMEOUT", ctx.epoch))
+}
+
+// firstUndecided returns the least slot the process knows no decision of.
+func (ctx *MultiPaxos) firstUndecided() int32 {
+	var res int32
+	for ctx.decided(res) {
+		res++
+	}
+	return res
+}
+
+// prepare starts the first phase for all the slots from the first undecided one with a new ballot.
+func (ctx *MultiPaxos) prepare(dp *process.Process) {
+	n := int32(len(dp.Network.Nodes()))
+	ctx.leading, ctx.leader = true, false
+	ctx.round++
+	ctx.ballot = ctx.round*n + dp.Node
+	ctx.first = ctx.firstUndecided()
+	ctx.promises = make(map[int32]bool)
+	ctx.found = make(map[int32]acceptance)
+	ctx.attempts++
+	broadcast(dp, "MULTIPAXOS_PREPARE", ctx.ballot, ctx.first)
+	ctx.wait(dp)
+}
+
+// lead starts the second phase once the process becomes the leader: the values accepted before
+// are proposed again, the slots left empty are filled with Noop and pending values follow them.
+func (ctx *MultiPaxos) lead(dp *process.Process) {
+	ctx.leader = true
+	ctx.attempts = 1
+	ctx.inflight = make(map[int32]int32)
+	ctx.votes = make(map[int32]map[int32]bool)
+	ctx.next = ctx.first
+	for _, slots := range [][]int32{sorted(ctx.found), sorted(ctx.Log)} {
+		if n := len(slots); n > 0 && slots[n-1] >= ctx.next {
+			ctx.next = slots[n-1] + 1
+		}
+	}
+	for slot := ctx.first; slot < ctx.next; slot++ {
+		if ctx.decided(slot) {
+			continue
+		}
+		value := int32(Noop)
+		if a, ok := ctx.found[slot]; ok {
+			value = a.value
+			ctx.pending = remove(ctx.pending, value)
+		}
+		ctx.accept(dp, slot, value)
+	}
+	ctx.propose(dp)
+}
+
+// accept proposes the value in the slot by the second phase.
+func (ctx *MultiPaxos) accept(dp *process.Process, slot int32, value int32) {
+	ctx.inflight[slot] = value
+	ctx.votes[slot] = make(map[int32]bool)
+	broadcast(dp, "MULTIPAXOS_ACCEPT", ctx.ballot, slot, value)
+}
+
+// propose puts the pending values into the next slots, skipping the ones already decided or proposed.
+func (ctx *MultiPaxos) propose(dp *process.Process) {
+	for _, v := range ctx.pending {
+		if ctx.contains(v) || ctx.proposed(v) {
+			continue
+		}
+		ctx.accept(dp, ctx.next, v)
+		ctx.next++
+	}
+	ctx.pending = ctx.pending[:0]
+	ctx.wait(dp)
+}
+
+// proposed reports whether the value is being proposed by the leader.
+func (ctx *MultiPaxos) proposed(value int32) bool {
+	for _, v := range ctx.inflight {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// resign stops leading after a higher ballot is found, the values not decided yet are pending again.
+func (ctx *MultiPaxos) resign(dp *process.Process, promised int32) {
+	if r := promised / int32(len(dp.Network.Nodes())); r > ctx.round {
+		ctx.round = r
+	}
+	for _, slot := range sorted(ctx.inflight) {
+		if v := ctx.inflight[slot]; v != Noop {
+			ctx.pending = append(ctx.pending, v)
+		}
+	}
+	ctx.leading, ctx.leader = false, false
+	ctx.inflight = nil
+	ctx.wait(dp)
+}
+
+// retry sends the proposals and the decisions not acknowledged yet again.
+// It reports whether there are any of them.
+func (ctx *MultiPaxos) retry(dp *process.Process) bool {
+	res := false
+	for _, slot := range sorted(ctx.inflight) {
+		res = true
+		for _, u := range dp.Network.Nodes() {
+			if !ctx.votes[slot][u] {
+				dp.Network.SendMessage(dp.Node, u, messages.NewMessageByType("MULTIPAXOS_ACCEPT", ctx.ballot, slot, ctx.inflight[slot]))
+			}
+		}
+	}
+	for _, slot := range sorted(ctx.learned) {
+		res = true
+		for _, u := range dp.Network.Nodes() {
+			if !ctx.learned[slot][u] {
+				dp.Network.SendMessage(dp.Node, u, messages.NewMessageByType("MULTIPAXOS_DECIDE", slot, ctx.Log[slot]))
+			}
+		}
+	}
+	return res
+}
+
+// MultiPaxosWorkFunction handles MULTIPAXOS_PROPOSE, MULTIPAXOS_PREPARE, MULTIPAXOS_PROMISE, MULTIPAXOS_ACCEPT,
+// MULTIPAXOS_ACCEPTED, MULTIPAXOS_NACK, MULTIPAXOS_DECIDE, MULTIPAXOS_LEARNED and MULTIPAXOS_TIMEOUT messages.
+// A process with proposed values becomes the leader by the first phase of Paxos for all the slots at once
+// and then decides the values in consecutive slots by the second phase only, until a higher ballot deposes it.
+// Decisions are sent to all the processes until they acknowledge them.
+func MultiPaxosWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("MULTIPAXOS"), s) {
+		return false
+	}
+	ctx := process.Ctx[*MultiPaxos](dp, "MultiPaxos")
+	switch string(s) {
+	case "MULTIPAXOS_PROPOSE":
+		v := m.GetInt32()
+		ctx.Proposed = append(ctx.Proposed, v)
+		if ctx.contains(v) {
+			break
+		}
+		ctx.pending = append(ctx.pending, v)
+		switch {
+		case ctx.leader:
+			ctx.propose(dp)
+		case !ctx.leading:
+			ctx.prepare(dp)
+		}
+	case "MULTIPAXOS_PREPARE":
+		b, first := m.GetInt32(), m.GetInt32()
+		if b < ctx.promised {
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("MULTIPAXOS_NACK", b, ctx.promised))
+			break
+		}
+		ctx.promised = b
+		args := []int32{b}
+		for _, slot := range sorted(ctx.accepted) {
+			if a := ctx.accepted[slot]; slot >= first && !ctx.decided(slot) {
+				args = append(args, slot, a.ballot, a.value)
+			}
+		}
+		for _, slot := range sorted(ctx.Log) {
+			if slot >= first {
+				args = append(args, slot, math.MaxInt32, ctx.Log[slot])
+			}
+		}
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("MULTIPAXOS_PROMISE", args...))
+	case "MULTIPAXOS_PROMISE":
+		b := m.GetInt32()
+		if !ctx.leading || ctx.leader || b != ctx.ballot {
+			break
+		}
+		ctx.promises[m.From] = true
+		for m.Ptr < len(m.Body) {
+			slot, ballot, value := m.GetInt32(), m.GetInt32(), m.GetInt32()
+			if a, ok := ctx.found[slot]; !ok || ballot > a.ballot {
+				ctx.found[slot] = acceptance{ballot, value}
+			}
+		}
+		if len(ctx.promises) >= majority(dp) {
+			ctx.lead(dp)
+		}
+	case "MULTIPAXOS_ACCEPT":
+		b, slot, value := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		switch {
+		case ctx.decided(slot):
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("MULTIPAXOS_DECIDE", slot, ctx.Log[slot]))
+		case b >= ctx.promised:
+			ctx.promised = b
+			ctx.accepted[slot] = acceptance{b, value}
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("MULTIPAXOS_ACCEPTED", b, slot))
+		default:
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("MULTIPAXOS_NACK", b, ctx.promised))
+		}
+	case "MULTIPAXOS_ACCEPTED":
+		b, slot := m.GetInt32(), m.GetInt32()
+		value, ok := ctx.inflight[slot]
+		if !ctx.leader || b != ctx.ballot || !ok {
+			break
+		}
+		ctx.votes[slot][m.From] = true
+		if len(ctx.votes[slot]) >= majority(dp) {
+			delete(ctx.inflight, slot)
+			delete(ctx.votes, slot)
+			ctx.decide(dp, "MULTIPAXOS", slot, value)
+			ctx.learned[slot] = make(map[int32]bool)
+			broadcast(dp, "MULTIPAXOS_DECIDE", slot, value)
+		}
+	case "MULTIPAXOS_NACK":
+		b, promised := m.GetInt32(), m.GetInt32()
+		if ctx.leading && b == ctx.ballot {
+			ctx.resign(dp, promised)
+		}
+	case "MULTIPAXOS_DECIDE":
+		slot, value := m.GetInt32(), m.GetInt32()
+		ctx.decide(dp, "MULTIPAXOS", slot, value)
+		ctx.pending = remove(ctx.pending, value)
+		if v, ok := ctx.inflight[slot]; ok {
+			delete(ctx.inflight, slot)
+			delete(ctx.votes, slot)
+			if v != value && v != Noop {
+				ctx.pending = append(ctx.pending, v)
+			}
+		}
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("MULTIPAXOS_LEARNED", slot))
+	case "MULTIPAXOS_LEARNED":
+		slot := m.GetInt32()
+		if learned, ok := ctx.learned[slot]; ok {
+			learned[m.From] = true
+			if len(learned) == len(dp.Network.Nodes()) {
+				delete(ctx.learned, slot)
+			}
+		}
+	case "MULTIPAXOS_TIMEOUT":
+		if m.GetInt32() != ctx.epoch {
+			break
+		}
+		outstanding := ctx.retry(dp)
+		switch {
+		case ctx.leader && len(ctx.pending) > 0:
+			ctx.propose(dp)
+		case !ctx.leader && (ctx.leading || len(ctx.pending) > 0):
+			ctx.prepare(dp)
+		case outstanding:
+			ctx.wait(dp)
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package consensus
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Paxos is a context of the single-decree Paxos algorithm. Every process is a proposer, an acceptor and a learner.
+// Timeout is the time of waiting for answers in ticks, twice the greatest link latency by default.
+type Paxos struct {
+	Replica
+	Timeout   int64
+	proposing bool
+	phase     int
+	value     int32
+	round     int32
+	ballot    int32
+	highest   int32
+	votes     map[int32]bool
+	learned   map[int32]bool
+	attempts  int64
+	epoch     int32
+	promised  int32
+	accepted  int32
+	accValue  int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "PAXOS",
+		Description: "single-decree Paxos on a complete graph, PAXOS_PROPOSE proposes its argument",
+		Work:        PaxosWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Paxos": func(int32) context.Context {
+				return &Paxos{Replica: newReplica(), promised: -1, accepted: -1}
+			},
+		},
+	})
+}
+
+// wait starts a new timeout, cancelling the previous one. Retries are delayed more and more
+// and differently by processes, so that competing proposers do not preempt each other forever.
+func (ctx *Paxos) wait(dp *process.Process) {
+	ctx.epoch++
+	delay := timeout(dp, ctx.Timeout)*ctx.attempts + int64(dp.Node)
+	dp.Network.SendTimeout(dp.Node, delay, messages.NewMessageByType("PAXOS_TIMEOUT", ctx.epoch))
+}
+
+// prepare starts the first phase with a new ballot, unique among the processes.
+func (ctx *Paxos) prepare(dp *process.Process) {
+	n := int32(len(dp.Network.Nodes()))
+	ctx.proposing, ctx.phase = true, 1
+	ctx.round++
+	ctx.ballot = ctx.round*n + dp.Node
+	ctx.highest = -1
+	ctx.votes = make(map[int32]bool)
+	ctx.attempts++
+	broadcast(dp, "PAXOS_PREPARE", ctx.ballot)
+	ctx.wait(dp)
+}
+
+// announce sends the decision to the processes which have not learned it yet.
+func (ctx *Paxos) announce(dp *process.Process) {
+	if len(ctx.learned) == len(dp.Network.Nodes()) {
+		return
+	}
+	for _, v := range dp.Network.Nodes() {
+		if !ctx.learned[v] {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("PAXOS_DECIDE", ctx.Log[0]))
+		}
+	}
+	ctx.wait(dp)
+}
+
+// PaxosWorkFunction handles PAXOS_PROPOSE, PAXOS_PREPARE, PAXOS_PROMISE, PAXOS_ACCEPT, PAXOS_ACCEPTED,
+// PAXOS_NACK, PAXOS_DECIDE, PAXOS_LEARNED and PAXOS_TIMEOUT messages.
+// A proposer gets promises of a majority for its ballot along with the values accepted before, proposes
+// the value of the highest ballot among them, if any, or its own one, and decides it when a majority accepts it.
+// The decision is sent to all the processes until they acknowledge it.
+func PaxosWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("PAXOS"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Paxos](dp, "Paxos")
+	switch string(s) {
+	case "PAXOS_PROPOSE":
+		v := m.GetInt32()
+		ctx.Proposed = append(ctx.Proposed, v)
+		if !ctx.decided(0) && !ctx.proposing {
+			ctx.value = v
+			ctx.prepare(dp)
+		}
+	case "PAXOS_PREPARE":
+		b := m.GetInt32()
+		switch {
+		case ctx.decided(0):
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_DECIDE", ctx.Log[0]))
+		case b >= ctx.promised:
+			ctx.promised = b
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_PROMISE", b, ctx.accepted, ctx.accValue))
+		default:
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_NACK", b, ctx.promised))
+		}
+	case "PAXOS_PROMISE":
+		b, accepted, value := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		if !ctx.proposing || ctx.phase != 1 || b != ctx.ballot {
+			break
+		}
+		ctx.votes[m.From] = true
+		if accepted > ctx.highest {
+			ctx.highest, ctx.value = accepted, value
+		}
+		if len(ctx.votes) >= majority(dp) {
+			ctx.phase = 2
+			ctx.votes = make(map[int32]bool)
+			broadcast(dp, "PAXOS_ACCEPT", ctx.ballot, ctx.value)
+			ctx.wait(dp)
+		}
+	case "PAXOS_ACCEPT":
+		b, value := m.GetInt32(), m.GetInt32()
+		switch {
+		case ctx.decided(0):
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_DECIDE", ctx.Log[0]))
+		case b >= ctx.promised:
+			ctx.promised, ctx.accepted, ctx.accValue = b, b, value
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_ACCEPTED", b))
+		default:
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_NACK", b, ctx.promised))
+		}
+	case "PAXOS_ACCEPTED":
+		b := m.GetInt32()
+		if !ctx.proposing || ctx.phase != 2 || b != ctx.ballot {
+			break
+		}
+		ctx.votes[m.From] = true
+		if len(ctx.votes) >= majority(dp) {
+			ctx.proposing = false
+			ctx.decide(dp, "PAXOS", 0, ctx.value)
+			ctx.learned = make(map[int32]bool)
+			ctx.attempts = 1
+			ctx.announce(dp)
+		}
+	case "PAXOS_NACK":
+		b, promised := m.GetInt32(), m.GetInt32()
+		if !ctx.proposing || ctx.phase == 0 || b != ctx.ballot {
+			break
+		}
+		if r := promised / int32(len(dp.Network.Nodes())); r > ctx.round {
+			ctx.round = r
+		}
+		ctx.phase = 0
+		ctx.wait(dp)
+	case "PAXOS_DECIDE":
+		ctx.proposing = false
+		ctx.decide(dp, "PAXOS", 0, m.GetInt32())
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("PAXOS_LEARNED"))
+	case "PAXOS_LEARNED":
+		if ctx.learned != nil {
+			ctx.learned[m.From] = true
+		}
+	case "PAXOS_TIMEOUT":
+		if m.GetInt32() != ctx.epoch {
+			break
+		}
+		if ctx.learned != nil {
+			ctx.announce(dp)
+		} else if ctx.proposing {
+			ctx.prepare(dp)
+		}
+	}
+	return true
+}
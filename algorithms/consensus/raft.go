@@ -0,0 +1,353 @@
+package consensus
+
+import (
+	"math/rand"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Roles of Raft processes.
+const (
+	Follower  = "follower"
+	Candidate = "candidate"
+	Leader    = "leader"
+)
+
+// entry is an entry of the Raft log.
+type entry struct {
+	term, value int32
+}
+
+// Raft is a context of the Raft algorithm.
+// Timeout is the least election timeout in ticks, four times the greatest link latency by default,
+// the leader sends heartbeats three times as often.
+type Raft struct {
+	Replica
+	Timeout  int64
+	Term     int32
+	Role     string
+	Leader   int32
+	rng      *rand.Rand
+	votedFor int32
+	votes    map[int32]bool
+	log      []entry
+	commit   int32
+	next     map[int32]int32
+	match    map[int32]int32
+	pending  []int32
+	epoch    int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "RAFT",
+		Description: "Raft leader election and log replication on a complete graph, RAFT_INIT starts it, RAFT_PROPOSE proposes its argument",
+		Work:        RaftWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Raft": func(int32) context.Context {
+				return &Raft{Replica: newReplica(), Role: Follower, Leader: -1, votedFor: -1, pending: make([]int32, 0)}
+			},
+		},
+	})
+}
+
+// timeout returns the least election timeout.
+func (ctx *Raft) timeout(dp *process.Process) int64 {
+	if ctx.Timeout > 0 {
+		return ctx.Timeout
+	}
+	return 2 * timeout(dp, 0)
+}
+
+// wait starts a new election timeout, cancelling the previous one. Timeouts are random between
+// the least timeout and twice as much, so that split votes do not repeat forever.
+func (ctx *Raft) wait(dp *process.Process) {
+	ctx.epoch++
+	t := ctx.timeout(dp)
+	dp.Network.SendTimeout(dp.Node, t+ctx.rng.Int63n(t), messages.NewMessageByType("RAFT_TIMEOUT", ctx.epoch))
+}
+
+// last returns the index and the term of the last entry of the log, indices start from 1.
+func (ctx *Raft) last() (int32, int32) {
+	return ctx.at(int32(len(ctx.log)))
+}
+
+// at returns the index and the term of the entry, the term is 0 for the index 0.
+func (ctx *Raft) at(index int32) (int32, int32) {
+	if index == 0 {
+		return 0, 0
+	}
+	return index, ctx.log[index-1].term
+}
+
+// logged reports whether the value is in the log.
+func (ctx *Raft) logged(value int32) bool {
+	for _, e := range ctx.log {
+		if e.value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// follow steps down to a follower of the term.
+func (ctx *Raft) follow(dp *process.Process, term int32) {
+	if term > ctx.Term {
+		ctx.Term, ctx.votedFor, ctx.Leader = term, -1, -1
+	}
+	ctx.Role = Follower
+	ctx.wait(dp)
+}
+
+// elect starts an election in the next term.
+func (ctx *Raft) elect(dp *process.Process) {
+	ctx.Term++
+	ctx.Role, ctx.Leader = Candidate, -1
+	ctx.votedFor = dp.Node
+	ctx.votes = map[int32]bool{dp.Node: true}
+	index, term := ctx.last()
+	for _, v := range dp.Network.Nodes() {
+		if v != dp.Node {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("RAFT_VOTE", ctx.Term, index, term))
+		}
+	}
+	ctx.wait(dp)
+	ctx.check(dp)
+}
+
+// check makes the candidate the leader when a majority has voted for it.
+func (ctx *Raft) check(dp *process.Process) {
+	if ctx.Role != Candidate || len(ctx.votes) < majority(dp) {
+		return
+	}
+	ctx.Role, ctx.Leader = Leader, dp.Node
+	logging.Infof("[%v]: leader of term %v", dp.Node, ctx.Term)
+	ctx.next = make(map[int32]int32)
+	ctx.match = make(map[int32]int32)
+	for _, v := range dp.Network.Nodes() {
+		ctx.next[v] = int32(len(ctx.log)) + 1
+	}
+	ctx.append(Noop)
+	for _, v := range ctx.pending {
+		ctx.append(v)
+	}
+	ctx.heartbeat(dp)
+}
+
+// append adds the value to the log of the leader, unless it is there already.
+// Every leader appends Noop first, so that the entries of the previous terms are committed along with it.
+func (ctx *Raft) append(value int32) {
+	if value == Noop || !ctx.logged(value) {
+		ctx.log = append(ctx.log, entry{ctx.Term, value})
+		ctx.match[ctx.Leader] = int32(len(ctx.log))
+	}
+}
+
+// heartbeat sends the entries the followers lack and schedules the next heartbeat.
+func (ctx *Raft) heartbeat(dp *process.Process) {
+	ctx.advance(dp)
+	for _, v := range dp.Network.Nodes() {
+		if v != dp.Node {
+			ctx.replicate(dp, v)
+		}
+	}
+	ctx.epoch++
+	delay := ctx.timeout(dp) / 3
+	if delay < 1 {
+		delay = 1
+	}
+	dp.Network.SendTimeout(dp.Node, delay, messages.NewMessageByType("RAFT_HEARTBEAT", ctx.epoch))
+}
+
+// replicate sends the entries from the next index of the follower along with the preceding entry.
+func (ctx *Raft) replicate(dp *process.Process, v int32) {
+	prev, term := ctx.at(ctx.next[v] - 1)
+	args := []int32{ctx.Term, prev, term, ctx.commit}
+	for _, e := range ctx.log[prev:] {
+		args = append(args, e.term, e.value)
+	}
+	dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("RAFT_APPEND", args...))
+}
+
+// advance commits the entries of the current term stored by a majority along with the preceding ones.
+func (ctx *Raft) advance(dp *process.Process) {
+	for index := int32(len(ctx.log)); index > ctx.commit; index-- {
+		if ctx.log[index-1].term != ctx.Term {
+			break
+		}
+		count := 0
+		for _, v := range dp.Network.Nodes() {
+			if ctx.match[v] >= index {
+				count++
+			}
+		}
+		if count >= majority(dp) {
+			ctx.apply(dp, index)
+			return
+		}
+	}
+}
+
+// apply decides the committed entries up to the index.
+func (ctx *Raft) apply(dp *process.Process, index int32) {
+	for ; ctx.commit < index; ctx.commit++ {
+		value := ctx.log[ctx.commit].value
+		ctx.decide(dp, "RAFT", ctx.commit, value)
+		ctx.pending = remove(ctx.pending, value)
+	}
+}
+
+// pend keeps the proposed value until it is decided.
+func (ctx *Raft) pend(value int32) {
+	for _, v := range ctx.pending {
+		if v == value {
+			return
+		}
+	}
+	if !ctx.contains(value) {
+		ctx.pending = append(ctx.pending, value)
+	}
+}
+
+// forward sends the pending values to the leader, if any, until they are decided.
+func (ctx *Raft) forward(dp *process.Process) {
+	if ctx.Role == Leader || ctx.Leader < 0 {
+		return
+	}
+	for _, v := range ctx.pending {
+		dp.Network.SendMessage(dp.Node, ctx.Leader, messages.NewMessageByType("RAFT_FORWARD", v))
+	}
+}
+
+// RaftWorkFunction handles RAFT_INIT, RAFT_PROPOSE, RAFT_FORWARD, RAFT_TIMEOUT, RAFT_HEARTBEAT, RAFT_VOTE,
+// RAFT_VOTED, RAFT_APPEND and RAFT_APPENDED messages.
+// A follower which has not heard of a leader within the election timeout becomes a candidate of the next term
+// and the leader once a majority votes for it. Processes vote once per term for candidates with logs
+// at least as up to date as their own. The leader replicates its log to the followers by heartbeats
+// and commits the entries of its term stored by a majority. Followers forward proposed values to the leader.
+func RaftWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("RAFT"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Raft](dp, "Raft")
+	if ctx.rng == nil {
+		ctx.rng = rand.New(rand.NewSource(dp.Network.Rng.Int63()))
+		ctx.wait(dp)
+	}
+	switch string(s) {
+	case "RAFT_PROPOSE":
+		v := m.GetInt32()
+		ctx.Proposed = append(ctx.Proposed, v)
+		ctx.pend(v)
+		if ctx.Role == Leader {
+			ctx.append(v)
+		}
+		ctx.forward(dp)
+	case "RAFT_FORWARD":
+		if ctx.Role == Leader {
+			ctx.append(m.GetInt32())
+		}
+	case "RAFT_TIMEOUT":
+		if m.GetInt32() == ctx.epoch && ctx.Role != Leader {
+			ctx.elect(dp)
+		}
+	case "RAFT_HEARTBEAT":
+		if m.GetInt32() == ctx.epoch && ctx.Role == Leader {
+			ctx.heartbeat(dp)
+		}
+	case "RAFT_VOTE":
+		term, index, last := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		if term > ctx.Term {
+			ctx.follow(dp, term)
+		}
+		myIndex, myLast := ctx.last()
+		upToDate := last > myLast || last == myLast && index >= myIndex
+		granted := term == ctx.Term && (ctx.votedFor < 0 || ctx.votedFor == m.From) && upToDate
+		if granted {
+			ctx.votedFor = m.From
+			ctx.wait(dp)
+		}
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RAFT_VOTED", ctx.Term, flag(granted)))
+	case "RAFT_VOTED":
+		term, granted := m.GetInt32(), m.GetInt32() == 1
+		if term > ctx.Term {
+			ctx.follow(dp, term)
+		} else if term == ctx.Term && granted && ctx.Role == Candidate {
+			ctx.votes[m.From] = true
+			ctx.check(dp)
+		}
+	case "RAFT_APPEND":
+		term, prev, prevTerm, commit := m.GetInt32(), m.GetInt32(), m.GetInt32(), m.GetInt32()
+		if term < ctx.Term {
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RAFT_APPENDED", ctx.Term, 0, 0))
+			break
+		}
+		ctx.follow(dp, term)
+		ctx.Leader = m.From
+		if prev > int32(len(ctx.log)) || prev > 0 && ctx.log[prev-1].term != prevTerm {
+			hint := prev - 1
+			if hint > int32(len(ctx.log)) {
+				hint = int32(len(ctx.log))
+			}
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RAFT_APPENDED", ctx.Term, 0, hint))
+			break
+		}
+		index := prev
+		for m.Ptr < len(m.Body) {
+			e := entry{m.GetInt32(), m.GetInt32()}
+			index++
+			if index <= int32(len(ctx.log)) && ctx.log[index-1].term != e.term {
+				ctx.log = ctx.log[:index-1]
+			}
+			if index > int32(len(ctx.log)) {
+				ctx.log = append(ctx.log, e)
+			}
+		}
+		if commit > index {
+			commit = index
+		}
+		if commit > ctx.commit {
+			ctx.apply(dp, commit)
+		}
+		ctx.forward(dp)
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RAFT_APPENDED", ctx.Term, 1, index))
+	case "RAFT_APPENDED":
+		term, success, index := m.GetInt32(), m.GetInt32() == 1, m.GetInt32()
+		if term > ctx.Term {
+			ctx.follow(dp, term)
+			break
+		}
+		if ctx.Role != Leader || term != ctx.Term {
+			break
+		}
+		if success {
+			if index > ctx.match[m.From] {
+				ctx.match[m.From] = index
+			}
+			ctx.next[m.From] = ctx.match[m.From] + 1
+			ctx.advance(dp)
+			break
+		}
+		if next := index + 1; next < ctx.next[m.From] {
+			ctx.next[m.From] = next
+		} else if ctx.next[m.From] > 1 {
+			ctx.next[m.From]--
+		}
+		ctx.replicate(dp, m.From)
+	}
+	return true
+}
+
+// flag encodes the condition as a message argument.
+func flag(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
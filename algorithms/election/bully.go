@@ -0,0 +1,130 @@
+package election
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Bully is a context of the Bully algorithm.
+// Timeout is the time of waiting for answers in ticks, twice the greatest link latency by default.
+type Bully struct {
+	State
+	Timeout  int64
+	electing bool
+	answered bool
+	epoch    int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "BULLY",
+		Description: "Bully election on a complete graph, any processes initiate it by BULLY_INIT",
+		Work:        BullyWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Bully": func(node int32) context.Context { return &Bully{State: newState(node)} },
+		},
+	})
+}
+
+// higher returns the neighbours with greater node numbers.
+func higher(dp *process.Process) []int32 {
+	res := make([]int32, 0)
+	for _, v := range dp.Neighbours() {
+		if v > dp.Node {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// timeout returns the time of waiting for answers.
+func (ctx *Bully) timeout(dp *process.Process) int64 {
+	if ctx.Timeout > 0 {
+		return ctx.Timeout
+	}
+	var res int64 = 1
+	for _, v := range dp.Neighbours() {
+		if l := 2 * int64(dp.Network.GetLink(dp.Node, v)); l > res {
+			res = l
+		}
+	}
+	return res
+}
+
+// wait starts a new timeout, cancelling the previous one.
+func (ctx *Bully) wait(dp *process.Process) {
+	ctx.epoch++
+	dp.Network.SendTimeout(dp.Node, ctx.timeout(dp), messages.NewMessageByType("BULLY_TIMEOUT", ctx.epoch))
+}
+
+// election starts an election: the process challenges all the greater ones
+// or becomes the coordinator if there are none.
+func (ctx *Bully) election(dp *process.Process) {
+	ctx.electing, ctx.answered = true, false
+	hs := higher(dp)
+	if len(hs) == 0 {
+		ctx.coordinate(dp)
+		return
+	}
+	for _, v := range hs {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("BULLY_ELECTION"))
+	}
+	ctx.wait(dp)
+}
+
+// coordinate announces the process as the coordinator.
+func (ctx *Bully) coordinate(dp *process.Process) {
+	ctx.electing = false
+	ctx.epoch++
+	ctx.elect(dp, dp.Node)
+	for _, v := range dp.Neighbours() {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("BULLY_COORDINATOR", dp.Node))
+	}
+}
+
+// BullyWorkFunction handles BULLY_INIT, BULLY_ELECTION, BULLY_OK, BULLY_COORDINATOR and BULLY_TIMEOUT messages.
+// A process challenges the processes with greater node numbers. Each of them answers and starts its own election.
+// The process which gets no answers in time becomes the coordinator. If the coordinator is not announced in time
+// after an answer, the election is restarted.
+func BullyWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("BULLY"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Bully](dp, "Bully")
+	ctx.UID = dp.Node
+	switch string(s) {
+	case "BULLY_INIT":
+		if !ctx.electing {
+			ctx.election(dp)
+		}
+	case "BULLY_ELECTION":
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("BULLY_OK"))
+		if !ctx.electing {
+			ctx.election(dp)
+		}
+	case "BULLY_OK":
+		if ctx.electing && !ctx.answered {
+			ctx.answered = true
+			ctx.wait(dp)
+		}
+	case "BULLY_COORDINATOR":
+		leader := m.GetInt32()
+		ctx.electing = false
+		ctx.epoch++
+		ctx.elect(dp, leader)
+	case "BULLY_TIMEOUT":
+		if m.GetInt32() != ctx.epoch || !ctx.electing {
+			break
+		}
+		if ctx.answered {
+			ctx.election(dp)
+		} else {
+			ctx.coordinate(dp)
+		}
+	}
+	return true
+}
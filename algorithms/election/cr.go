@@ -0,0 +1,67 @@
+package election
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// CR is a context of the Chang–Roberts algorithm.
+type CR struct {
+	State
+	participant bool
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "CR",
+		Description: "Chang–Roberts election on a unidirectional ring, any processes initiate it by CR_INIT",
+		Work:        CRWorkFunction,
+		Contexts: map[string]context.Factory{
+			"CR": func(node int32) context.Context { return &CR{State: newState(node)} },
+		},
+	})
+}
+
+// CRWorkFunction handles CR_INIT, CR_ELECTION and CR_ELECTED messages.
+// Unlike LCR, only initiators send their UIDs at once. A process which is not a participant yet
+// replaces a smaller UID by its own, participants discard smaller UIDs.
+func CRWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("CR"), s) {
+		return false
+	}
+	ctx := process.Ctx[*CR](dp, "CR")
+	next := successor(dp)
+	switch string(s) {
+	case "CR_INIT":
+		if !ctx.participant {
+			ctx.participant = true
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("CR_ELECTION", ctx.UID))
+		}
+	case "CR_ELECTION":
+		uid := m.GetInt32()
+		switch {
+		case uid > ctx.UID:
+			ctx.participant = true
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("CR_ELECTION", uid))
+		case uid < ctx.UID && !ctx.participant:
+			ctx.participant = true
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("CR_ELECTION", ctx.UID))
+		case uid == ctx.UID:
+			ctx.participant = false
+			ctx.elect(dp, uid)
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("CR_ELECTED", uid))
+		}
+	case "CR_ELECTED":
+		uid := m.GetInt32()
+		if uid != ctx.UID {
+			ctx.participant = false
+			ctx.elect(dp, uid)
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("CR_ELECTED", uid))
+		}
+	}
+	return true
+}
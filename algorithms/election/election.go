@@ -0,0 +1,114 @@
+// Package election implements leader election algorithms:
+// LCR and Chang–Roberts on unidirectional rings, Hirschberg–Sinclair on bidirectional rings,
+// Bully on complete graphs and FloodMax on arbitrary connected graphs.
+//
+// Every algorithm elects the process with the greatest UID.
+// UIDs are node numbers by default and can be changed by the context directive, e.g.
+// "context 3 LCR UID=17", except for Bully, which compares node numbers.
+package election
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+// Unknown marks the leader which is not elected yet.
+const Unknown = -1
+
+// State is the outcome of the election, it is embedded into contexts of all the algorithms.
+type State struct {
+	UID    int32
+	Leader int32
+}
+
+func newState(node int32) State {
+	return State{UID: node, Leader: Unknown}
+}
+
+// Result returns the outcome of the election.
+func (s *State) Result() *State {
+	return s
+}
+
+// IsLeader reports whether the process has been elected.
+func (s *State) IsLeader() bool {
+	return s.Leader != Unknown && s.Leader == s.UID
+}
+
+// elect records the leader known to the process.
+func (s *State) elect(dp *process.Process, leader int32) {
+	s.Leader = leader
+	logging.Infof("[%v]: leader is %v", dp.Node, leader)
+}
+
+// Outcome is implemented by contexts of all the election algorithms.
+type Outcome interface {
+	Result() *State
+}
+
+// Verify checks that the election with the context key has finished on all the processes:
+// all of them know the same leader, which is the only elected process and has the greatest UID.
+// It returns the leader UID.
+func Verify(ps []*process.Process, key string) (int32, error) {
+	var leader int32 = Unknown
+	var max int32 = Unknown
+	elected := make([]int32, 0)
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return Unknown, fmt.Errorf("process %v has no election context %v", dp.Node, key)
+		}
+		s := o.Result()
+		if s.Leader == Unknown {
+			return Unknown, fmt.Errorf("process %v knows no leader", dp.Node)
+		}
+		if leader != Unknown && s.Leader != leader {
+			return Unknown, fmt.Errorf("process %v knows leader %v, others know %v", dp.Node, s.Leader, leader)
+		}
+		leader = s.Leader
+		if s.UID > max {
+			max = s.UID
+		}
+		if s.IsLeader() {
+			elected = append(elected, dp.Node)
+		}
+	}
+	if len(elected) != 1 {
+		return Unknown, fmt.Errorf("processes %v are elected", elected)
+	}
+	if leader != max {
+		return Unknown, fmt.Errorf("leader %v has not the greatest UID %v", leader, max)
+	}
+	return leader, nil
+}
+
+// successor returns the next process on a ring: the least neighbour greater than the process
+// or the least neighbour at all, if there are no greater ones.
+// So both rings linked from i to i+1 and bidirected rings are walked in the same direction.
+func successor(dp *process.Process) int32 {
+	ns := dp.Neighbours()
+	if len(ns) == 0 {
+		return dp.Node
+	}
+	i := sort.Search(len(ns), func(i int) bool { return ns[i] > dp.Node })
+	if i == len(ns) {
+		return ns[0]
+	}
+	return ns[i]
+}
+
+// opposite returns the neighbour on a bidirected ring other than the given one.
+func opposite(dp *process.Process, from int32) int32 {
+	for _, v := range dp.Neighbours() {
+		if v != from {
+			return v
+		}
+	}
+	return from
+}
@@ -0,0 +1,191 @@
+package election
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+		want   int32
+	}{
+		{"LCR", "../../configs/election/lcr.data", "LCR", 42},
+		{"CR", "../../configs/election/cr.data", "CR", 42},
+		{"HS", "../../configs/election/hs.data", "HS", 42},
+		{"Bully", "../../configs/election/bully.data", "Bully", 5},
+		{"FloodMax", "../../configs/election/floodmax.data", "FloodMax", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sample.Run(t, tt.config, 1000, false, nil)
+			got, err := Verify(w.ProcessesList, tt.key)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ring creates a ring of n processes with random UIDs and latencies.
+func ring(t *testing.T, n int32, function string, key string, rng *rand.Rand) *world.World {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 10000})
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+	}
+	uids := rng.Perm(int(n) * 3)
+	for i := int32(0); i < n; i++ {
+		w.Network.CreateLink(i, (i+1)%n, function == "HS", 1+rng.Int31n(5))
+		w.AssignWorkFunction(i, []byte(function))
+		if err := w.SetContext(i, key, map[string]string{"UID": fmt.Sprint(uids[i])}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w
+}
+
+func TestRings(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+	}{
+		{"LCR", "LCR"},
+		{"CR", "CR"},
+		{"HS", "HS"},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 3, 7, 16} {
+			t.Run(fmt.Sprintf("%v%v", tt.function, n), func(t *testing.T) {
+				w := ring(t, n, tt.function, tt.key, rng)
+				defer w.Stop()
+				initiators := []int32{-1}
+				if tt.function == "CR" {
+					initiators = []int32{rng.Int31n(n), rng.Int31n(n)}
+				}
+				for _, i := range initiators {
+					w.Network.SendMessage(-1, i, messages.NewMessageByType(tt.function+"_INIT"))
+				}
+				if !w.Run() {
+					t.Fatalf("World.Run() = false")
+				}
+				if _, err := Verify(w.ProcessesList, tt.key); err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestBully(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int32{1, 2, 5, 10} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{Seed: 1, MaxTicks: 10000})
+			defer w.Stop()
+			for i := int32(0); i < n; i++ {
+				w.CreateProcess(i)
+			}
+			for i := int32(0); i < n; i++ {
+				for j := i + 1; j < n; j++ {
+					w.Network.CreateLink(i, j, true, 1+rng.Int31n(5))
+				}
+				w.AssignWorkFunction(i, []byte("BULLY"))
+			}
+			w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType("BULLY_INIT"))
+			if !w.Run() {
+				t.Fatalf("World.Run() = false")
+			}
+			got, err := Verify(w.ProcessesList, "Bully")
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != n-1 {
+				t.Errorf("Verify() = %v, want %v", got, n-1)
+			}
+		})
+	}
+}
+
+func TestFloodMax(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int32{1, 2, 6, 12} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{Seed: 1, MaxTicks: 10000})
+			defer w.Stop()
+			for i := int32(0); i < n; i++ {
+				w.CreateProcess(i)
+			}
+			uids := rng.Perm(int(n) * 3)
+			for i := int32(0); i < n; i++ {
+				if i > 0 {
+					w.Network.CreateLink(i, rng.Int31n(i), true, 1+rng.Int31n(5))
+				}
+				w.AssignWorkFunction(i, []byte("FLOODMAX"))
+				if err := w.SetContext(i, "FloodMax", map[string]string{"UID": fmt.Sprint(uids[i])}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType("FLOODMAX_INIT"))
+			if !w.Run() {
+				t.Fatalf("World.Run() = false")
+			}
+			if _, err := Verify(w.ProcessesList, "FloodMax"); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		states  []State
+		want    int32
+		wantErr bool
+	}{
+		{"Valid", []State{{1, 3}, {3, 3}, {2, 3}}, 3, false},
+		{"Unknown", []State{{1, 3}, {3, 3}, {2, Unknown}}, Unknown, true},
+		{"Disagree", []State{{1, 3}, {3, 3}, {2, 2}}, Unknown, true},
+		{"NoLeader", []State{{1, 5}, {3, 5}}, Unknown, true},
+		{"NotGreatest", []State{{1, 1}, {3, 1}}, Unknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{})
+			defer w.Stop()
+			for i, s := range tt.states {
+				w.CreateProcess(int32(i))
+				w.AssignWorkFunction(int32(i), []byte("LCR"))
+				*w.ProcessesList[i].Context["LCR"].(*LCR) = LCR{State: s}
+			}
+			got, err := Verify(w.ProcessesList, "LCR")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	w := world.NewWithOptions(world.Options{})
+	defer w.Stop()
+	w.CreateProcess(0)
+	if _, err := Verify(w.ProcessesList, "LCR"); err == nil {
+		t.Errorf("Verify() succeeded without contexts")
+	}
+}
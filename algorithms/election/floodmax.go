@@ -0,0 +1,92 @@
+package election
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// FloodMax is a context of the FloodMax algorithm.
+// Rounds is an upper bound of the network diameter, the number of processes minus one by default.
+type FloodMax struct {
+	State
+	Max      int32
+	Rounds   int32
+	round    int32
+	started  bool
+	received map[int32]int
+	maxima   map[int32]int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "FLOODMAX",
+		Description: "FloodMax election on a connected graph, any processes wake the others up by FLOODMAX_INIT",
+		Work:        FloodMaxWorkFunction,
+		Contexts: map[string]context.Factory{
+			"FloodMax": func(node int32) context.Context {
+				return &FloodMax{
+					State:    newState(node),
+					Max:      Unknown,
+					received: make(map[int32]int),
+					maxima:   make(map[int32]int32),
+				}
+			},
+		},
+	})
+}
+
+// next sends the greatest known UID to all the neighbours in the next round.
+func (ctx *FloodMax) next(dp *process.Process) {
+	ctx.round++
+	for _, v := range dp.Neighbours() {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("FLOODMAX_MAX", ctx.round, ctx.Max))
+	}
+}
+
+// FloodMaxWorkFunction handles FLOODMAX_INIT and FLOODMAX_MAX messages.
+// The synchronous rounds are simulated: a process starts the next round after receiving
+// the messages of the current one from all the neighbours. After the rounds are over,
+// the greatest UID known is the leader.
+func FloodMaxWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("FLOODMAX"), s) {
+		return false
+	}
+	ctx := process.Ctx[*FloodMax](dp, "FloodMax")
+	if ctx.Rounds == 0 {
+		ctx.Rounds = int32(len(dp.Network.QueueMap)) - 1
+	}
+	if !ctx.started && s[0] != '*' {
+		ctx.started = true
+		ctx.Max = ctx.UID
+		if ctx.Rounds <= 0 || len(dp.Neighbours()) == 0 {
+			ctx.elect(dp, ctx.Max)
+			return true
+		}
+		ctx.next(dp)
+	}
+	if string(s) != "FLOODMAX_MAX" {
+		return true
+	}
+	round, max := m.GetInt32(), m.GetInt32()
+	ctx.received[round]++
+	if max > ctx.maxima[round] || ctx.received[round] == 1 {
+		ctx.maxima[round] = max
+	}
+	for ctx.Leader == Unknown && ctx.received[ctx.round] == len(dp.Neighbours()) {
+		if ctx.maxima[ctx.round] > ctx.Max {
+			ctx.Max = ctx.maxima[ctx.round]
+		}
+		delete(ctx.received, ctx.round)
+		delete(ctx.maxima, ctx.round)
+		if ctx.round == ctx.Rounds {
+			ctx.elect(dp, ctx.Max)
+			break
+		}
+		ctx.next(dp)
+	}
+	return true
+}
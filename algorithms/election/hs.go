@@ -0,0 +1,94 @@
+package election
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// HS is a context of the Hirschberg–Sinclair algorithm.
+type HS struct {
+	State
+	Phase   int32
+	started bool
+	replies int
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "HS",
+		Description: "Hirschberg–Sinclair election on a bidirected ring, all processes start by HS_INIT",
+		Work:        HSWorkFunction,
+		Contexts: map[string]context.Factory{
+			"HS": func(node int32) context.Context { return &HS{State: newState(node)} },
+		},
+	})
+}
+
+// probe sends the UID of the process in both directions for the current phase.
+// A process without neighbours is the leader at once.
+func (ctx *HS) probe(dp *process.Process) {
+	ctx.replies = 0
+	sent := false
+	for _, v := range dp.Neighbours() {
+		if v != dp.Node {
+			sent = true
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("HS_PROBE", ctx.UID, ctx.Phase, 1))
+		}
+	}
+	if !sent {
+		ctx.elect(dp, ctx.UID)
+	}
+}
+
+// HSWorkFunction handles HS_INIT, HS_PROBE, HS_REPLY and HS_ELECTED messages.
+// In phase k a process sends its UID to the distance 2^k in both directions.
+// Probes are swallowed by processes with greater UIDs, the others pass them on and
+// reply at the end of the way. A process getting both replies starts the next phase,
+// a process getting its own probe is the leader.
+func HSWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("HS"), s) {
+		return false
+	}
+	ctx := process.Ctx[*HS](dp, "HS")
+	if !ctx.started && string(s) != "HS_ELECTED" && s[0] != '*' {
+		ctx.started = true
+		ctx.probe(dp)
+	}
+	switch string(s) {
+	case "HS_PROBE":
+		uid, phase, hops := m.GetInt32(), m.GetInt32(), m.GetInt32()
+		switch {
+		case uid == ctx.UID:
+			if ctx.Leader == Unknown {
+				ctx.elect(dp, uid)
+				dp.Network.SendMessage(dp.Node, opposite(dp, m.From), messages.NewMessageByType("HS_ELECTED", uid))
+			}
+		case uid > ctx.UID && hops < 1<<phase:
+			dp.Network.SendMessage(dp.Node, opposite(dp, m.From), messages.NewMessageByType("HS_PROBE", uid, phase, hops+1))
+		case uid > ctx.UID:
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("HS_REPLY", uid, phase))
+		}
+	case "HS_REPLY":
+		uid, phase := m.GetInt32(), m.GetInt32()
+		if uid != ctx.UID {
+			dp.Network.SendMessage(dp.Node, opposite(dp, m.From), messages.NewMessageByType("HS_REPLY", uid, phase))
+			break
+		}
+		ctx.replies++
+		if ctx.replies == len(dp.Neighbours()) && phase == ctx.Phase && ctx.Leader == Unknown {
+			ctx.Phase++
+			ctx.probe(dp)
+		}
+	case "HS_ELECTED":
+		uid := m.GetInt32()
+		if uid != ctx.UID {
+			ctx.elect(dp, uid)
+			dp.Network.SendMessage(dp.Node, opposite(dp, m.From), messages.NewMessageByType("HS_ELECTED", uid))
+		}
+	}
+	return true
+}
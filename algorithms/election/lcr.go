@@ -0,0 +1,60 @@
+package election
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// LCR is a context of the LeLann–Chang–Roberts algorithm.
+type LCR struct {
+	State
+	started bool
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "LCR",
+		Description: "LeLann–Chang–Roberts election on a unidirectional ring, all processes start by LCR_INIT",
+		Work:        LCRWorkFunction,
+		Contexts: map[string]context.Factory{
+			"LCR": func(node int32) context.Context { return &LCR{State: newState(node)} },
+		},
+	})
+}
+
+// LCRWorkFunction handles LCR_INIT, LCR_UID and LCR_ELECTED messages.
+// Every process sends its UID to the successor, which passes on only UIDs greater than its own.
+// The process receiving its own UID is the leader and announces itself around the ring.
+func LCRWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("LCR"), s) {
+		return false
+	}
+	ctx := process.Ctx[*LCR](dp, "LCR")
+	next := successor(dp)
+	if !ctx.started && string(s) != "LCR_ELECTED" && s[0] != '*' {
+		ctx.started = true
+		dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("LCR_UID", ctx.UID))
+	}
+	switch string(s) {
+	case "LCR_UID":
+		uid := m.GetInt32()
+		switch {
+		case uid > ctx.UID:
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("LCR_UID", uid))
+		case uid == ctx.UID:
+			ctx.elect(dp, uid)
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("LCR_ELECTED", uid))
+		}
+	case "LCR_ELECTED":
+		uid := m.GetInt32()
+		if uid != ctx.UID {
+			ctx.elect(dp, uid)
+			dp.Network.SendMessage(dp.Node, next, messages.NewMessageByType("LCR_ELECTED", uid))
+		}
+	}
+	return true
+}
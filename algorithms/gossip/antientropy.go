@@ -0,0 +1,63 @@
+package gossip
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// AntiEntropy is a context of anti-entropy.
+type AntiEntropy struct {
+	Gossip
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "ANTIENTROPY",
+		Description: "anti-entropy gossip exchanging all the updates with random neighbours, ANTIENTROPY_UPDATE originates its argument",
+		Work:        AntiEntropyWorkFunction,
+		Contexts: map[string]context.Factory{
+			"AntiEntropy": func(int32) context.Context { return &AntiEntropy{Gossip: newGossip()} },
+		},
+	})
+}
+
+// AntiEntropyWorkFunction handles ANTIENTROPY_INIT, ANTIENTROPY_UPDATE, ANTIENTROPY_ROUND, ANTIENTROPY_PUSH
+// and ANTIENTROPY_PULL messages. Every round the process pushes all its updates to the contacted neighbours,
+// sends them its digest to pull the missing updates or does both, depending on the mode.
+func AntiEntropyWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("ANTIENTROPY"), s) {
+		return false
+	}
+	ctx := process.Ctx[*AntiEntropy](dp, "AntiEntropy")
+	switch string(s) {
+	case "ANTIENTROPY_INIT":
+		ctx.wake(dp, "ANTIENTROPY")
+	case "ANTIENTROPY_UPDATE":
+		ctx.learn(dp, "ANTIENTROPY", ctx.originate(dp, "ANTIENTROPY", m.GetInt32()))
+	case "ANTIENTROPY_ROUND":
+		if !ctx.next(dp, "ANTIENTROPY", false) {
+			break
+		}
+		for _, v := range ctx.targets(dp) {
+			if ctx.pushes() && len(ctx.Known) > 0 {
+				dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("ANTIENTROPY_PUSH", encode(ctx.Known)...))
+			}
+			if ctx.pulls() {
+				dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("ANTIENTROPY_PULL", ctx.digest()...))
+			}
+		}
+	case "ANTIENTROPY_PUSH":
+		for _, u := range decode(m.GetInt32s()) {
+			ctx.learn(dp, "ANTIENTROPY", u)
+		}
+	case "ANTIENTROPY_PULL":
+		if updates := missing(ctx.Known, m.GetInt32s()); len(updates) > 0 {
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("ANTIENTROPY_PUSH", encode(updates)...))
+		}
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+// Package gossip implements epidemic dissemination of updates: anti-entropy and rumour mongering.
+//
+// Every round a process contacts Fanout random neighbours chosen by the network random generator
+// and pushes its updates to them, pulls their updates or does both. Anti-entropy exchanges all the updates
+// every round, rumour mongering pushes only the hot ones: a process loses interest in an update
+// after contacting K processes which have already known it.
+//
+// A process starts its rounds when it receives <NAME>_INIT or learns an update, <NAME>_UPDATE originates
+// an update with its argument as a value. Updates are identified by their origins and sequence numbers.
+// Updates and processes learning them are reported to the network tracer as broadcast and accept events,
+// so that the fraction of informed processes per tick can be found by World.CheckBroadcast.
+package gossip
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	props "github.com/trmigor/distr-model/internal/broadcast"
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Modes of exchange with the contacted neighbours.
+const (
+	// Push sends the updates to the neighbours.
+	Push = "push"
+	// Pull sends the digest of the known updates to the neighbours, which answer with the missing ones.
+	Pull = "pull"
+	// PushPull does both.
+	PushPull = "pushpull"
+)
+
+// Update is a value disseminated by gossip, Tick is the tick the process has learned it at.
+type Update struct {
+	Origin int32
+	Seq    int32
+	Value  int32
+	Tick   int64
+}
+
+// id identifies an update.
+type id struct {
+	origin, seq int32
+}
+
+// Gossip is the state and the settings of a process, it is embedded into contexts of all the algorithms.
+// Mode is the mode of exchange, push by default, Fanout is the number of neighbours contacted every round,
+// 1 by default, Period is the length of a round in ticks, twice the greatest neighbour latency by default,
+// and Rounds is the number of rounds the process keeps gossiping after it has learned its last update,
+// 0 by default for gossiping as long as the model runs. Known holds the updates in the order they are learned.
+type Gossip struct {
+	Mode   string
+	Fanout int
+	Period int64
+	Rounds int
+	Seq    int32
+	Known  []Update
+	known  map[id]bool
+	active bool
+	quiet  int
+}
+
+func newGossip() Gossip {
+	return Gossip{Mode: Push, Fanout: 1, Known: make([]Update, 0), known: make(map[id]bool)}
+}
+
+// Result returns the state of the process.
+func (g *Gossip) Result() *Gossip {
+	return g
+}
+
+// Outcome is implemented by contexts of all the gossip algorithms.
+type Outcome interface {
+	Result() *Gossip
+}
+
+// Knows reports whether the process knows the update of the origin with the sequence number.
+func (g *Gossip) Knows(origin int32, seq int32) bool {
+	return g.known[id{origin, seq}]
+}
+
+// Verify checks that all the processes with the context key know the same updates and returns the number of them.
+func Verify(ps []*process.Process, key string) (int, error) {
+	res := -1
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return 0, fmt.Errorf("process %v has no gossip context %v", dp.Node, key)
+		}
+		n := len(o.Result().Known)
+		if res >= 0 && n != res {
+			return 0, fmt.Errorf("process %v knows %v updates, others know %v", dp.Node, n, res)
+		}
+		res = n
+	}
+	if res < 0 {
+		res = 0
+	}
+	return res, nil
+}
+
+// originate creates a new update of the process and reports it.
+func (g *Gossip) originate(dp *process.Process, name string, value int32) Update {
+	g.Seq++
+	u := Update{Origin: dp.Node, Seq: g.Seq, Value: value}
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Broadcast, From: dp.Node, To: dp.Node,
+		Message: fmt.Sprintf("%v %v %v %v", name, u.Origin, u.Seq, u.Value)})
+	return u
+}
+
+// learn adds the update to the known ones, reports it and wakes the process up.
+// It returns false if the update is already known.
+func (g *Gossip) learn(dp *process.Process, name string, u Update) bool {
+	if g.known[id{u.Origin, u.Seq}] {
+		return false
+	}
+	u.Tick = dp.Network.Tick
+	g.known[id{u.Origin, u.Seq}] = true
+	g.Known = append(g.Known, u)
+	logging.Infof("[%v]: learned %v from %v", dp.Node, u.Value, u.Origin)
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Accept, From: dp.Node, To: dp.Node,
+		Message: fmt.Sprintf("%v %v %v %v", name, u.Origin, u.Seq, u.Value)})
+	g.quiet = 0
+	g.wake(dp, name)
+	return true
+}
+
+// wake starts the rounds of the process unless they are going on, the first one is performed at once.
+func (g *Gossip) wake(dp *process.Process, name string) {
+	if g.active {
+		return
+	}
+	g.active = true
+	dp.Network.SendTimeout(dp.Node, 0, messages.NewMessageByType(name+"_ROUND"))
+}
+
+// next schedules the next round unless the process is idle and has been quiet for Rounds rounds.
+// It reports whether the current round is to be performed.
+func (g *Gossip) next(dp *process.Process, name string, busy bool) bool {
+	g.active = false
+	if g.Rounds > 0 && !busy && g.quiet >= g.Rounds {
+		return false
+	}
+	g.quiet++
+	g.active = true
+	dp.Network.SendTimeout(dp.Node, g.period(dp), messages.NewMessageByType(name+"_ROUND"))
+	return true
+}
+
+// pushes reports whether the process pushes its updates to the contacted neighbours.
+func (g *Gossip) pushes() bool {
+	return !strings.EqualFold(g.Mode, Pull)
+}
+
+// pulls reports whether the process pulls the updates of the contacted neighbours.
+func (g *Gossip) pulls() bool {
+	return strings.EqualFold(g.Mode, Pull) || strings.EqualFold(g.Mode, PushPull)
+}
+
+// period returns the length of a round.
+func (g *Gossip) period(dp *process.Process) int64 {
+	if g.Period > 0 {
+		return g.Period
+	}
+	var res int64 = 1
+	for _, v := range dp.Neighbours() {
+		if l := 2 * int64(dp.Network.GetLink(dp.Node, v)); l > res {
+			res = l
+		}
+	}
+	return res
+}
+
+// targets returns Fanout random neighbours of the process, all of them if there are fewer.
+func (g *Gossip) targets(dp *process.Process) []int32 {
+	neighbours := dp.Neighbours()
+	if g.Fanout >= len(neighbours) {
+		return neighbours
+	}
+	res := make([]int32, 0, g.Fanout)
+	for _, i := range dp.Network.Rng.Perm(len(neighbours))[:g.Fanout] {
+		res = append(res, neighbours[i])
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// digest returns the identifiers of the known updates as message arguments.
+func (g *Gossip) digest() []int32 {
+	res := make([]int32, 0, 2*len(g.Known))
+	for _, u := range g.Known {
+		res = append(res, u.Origin, u.Seq)
+	}
+	return res
+}
+
+// missing returns the updates, which are not in the digest.
+func missing(updates []Update, digest []int32) []Update {
+	known := make(map[id]bool)
+	for i := 0; i+1 < len(digest); i += 2 {
+		known[id{digest[i], digest[i+1]}] = true
+	}
+	res := make([]Update, 0)
+	for _, u := range updates {
+		if !known[id{u.Origin, u.Seq}] {
+			res = append(res, u)
+		}
+	}
+	return res
+}
+
+// encode returns the updates as message arguments, values are the last arguments of the triples.
+func encode(updates []Update) []int32 {
+	res := make([]int32, 0, 3*len(updates))
+	for _, u := range updates {
+		res = append(res, u.Origin, u.Seq, u.Value)
+	}
+	return res
+}
+
+// decode returns the updates from the message arguments.
+func decode(args []int32) []Update {
+	res := make([]Update, 0, len(args)/3)
+	for i := 0; i+2 < len(args); i += 3 {
+		res = append(res, Update{Origin: args[i], Seq: args[i+1], Value: args[i+2]})
+	}
+	return res
+}
+
+func init() {
+	props.Guarantee("ANTIENTROPY", props.Integrity)
+	props.Guarantee("RUMOR", props.Integrity)
+}
@@ -0,0 +1,138 @@
+package gossip
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/broadcast"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+		want   int
+	}{
+		{"AntiEntropy", "../../configs/gossip/antientropy.data", "AntiEntropy", 2},
+		{"Rumor", "../../configs/gossip/rumor.data", "Rumor", 1},
+		{"Congestion", "../../configs/network/congestion.data", "AntiEntropy", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *broadcast.Checker
+			w := sample.Run(t, tt.config, 1000, true, func(w *world.World) { c = w.CheckBroadcast() })
+			got, err := Verify(w.ProcessesList, tt.key)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+			if v := c.Violations(w.Nodes()); len(v) > 0 {
+				t.Errorf("Checker.Violations() = %v", v)
+			}
+			for _, id := range c.IDs() {
+				curve := c.Curve(id, w.Nodes())
+				if last := curve[len(curve)-1]; last.Fraction != 1 {
+					t.Errorf("Checker.Curve(%v) ends with %v, want 1", id, last.Fraction)
+				}
+			}
+		})
+	}
+}
+
+// run originates random updates at random processes of a random connected graph with the message loss rate
+// and checks that all the processes learn all the updates.
+func run(t *testing.T, function string, key string, fields map[string]string, n int32, loss float64, rng *rand.Rand) {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 100000})
+	defer w.Stop()
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte(function))
+		fields["Fanout"] = fmt.Sprint(1 + rng.Intn(2))
+		if err := w.ProcessesList[i].SetContextFields(key, fields); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := int32(1); i < n; i++ {
+		w.Network.CreateLink(i, rng.Int31n(i), true, 1+rng.Int31n(3))
+		if j := rng.Int31n(n); j != i {
+			w.Network.CreateLink(i, j, true, 1+rng.Int31n(3))
+		}
+	}
+	w.Network.SendMessage(-1, -1, messages.NewMessageByType(function+"_INIT"))
+	updates := 1 + rng.Intn(3)
+	for i := 0; i < updates; i++ {
+		w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType(function+"_UPDATE", int32(i)))
+		w.Wait(int64(rng.Intn(4)))
+	}
+	w.Network.SetErrorRate(loss)
+	w.Wait(1000)
+
+	got, err := Verify(w.ProcessesList, key)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got != updates {
+		t.Errorf("Verify() = %v, want %v", got, updates)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+		mode     string
+		k        string
+		loss     float64
+	}{
+		{"ANTIENTROPY", "AntiEntropy", Push, "", 0.1},
+		{"ANTIENTROPY", "AntiEntropy", Pull, "", 0.1},
+		{"ANTIENTROPY", "AntiEntropy", PushPull, "", 0.1},
+		// Rumours should not go cold before reaching all the processes.
+		{"RUMOR", "Rumor", Push, "100", 0},
+		{"RUMOR", "Rumor", Pull, "100", 0},
+		{"RUMOR", "Rumor", PushPull, "100", 0.1},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 3, 5, 8} {
+			for i := 0; i < 5; i++ {
+				t.Run(fmt.Sprintf("%v-%v%v/%v", tt.function, tt.mode, n, i), func(t *testing.T) {
+					fields := map[string]string{"Mode": tt.mode}
+					if tt.k != "" {
+						fields["K"] = tt.k
+					}
+					run(t, tt.function, tt.key, fields, n, tt.loss, rng)
+				})
+			}
+		}
+	}
+}
+
+// TestRumorCools checks that rumour mongering stops gossiping once its rumours are cold.
+func TestRumorCools(t *testing.T) {
+	w := world.NewWithOptions(world.Options{Seed: 1, MaxTicks: 1000})
+	defer w.Stop()
+	if !w.ParseConfig([]byte("../../configs/gossip/rumor.data")) {
+		t.Fatalf("World.ParseConfig() = false")
+	}
+	for _, dp := range w.ProcessesList {
+		ctx := dp.Context["Rumor"].(*Rumor)
+		if len(ctx.hot) != 0 || ctx.active {
+			t.Errorf("process %v keeps %v hot rumours, active = %v", dp.Node, len(ctx.hot), ctx.active)
+		}
+	}
+	if got := w.Network.Metrics().Sent(); got > 16*16 {
+		t.Errorf("Collector.Sent() = %v, want at most %v", got, 16*16)
+	}
+}
@@ -0,0 +1,117 @@
+package gossip
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Rumor is a context of rumour mongering. K is the number of processes, which have already known a rumour,
+// the process contacts before it loses interest in the rumour, 2 by default.
+type Rumor struct {
+	Gossip
+	K   int
+	hot map[id]int
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "RUMOR",
+		Description: "rumour mongering spreading hot updates to random neighbours, RUMOR_UPDATE originates its argument",
+		Work:        RumorWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Rumor": func(int32) context.Context { return &Rumor{Gossip: newGossip(), K: 2, hot: make(map[id]int)} },
+		},
+	})
+}
+
+// learn adds the update to the known ones and makes it a hot rumour.
+func (ctx *Rumor) learn(dp *process.Process, u Update) bool {
+	if !ctx.Gossip.learn(dp, "RUMOR", u) {
+		return false
+	}
+	ctx.hot[id{u.Origin, u.Seq}] = 0
+	return true
+}
+
+// hotUpdates returns the hot rumours in the order they are learned.
+func (ctx *Rumor) hotUpdates() []Update {
+	res := make([]Update, 0, len(ctx.hot))
+	for _, u := range ctx.Known {
+		if _, ok := ctx.hot[id{u.Origin, u.Seq}]; ok {
+			res = append(res, u)
+		}
+	}
+	return res
+}
+
+// cool counts a contact with a process, which has already known the rumour.
+func (ctx *Rumor) cool(r id) {
+	n, ok := ctx.hot[r]
+	if !ok {
+		return
+	}
+	if n+1 >= ctx.K {
+		delete(ctx.hot, r)
+		return
+	}
+	ctx.hot[r] = n + 1
+}
+
+// RumorWorkFunction handles RUMOR_INIT, RUMOR_UPDATE, RUMOR_ROUND, RUMOR_PUSH, RUMOR_PULL and RUMOR_KNOWN messages.
+// Every round the process with hot rumours pushes them to the contacted neighbours, which answer with the ones
+// they have already known, and pulls the missing hot rumours of the neighbours, depending on the mode.
+// A rumour goes cold after K such answers, the process stops gossiping when all its rumours are cold
+// and it has been quiet for Rounds rounds.
+func RumorWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("RUMOR"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Rumor](dp, "Rumor")
+	switch string(s) {
+	case "RUMOR_INIT":
+		ctx.wake(dp, "RUMOR")
+	case "RUMOR_UPDATE":
+		ctx.learn(dp, ctx.originate(dp, "RUMOR", m.GetInt32()))
+	case "RUMOR_ROUND":
+		if !ctx.next(dp, "RUMOR", len(ctx.hot) > 0) {
+			break
+		}
+		hot := ctx.hotUpdates()
+		for _, v := range ctx.targets(dp) {
+			if ctx.pushes() && len(hot) > 0 {
+				dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("RUMOR_PUSH", encode(hot)...))
+			}
+			if ctx.pulls() {
+				dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("RUMOR_PULL", ctx.digest()...))
+			}
+		}
+	case "RUMOR_PUSH":
+		known := make([]int32, 0)
+		for _, u := range decode(m.GetInt32s()) {
+			if !ctx.learn(dp, u) {
+				known = append(known, u.Origin, u.Seq)
+			}
+		}
+		if len(known) > 0 {
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RUMOR_KNOWN", known...))
+		}
+	case "RUMOR_PULL":
+		digest := m.GetInt32s()
+		for i := 0; i+1 < len(digest); i += 2 {
+			ctx.cool(id{digest[i], digest[i+1]})
+		}
+		if updates := missing(ctx.hotUpdates(), digest); len(updates) > 0 {
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RUMOR_PUSH", encode(updates)...))
+		}
+	case "RUMOR_KNOWN":
+		known := m.GetInt32s()
+		for i := 0; i+1 < len(known); i += 2 {
+			ctx.cool(id{known[i], known[i+1]})
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package mutex
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Lamport is a context of Lamport's queue algorithm.
+type Lamport struct {
+	Section
+	Clock      int32
+	requesting bool
+	stamp      stamp
+	queue      []stamp
+	last       map[int32]int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "LAMPORT",
+		Description: "Lamport's queue mutual exclusion on a complete graph with FIFO links, LAMPORT_INIT requests the critical section",
+		Work:        LamportWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Lamport": func(int32) context.Context {
+				return &Lamport{Section: newSection(), last: make(map[int32]int32)}
+			},
+		},
+		FIFO: true,
+	})
+}
+
+// request puts the request into the queues of all the processes.
+func (ctx *Lamport) request(dp *process.Process) {
+	ctx.requesting = true
+	ctx.Clock++
+	ctx.stamp = stamp{ctx.Clock, dp.Node}
+	ctx.queue = insert(ctx.queue, ctx.stamp)
+	broadcast(dp, "LAMPORT_REQUEST", ctx.Clock)
+	ctx.check(dp)
+}
+
+// check enters the critical section when the request is the first in the queue
+// and later messages have been received from all the other processes.
+func (ctx *Lamport) check(dp *process.Process) {
+	if !ctx.requesting || ctx.queue[0] != ctx.stamp {
+		return
+	}
+	for _, v := range dp.Others() {
+		if ts, ok := ctx.last[v]; !ok || !ctx.stamp.less(stamp{ts, v}) {
+			return
+		}
+	}
+	ctx.requesting = false
+	ctx.enter(dp, "LAMPORT")
+}
+
+// LamportWorkFunction handles LAMPORT_INIT, LAMPORT_REQUEST, LAMPORT_REPLY, LAMPORT_RELEASE and LAMPORT_EXIT messages.
+// Every process keeps the queue of requests ordered by timestamps. A process enters the critical section
+// when its request is the first one and it has received later messages from all the others,
+// so 3(N-1) messages are sent per entry.
+func LamportWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("LAMPORT"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Lamport](dp, "Lamport")
+	switch string(s) {
+	case "LAMPORT_INIT":
+		ctx.Pending++
+		if !ctx.requesting && !ctx.InCS {
+			ctx.request(dp)
+		}
+	case "LAMPORT_REQUEST":
+		ts := m.GetInt32()
+		tick(&ctx.Clock, ts)
+		ctx.last[m.From] = ts
+		ctx.queue = insert(ctx.queue, stamp{ts, m.From})
+		dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("LAMPORT_REPLY", ctx.Clock))
+		ctx.check(dp)
+	case "LAMPORT_REPLY":
+		ts := m.GetInt32()
+		tick(&ctx.Clock, ts)
+		ctx.last[m.From] = ts
+		ctx.check(dp)
+	case "LAMPORT_RELEASE":
+		ts := m.GetInt32()
+		tick(&ctx.Clock, ts)
+		ctx.last[m.From] = ts
+		ctx.queue = remove(ctx.queue, m.From)
+		ctx.check(dp)
+	case "LAMPORT_EXIT":
+		ctx.exit(dp, "LAMPORT")
+		ctx.queue = remove(ctx.queue, dp.Node)
+		ctx.Clock++
+		broadcast(dp, "LAMPORT_RELEASE", ctx.Clock)
+		if ctx.Pending > 0 {
+			ctx.request(dp)
+		}
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package mutex
+
+import (
+	"math"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Maekawa is a context of Maekawa's algorithm. The process is both a requester and a voter of its quorum.
+type Maekawa struct {
+	Section
+	Clock      int32
+	requesting bool
+	stamp      stamp
+	quorum     []int32
+	grants     map[int32]bool
+	failed     map[int32]bool
+	inquiries  map[int32]bool
+	locked     bool
+	lock       stamp
+	inquired   bool
+	queue      []stamp
+	told       map[int32]bool
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "MAEKAWA",
+		Description: "Maekawa mutual exclusion with grid quorums on a complete graph with FIFO links, MAEKAWA_INIT requests the critical section",
+		Work:        MaekawaWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Maekawa": func(int32) context.Context {
+				return &Maekawa{
+					Section:   newSection(),
+					grants:    make(map[int32]bool),
+					failed:    make(map[int32]bool),
+					inquiries: make(map[int32]bool),
+					told:      make(map[int32]bool),
+				}
+			},
+		},
+		FIFO: true,
+	})
+}
+
+// Quorum returns the quorum of the process: the processes are placed row by row into a square grid
+// and the quorum is the row and the column of the process, so any two quorums intersect.
+func Quorum(node int32, nodes []int32) []int32 {
+	k := int(math.Ceil(math.Sqrt(float64(len(nodes)))))
+	pos := -1
+	for i, v := range nodes {
+		if v == node {
+			pos = i
+		}
+	}
+	res := make([]int32, 0, 2*k)
+	if pos < 0 {
+		return res
+	}
+	for i, v := range nodes {
+		if i/k == pos/k || i%k == pos%k {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// request asks the quorum for votes.
+func (ctx *Maekawa) request(dp *process.Process) {
+	if ctx.quorum == nil {
+		ctx.quorum = Quorum(dp.Node, dp.Network.Nodes())
+	}
+	ctx.requesting = true
+	ctx.Clock++
+	ctx.stamp = stamp{ctx.Clock, dp.Node}
+	for _, v := range ctx.quorum {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("MAEKAWA_REQUEST", ctx.Clock))
+	}
+}
+
+// yield returns the vote to the voter which has inquired about it.
+func (ctx *Maekawa) yield(dp *process.Process, voter int32) {
+	delete(ctx.grants, voter)
+	delete(ctx.inquiries, voter)
+	dp.Network.SendMessage(dp.Node, voter, messages.NewMessageByType("MAEKAWA_YIELD"))
+}
+
+// vote locks the voter for the first queued request, if any. The rest of queued requests fail.
+func (ctx *Maekawa) vote(dp *process.Process) {
+	if len(ctx.queue) == 0 {
+		ctx.locked = false
+		return
+	}
+	ctx.locked, ctx.lock, ctx.inquired = true, ctx.queue[0], false
+	ctx.queue = ctx.queue[1:]
+	delete(ctx.told, ctx.lock.node)
+	dp.Network.SendMessage(dp.Node, ctx.lock.node, messages.NewMessageByType("MAEKAWA_LOCKED"))
+	for _, r := range ctx.queue {
+		ctx.fail(dp, r.node)
+	}
+}
+
+// fail tells the requester that the voter has voted for an earlier request, unless it is already told.
+func (ctx *Maekawa) fail(dp *process.Process, node int32) {
+	if !ctx.told[node] {
+		ctx.told[node] = true
+		dp.Network.SendMessage(dp.Node, node, messages.NewMessageByType("MAEKAWA_FAILED"))
+	}
+}
+
+// MaekawaWorkFunction handles MAEKAWA_INIT, MAEKAWA_REQUEST, MAEKAWA_LOCKED, MAEKAWA_FAILED,
+// MAEKAWA_INQUIRE, MAEKAWA_YIELD, MAEKAWA_RELEASE and MAEKAWA_EXIT messages.
+// A process enters the critical section when all the processes of its quorum have voted for it.
+// Every voter votes for one request at a time. Deadlocks are avoided by inquiring the voted process
+// about an earlier request, it yields the vote if it has failed to get another one.
+func MaekawaWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("MAEKAWA"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Maekawa](dp, "Maekawa")
+	switch string(s) {
+	case "MAEKAWA_INIT":
+		ctx.Pending++
+		if !ctx.requesting && !ctx.InCS {
+			ctx.request(dp)
+		}
+	case "MAEKAWA_REQUEST":
+		ts := m.GetInt32()
+		tick(&ctx.Clock, ts)
+		r := stamp{ts, m.From}
+		if !ctx.locked {
+			ctx.queue = insert(ctx.queue, r)
+			ctx.vote(dp)
+			break
+		}
+		ctx.queue = insert(ctx.queue, r)
+		if ctx.lock.less(r) || ctx.queue[0] != r {
+			ctx.fail(dp, m.From)
+			break
+		}
+		if len(ctx.queue) > 1 {
+			ctx.fail(dp, ctx.queue[1].node)
+		}
+		if !ctx.inquired {
+			ctx.inquired = true
+			dp.Network.SendMessage(dp.Node, ctx.lock.node, messages.NewMessageByType("MAEKAWA_INQUIRE", ctx.lock.ts))
+		}
+	case "MAEKAWA_LOCKED":
+		ctx.grants[m.From] = true
+		delete(ctx.failed, m.From)
+		if ctx.requesting && len(ctx.grants) == len(ctx.quorum) {
+			ctx.requesting = false
+			ctx.inquiries = make(map[int32]bool)
+			ctx.enter(dp, "MAEKAWA")
+		}
+	case "MAEKAWA_FAILED":
+		ctx.failed[m.From] = true
+		for v := range ctx.inquiries {
+			ctx.yield(dp, v)
+		}
+	case "MAEKAWA_INQUIRE":
+		ts := m.GetInt32()
+		if !ctx.requesting || ts != ctx.stamp.ts || !ctx.grants[m.From] {
+			break
+		}
+		if len(ctx.failed) > 0 {
+			ctx.yield(dp, m.From)
+		} else {
+			ctx.inquiries[m.From] = true
+		}
+	case "MAEKAWA_YIELD":
+		if ctx.locked && ctx.lock.node == m.From {
+			ctx.queue = insert(ctx.queue, ctx.lock)
+			ctx.vote(dp)
+		}
+	case "MAEKAWA_RELEASE":
+		if ctx.locked && ctx.lock.node == m.From {
+			ctx.vote(dp)
+		}
+	case "MAEKAWA_EXIT":
+		ctx.exit(dp, "MAEKAWA")
+		ctx.grants = make(map[int32]bool)
+		ctx.failed = make(map[int32]bool)
+		for _, v := range ctx.quorum {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("MAEKAWA_RELEASE"))
+		}
+		if ctx.Pending > 0 {
+			ctx.request(dp)
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+// Package mutex implements distributed mutual exclusion algorithms:
+// Ricart–Agrawala and Lamport's queue on complete graphs, Maekawa with grid quorums
+// and a token ring.
+//
+// A process requests the critical section once for every <NAME>_INIT message it receives
+// and stays there for Hold ticks. Entries and exits are reported to the network tracer
+// as enter and exit events, so they can be checked by World.CheckExclusion.
+package mutex
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Section is the state of the process regarding the critical section,
+// it is embedded into contexts of all the algorithms.
+// Hold is the number of ticks spent in the critical section, Pending is the number of requests not served yet.
+type Section struct {
+	Hold    int64
+	Pending int
+	Entries int
+	InCS    bool
+}
+
+func newSection() Section {
+	return Section{Hold: 1}
+}
+
+// Result returns the state of the process regarding the critical section.
+func (s *Section) Result() *Section {
+	return s
+}
+
+// Outcome is implemented by contexts of all the mutual exclusion algorithms.
+type Outcome interface {
+	Result() *Section
+}
+
+// enter moves the process into the critical section and schedules the exit after Hold ticks.
+func (s *Section) enter(dp *process.Process, name string) {
+	s.InCS = true
+	s.Pending--
+	s.Entries++
+	logging.Infof("[%v]: enter CS", dp.Node)
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Enter, From: dp.Node, To: dp.Node, Message: name})
+	dp.Network.SendTimeout(dp.Node, s.Hold, messages.NewMessageByType(name+"_EXIT"))
+}
+
+// exit moves the process out of the critical section.
+func (s *Section) exit(dp *process.Process, name string) {
+	s.InCS = false
+	logging.Infof("[%v]: exit CS", dp.Node)
+	dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Exit, From: dp.Node, To: dp.Node, Message: name})
+}
+
+// Verify checks that all the requests of the processes with the context key have been served
+// and none of the processes is left in the critical section. It returns the total number of entries.
+func Verify(ps []*process.Process, key string) (int, error) {
+	res := 0
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return 0, fmt.Errorf("process %v has no mutual exclusion context %v", dp.Node, key)
+		}
+		s := o.Result()
+		if s.InCS {
+			return 0, fmt.Errorf("process %v is in the critical section", dp.Node)
+		}
+		if s.Pending > 0 {
+			return 0, fmt.Errorf("process %v has %v requests not served", dp.Node, s.Pending)
+		}
+		res += s.Entries
+	}
+	return res, nil
+}
+
+// stamp is a Lamport timestamp of a request made unique by the node number.
+type stamp struct {
+	ts, node int32
+}
+
+func (s stamp) less(t stamp) bool {
+	if s.ts != t.ts {
+		return s.ts < t.ts
+	}
+	return s.node < t.node
+}
+
+// insert adds the stamp into the sorted queue.
+func insert(queue []stamp, s stamp) []stamp {
+	i := sort.Search(len(queue), func(i int) bool { return s.less(queue[i]) })
+	queue = append(queue, stamp{})
+	copy(queue[i+1:], queue[i:])
+	queue[i] = s
+	return queue
+}
+
+// remove deletes the requests of the node from the queue.
+func remove(queue []stamp, node int32) []stamp {
+	res := queue[:0]
+	for _, s := range queue {
+		if s.node != node {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// tick advances the Lamport clock by a received timestamp.
+func tick(clock *int32, ts int32) {
+	if ts > *clock {
+		*clock = ts
+	}
+	*clock++
+}
+
+// broadcast sends the message to all the other neighbours.
+func broadcast(dp *process.Process, t string, args ...int32) {
+	for _, v := range dp.Others() {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType(t, args...))
+	}
+}
+
+// successor returns the next process on a ring: the least neighbour greater than the process
+// or the least neighbour at all, if there are no greater ones.
+func successor(dp *process.Process) int32 {
+	ns := dp.Neighbours()
+	if len(ns) == 0 {
+		return dp.Node
+	}
+	i := sort.Search(len(ns), func(i int) bool { return ns[i] > dp.Node })
+	if i == len(ns) {
+		return ns[0]
+	}
+	return ns[i]
+}
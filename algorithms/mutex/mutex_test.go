@@ -0,0 +1,180 @@
+package mutex
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		key     string
+		entries int
+		per     float64
+	}{
+		{"RA", "../../configs/mutex/ra.data", "RA", 6, 8},
+		{"Lamport", "../../configs/mutex/lamport.data", "Lamport", 6, 12},
+		{"Maekawa", "../../configs/mutex/maekawa.data", "Maekawa", 10, 0},
+		{"TokenRing", "../../configs/mutex/tokenring.data", "TokenRing", 4, 0},
+		{"Routing", "../../configs/routing/maekawa.data", "Maekawa", 9, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var x *world.Exclusion
+			w := sample.Run(t, tt.config, 1000, false, func(w *world.World) { x = w.CheckExclusion() })
+			got, err := Verify(w.ProcessesList, tt.key)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.entries || x.Entries() != tt.entries {
+				t.Errorf("Verify() = %v, Exclusion.Entries() = %v, want %v", got, x.Entries(), tt.entries)
+			}
+			if v := x.Violations(); len(v) > 0 {
+				t.Errorf("Exclusion.Violations() = %v", v)
+			}
+			if tt.per > 0 && x.MessagesPerEntry() != tt.per {
+				t.Errorf("Exclusion.MessagesPerEntry() = %v, want %v", x.MessagesPerEntry(), tt.per)
+			}
+		})
+	}
+}
+
+// compete runs n processes requesting the critical section at random moments.
+func compete(t *testing.T, function string, key string, n int32, ring bool, rng *rand.Rand) {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 100000})
+	defer w.Stop()
+	x := w.CheckExclusion()
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+	}
+	for i := int32(0); i < n; i++ {
+		if ring {
+			w.Network.CreateLink(i, (i+1)%n, false, 1+rng.Int31n(5))
+		} else {
+			for j := i + 1; j < n; j++ {
+				w.Network.CreateLink(i, j, true, 1+rng.Int31n(5))
+			}
+		}
+		w.AssignWorkFunction(i, []byte(function))
+		if err := w.SetContext(i, key, map[string]string{"Hold": fmt.Sprint(1 + rng.Intn(3))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	requests := 3 * int(n)
+	for i := 0; i < requests; i++ {
+		w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType(function+"_INIT"))
+		if rng.Intn(2) == 0 {
+			w.Wait(int64(rng.Intn(4)))
+		}
+	}
+	if !w.Run() {
+		t.Fatalf("World.Run() = false")
+	}
+	got, err := Verify(w.ProcessesList, key)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got != requests {
+		t.Errorf("Verify() = %v, want %v", got, requests)
+	}
+	if v := x.Violations(); len(v) > 0 {
+		t.Errorf("Exclusion.Violations() = %v", v)
+	}
+}
+
+func TestCompetition(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+		ring     bool
+	}{
+		{"RA", "RA", false},
+		{"LAMPORT", "Lamport", false},
+		{"MAEKAWA", "Maekawa", false},
+		{"TOKENRING", "TokenRing", true},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 5, 10} {
+			for i := 0; i < 5; i++ {
+				t.Run(fmt.Sprintf("%v%v/%v", tt.function, n, i), func(t *testing.T) {
+					compete(t, tt.function, tt.key, n, tt.ring, rng)
+				})
+			}
+		}
+	}
+}
+
+func TestQuorum(t *testing.T) {
+	nodes := []int32{0, 1, 2, 3, 4, 5, 6}
+	tests := []struct {
+		node int32
+		want []int32
+	}{
+		{0, []int32{0, 1, 2, 3, 6}},
+		{4, []int32{1, 3, 4, 5}},
+		{6, []int32{0, 3, 6}},
+		{7, []int32{}},
+	}
+	for _, tt := range tests {
+		if got := Quorum(tt.node, nodes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Quorum(%v) = %v, want %v", tt.node, got, tt.want)
+		}
+	}
+	for i := range nodes {
+		for j := range nodes {
+			a, b := Quorum(int32(i), nodes), Quorum(int32(j), nodes)
+			common := false
+			for _, u := range a {
+				for _, v := range b {
+					common = common || u == v
+				}
+			}
+			if !common {
+				t.Errorf("Quorum(%v) = %v and Quorum(%v) = %v do not intersect", i, a, j, b)
+			}
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections []Section
+		want     int
+		wantErr  bool
+	}{
+		{"Served", []Section{{Entries: 2}, {Entries: 1}}, 3, false},
+		{"Pending", []Section{{Entries: 2}, {Pending: 1}}, 0, true},
+		{"InCS", []Section{{Entries: 2, InCS: true}}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{})
+			defer w.Stop()
+			for i, s := range tt.sections {
+				w.CreateProcess(int32(i))
+				w.AssignWorkFunction(int32(i), []byte("RA"))
+				w.ProcessesList[i].Context["RA"].(*RA).Section = s
+			}
+			got, err := Verify(w.ProcessesList, "RA")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
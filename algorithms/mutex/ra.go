@@ -0,0 +1,88 @@
+package mutex
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// RA is a context of the Ricart–Agrawala algorithm.
+type RA struct {
+	Section
+	Clock      int32
+	requesting bool
+	stamp      stamp
+	replies    int
+	deferred   []int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "RA",
+		Description: "Ricart–Agrawala mutual exclusion on a complete graph, RA_INIT requests the critical section",
+		Work:        RAWorkFunction,
+		Contexts: map[string]context.Factory{
+			"RA": func(int32) context.Context { return &RA{Section: newSection()} },
+		},
+	})
+}
+
+// request asks all the other processes for permission.
+func (ctx *RA) request(dp *process.Process) {
+	ctx.requesting = true
+	ctx.Clock++
+	ctx.stamp = stamp{ctx.Clock, dp.Node}
+	ctx.replies = 0
+	broadcast(dp, "RA_REQUEST", ctx.Clock)
+	ctx.check(dp)
+}
+
+// check enters the critical section when all the processes have replied.
+func (ctx *RA) check(dp *process.Process) {
+	if ctx.requesting && ctx.replies == len(dp.Others()) {
+		ctx.requesting = false
+		ctx.enter(dp, "RA")
+	}
+}
+
+// RAWorkFunction handles RA_INIT, RA_REQUEST, RA_REPLY and RA_EXIT messages.
+// A requesting process asks all the others for permission. Permissions are deferred
+// by the processes in the critical section and by the ones with earlier requests
+// until they leave it, so 2(N-1) messages are sent per entry.
+func RAWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("RA"), s) {
+		return false
+	}
+	ctx := process.Ctx[*RA](dp, "RA")
+	switch string(s) {
+	case "RA_INIT":
+		ctx.Pending++
+		if !ctx.requesting && !ctx.InCS {
+			ctx.request(dp)
+		}
+	case "RA_REQUEST":
+		ts := m.GetInt32()
+		tick(&ctx.Clock, ts)
+		if ctx.InCS || ctx.requesting && ctx.stamp.less(stamp{ts, m.From}) {
+			ctx.deferred = append(ctx.deferred, m.From)
+		} else {
+			dp.Network.SendMessage(dp.Node, m.From, messages.NewMessageByType("RA_REPLY"))
+		}
+	case "RA_REPLY":
+		ctx.replies++
+		ctx.check(dp)
+	case "RA_EXIT":
+		ctx.exit(dp, "RA")
+		for _, v := range ctx.deferred {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("RA_REPLY"))
+		}
+		ctx.deferred = nil
+		if ctx.Pending > 0 {
+			ctx.request(dp)
+		}
+	}
+	return true
+}
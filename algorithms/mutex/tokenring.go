@@ -0,0 +1,77 @@
+package mutex
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// TokenRing is a context of the token ring algorithm. Token is set on the process holding the token,
+// initially on the process 0.
+type TokenRing struct {
+	Section
+	Token bool
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "TOKENRING",
+		Description: "token ring mutual exclusion on a unidirectional ring, TOKENRING_INIT requests the critical section",
+		Work:        TokenRingWorkFunction,
+		Contexts: map[string]context.Factory{
+			"TokenRing": func(node int32) context.Context { return &TokenRing{Section: newSection(), Token: node == 0} },
+		},
+	})
+}
+
+// pass sends the token to the successor.
+func (ctx *TokenRing) pass(dp *process.Process, idle int32) {
+	ctx.Token = false
+	dp.Network.SendMessage(dp.Node, successor(dp), messages.NewMessageByType("TOKENRING_TOKEN", idle))
+}
+
+// TokenRingWorkFunction handles TOKENRING_INIT, TOKENRING_TOKEN, TOKENRING_REQUEST and TOKENRING_EXIT messages.
+// The token circulates around the ring and only its holder may enter the critical section.
+// After a round without requests the token stops, so the model can finish. A process requesting
+// the critical section then sends a request around the ring, which makes the holder pass the token again.
+func TokenRingWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("TOKENRING"), s) {
+		return false
+	}
+	ctx := process.Ctx[*TokenRing](dp, "TokenRing")
+	switch string(s) {
+	case "TOKENRING_INIT":
+		ctx.Pending++
+		switch {
+		case ctx.InCS:
+		case ctx.Token:
+			ctx.enter(dp, "TOKENRING")
+		default:
+			dp.Network.SendMessage(dp.Node, successor(dp), messages.NewMessageByType("TOKENRING_REQUEST", dp.Node))
+		}
+	case "TOKENRING_TOKEN":
+		idle := m.GetInt32()
+		ctx.Token = true
+		switch {
+		case ctx.Pending > 0:
+			ctx.enter(dp, "TOKENRING")
+		case int(idle)+1 < len(dp.Network.Nodes()):
+			ctx.pass(dp, idle+1)
+		}
+	case "TOKENRING_REQUEST":
+		origin := m.GetInt32()
+		switch {
+		case ctx.Token && !ctx.InCS:
+			ctx.pass(dp, 0)
+		case !ctx.Token && origin != dp.Node:
+			dp.Network.SendMessage(dp.Node, successor(dp), messages.NewMessageByType("TOKENRING_REQUEST", origin))
+		}
+	case "TOKENRING_EXIT":
+		ctx.exit(dp, "TOKENRING")
+		ctx.pass(dp, 0)
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package routing
+
+import (
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// DV is a context of distance-vector routing. Costs of Infinity and more mean unreachable processes,
+// 16 by default, as in RIP. SplitHorizon omits routes from the vectors sent to their next hops,
+// PoisonedReverse advertises them with the infinite cost instead.
+type DV struct {
+	Table
+	Infinity        int64
+	SplitHorizon    bool
+	PoisonedReverse bool
+	vectors         map[int32]map[int32]int64
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "DV",
+		Description: "RIP-style distance-vector routing with split horizon and poisoned reverse options, DV_INIT starts it",
+		Work:        DVWorkFunction,
+		Contexts: map[string]context.Factory{
+			"DV": func(int32) context.Context {
+				return &DV{Table: newTable(), Infinity: 16, vectors: make(map[int32]map[int32]int64)}
+			},
+		},
+	})
+}
+
+// compute builds the routes by the distance vectors of the neighbours (Bellman–Ford),
+// ties are broken in favour of smaller next hops.
+func (ctx *DV) compute(dp *process.Process) map[int32]Route {
+	res := make(map[int32]Route)
+	consider := func(to int32, next int32, cost int64) {
+		if to == dp.Node || cost >= ctx.Infinity {
+			return
+		}
+		if r, ok := res[to]; ok && (r.Cost < cost || r.Cost == cost && r.Next < next) {
+			return
+		}
+		res[to] = Route{next, cost}
+	}
+	ls := links(dp)
+	for v := range ctx.vectors {
+		if _, ok := ls[v]; !ok {
+			delete(ctx.vectors, v)
+		}
+	}
+	for v, c := range ls {
+		consider(v, v, c)
+		for to, d := range ctx.vectors[v] {
+			consider(to, v, c+d)
+		}
+	}
+	return res
+}
+
+// advertise sends the distance vector of the process to its neighbours.
+func (ctx *DV) advertise(dp *process.Process) {
+	receivers := make([]int32, 0, len(ctx.Routes))
+	for to := range ctx.Routes {
+		receivers = append(receivers, to)
+	}
+	sort.Slice(receivers, func(i, j int) bool { return receivers[i] < receivers[j] })
+	for _, v := range dp.Neighbours() {
+		a := make([]int32, 0, 2*len(receivers))
+		for _, to := range receivers {
+			r := ctx.Routes[to]
+			switch {
+			case r.Next == v && ctx.PoisonedReverse:
+				a = append(a, to, int32(ctx.Infinity))
+			case r.Next == v && ctx.SplitHorizon:
+			default:
+				a = append(a, to, int32(r.Cost))
+			}
+		}
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("DV_VECTOR", a...))
+	}
+}
+
+// round rebuilds the routes, advertises them and schedules the next round.
+func (ctx *DV) round(dp *process.Process) {
+	ctx.update(dp, "DV", ctx.compute(dp))
+	ctx.advertise(dp)
+	ctx.schedule(dp, "DV")
+}
+
+// DVWorkFunction handles DV_INIT, DV_ROUND and DV_VECTOR messages. Every round a process rebuilds its routes
+// by the current costs of its links and the last distance vectors of its neighbours and sends its vector
+// to them, it also sends the vector as soon as its routes change. A process starts on the first message
+// of the protocol, so that the protocol survives lost DV_INIT messages. Without split horizon a removed link
+// makes the costs of the routes through it count to infinity.
+func DVWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("DV"), s) {
+		return false
+	}
+	ctx := process.Ctx[*DV](dp, "DV")
+	switch string(s) {
+	case "DV_INIT":
+		if ctx.start() {
+			ctx.round(dp)
+		}
+	case "DV_ROUND":
+		ctx.round(dp)
+	case "DV_VECTOR":
+		if dp.Network.GetLink(dp.Node, m.From) < 0 {
+			break
+		}
+		a := m.GetInt32s()
+		vector := make(map[int32]int64, len(a)/2)
+		for i := 0; i+1 < len(a); i += 2 {
+			vector[a[i]] = int64(a[i+1])
+		}
+		ctx.vectors[m.From] = vector
+		if ctx.start() {
+			ctx.round(dp)
+		} else if ctx.update(dp, "DV", ctx.compute(dp)) {
+			ctx.advertise(dp)
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package routing
+
+import (
+	"reflect"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/pkg/graph"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// LS is a context of link-state routing. Seq is the sequence number of the last link-state advertisement
+// of the process, Refresh is the number of rounds it is flooded again after, 0 for flooding on changes only.
+type LS struct {
+	Table
+	Seq     int32
+	Refresh int
+	rounds  int
+	lsdb    map[int32]lsa
+}
+
+// lsa is a link-state advertisement: the costs of the links of its origin.
+type lsa struct {
+	seq   int32
+	links map[int32]int64
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "LS",
+		Description: "OSPF-style link-state routing by flooding link-state advertisements, LS_INIT starts it",
+		Work:        LSWorkFunction,
+		Contexts: map[string]context.Factory{
+			"LS": func(int32) context.Context { return &LS{Table: newTable(), lsdb: make(map[int32]lsa)} },
+		},
+	})
+}
+
+// originate floods a new advertisement of the links of the process.
+func (ctx *LS) originate(dp *process.Process, ls map[int32]int64) {
+	ctx.Seq++
+	ctx.rounds = 0
+	ctx.lsdb[dp.Node] = lsa{ctx.Seq, ls}
+	a := []int32{dp.Node, ctx.Seq}
+	for _, v := range dp.Neighbours() {
+		if c, ok := ls[v]; ok {
+			a = append(a, v, int32(c))
+		}
+	}
+	m := messages.NewMessageByType("LS_LSA", a...)
+	for _, v := range dp.Neighbours() {
+		dp.Network.SendMessage(dp.Node, v, m)
+	}
+}
+
+// compute builds the routes by the shortest paths over the links of the database (Dijkstra).
+func (ctx *LS) compute(dp *process.Process) map[int32]Route {
+	g := graph.New()
+	for origin, a := range ctx.lsdb {
+		for v, c := range a.links {
+			g.AddEdge(origin, v, int32(c))
+		}
+	}
+	dist, prev := g.ShortestPaths(dp.Node)
+	res := make(map[int32]Route)
+	for to, d := range dist {
+		if to == dp.Node {
+			continue
+		}
+		next := to
+		for prev[next] != dp.Node {
+			next = prev[next]
+		}
+		res[to] = Route{next, d}
+	}
+	return res
+}
+
+// LSWorkFunction handles LS_INIT, LS_ROUND and LS_LSA messages. A process floods an advertisement of its links
+// when it starts and every round its links have changed at, the processes relay every advertisement newer than
+// the one of its origin they have and build the routes by the shortest paths over the advertised links.
+// A process starts on the first message of the protocol, so that the protocol survives lost LS_INIT messages.
+func LSWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("LS"), s) {
+		return false
+	}
+	ctx := process.Ctx[*LS](dp, "LS")
+	switch string(s) {
+	case "LS_INIT":
+		if !ctx.start() {
+			break
+		}
+		ctx.originate(dp, links(dp))
+		ctx.update(dp, "LS", ctx.compute(dp))
+		ctx.schedule(dp, "LS")
+	case "LS_ROUND":
+		ctx.rounds++
+		if ls := links(dp); !reflect.DeepEqual(ls, ctx.lsdb[dp.Node].links) || ctx.Refresh > 0 && ctx.rounds >= ctx.Refresh {
+			ctx.originate(dp, ls)
+			ctx.update(dp, "LS", ctx.compute(dp))
+		}
+		ctx.schedule(dp, "LS")
+	case "LS_LSA":
+		a := m.GetInt32s()
+		origin, seq := a[0], a[1]
+		if old, ok := ctx.lsdb[origin]; ok && old.seq >= seq || origin == dp.Node {
+			break
+		}
+		ls := make(map[int32]int64, (len(a)-2)/2)
+		for i := 2; i+1 < len(a); i += 2 {
+			ls[a[i]] = int64(a[i+1])
+		}
+		ctx.lsdb[origin] = lsa{seq, ls}
+		for _, v := range dp.Neighbours() {
+			if v != m.From {
+				dp.Network.SendBytes(dp.Node, v, m.Body)
+			}
+		}
+		if ctx.start() {
+			ctx.originate(dp, links(dp))
+			ctx.schedule(dp, "LS")
+		}
+		ctx.update(dp, "LS", ctx.compute(dp))
+	}
+	return true
+}
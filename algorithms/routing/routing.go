@@ -0,0 +1,154 @@
+// Package routing implements routing protocols: RIP-style distance-vector and OSPF-style link-state routing.
+//
+// A process starts on <NAME>_INIT and then checks the links to its neighbours every round, as a hello protocol
+// would, so the protocols follow links created, changed and removed during the run. Costs are link latencies.
+// Routing tables are kept in the Routes field of the contexts, which implement network.Table, so the "tables"
+// router forwards messages by them. Changes of the tables are reported to the network tracer as route events,
+// so that they can be compared with the shortest paths by World.CheckRouting.
+package routing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/pkg/graph"
+)
+
+// Route is an entry of a routing table: the next hop to the receiver and the cost of the path.
+type Route struct {
+	Next int32
+	Cost int64
+}
+
+// Table is the routing table of a process, it is embedded into contexts of all the protocols.
+// Period is the length of a round in ticks, twice the greatest latency of the links of the process by default.
+// Changed is the tick the table has changed at for the last time.
+type Table struct {
+	Period  int64
+	Routes  map[int32]Route
+	Changed int64
+	started bool
+}
+
+func newTable() Table {
+	return Table{Routes: make(map[int32]Route)}
+}
+
+// Result returns the routing table of the process.
+func (t *Table) Result() *Table {
+	return t
+}
+
+// Outcome is implemented by contexts of all the routing protocols.
+type Outcome interface {
+	Result() *Table
+}
+
+// NextHop implements network.Table.
+func (t *Table) NextHop(to int32) (int32, bool) {
+	r, ok := t.Routes[to]
+	return r.Next, ok
+}
+
+// update replaces the routes of the table, reports the changes and returns whether there are any.
+func (t *Table) update(dp *process.Process, name string, routes map[int32]Route) bool {
+	receivers := make([]int32, 0, len(routes)+len(t.Routes))
+	for to := range routes {
+		receivers = append(receivers, to)
+	}
+	for to := range t.Routes {
+		if _, ok := routes[to]; !ok {
+			receivers = append(receivers, to)
+		}
+	}
+	sort.Slice(receivers, func(i, j int) bool { return receivers[i] < receivers[j] })
+	changed := false
+	for _, to := range receivers {
+		r, ok := routes[to]
+		old, had := t.Routes[to]
+		if ok == had && r == old {
+			continue
+		}
+		changed = true
+		if !ok {
+			r = Route{-1, -1}
+		}
+		logging.Debugf("[%v]: route to %v via %v with cost %v", dp.Node, to, r.Next, r.Cost)
+		dp.Network.Record(trace.Event{Tick: dp.Network.Tick, Kind: trace.Route, From: dp.Node, To: dp.Node,
+			Message: fmt.Sprintf("%v %v %v %v", name, to, r.Next, r.Cost)})
+	}
+	if changed {
+		t.Routes = routes
+		t.Changed = dp.Network.Tick
+	}
+	return changed
+}
+
+// start reports whether the process has not started yet and marks it started.
+func (t *Table) start() bool {
+	if t.started {
+		return false
+	}
+	t.started = true
+	return true
+}
+
+// schedule schedules the next round of the process.
+func (t *Table) schedule(dp *process.Process, name string) {
+	period := t.Period
+	if period <= 0 {
+		period = 1
+		for _, c := range links(dp) {
+			if 2*c > period {
+				period = 2 * c
+			}
+		}
+	}
+	dp.Network.SendTimeout(dp.Node, period, messages.NewMessageByType(name+"_ROUND"))
+}
+
+// links returns the costs of the links from the process to its neighbours.
+func links(dp *process.Process) map[int32]int64 {
+	res := make(map[int32]int64)
+	for _, v := range dp.Neighbours() {
+		res[v] = int64(dp.Network.GetLink(dp.Node, v))
+	}
+	return res
+}
+
+// Verify checks that the routing tables of all the processes with the context key have the costs
+// of the shortest paths over the links to all the other processes and returns the tick
+// the tables have changed at for the last time.
+func Verify(ps []*process.Process, key string, links graph.Graph) (int64, error) {
+	var res int64
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return 0, fmt.Errorf("process %v has no routing context %v", dp.Node, key)
+		}
+		t := o.Result()
+		if t.Changed > res {
+			res = t.Changed
+		}
+		dist, _ := links.ShortestPaths(dp.Node)
+		for _, other := range ps {
+			if other == nil || other.Node == dp.Node {
+				continue
+			}
+			want, reachable := dist[other.Node]
+			r, ok := t.Routes[other.Node]
+			if ok != reachable || ok && r.Cost != want {
+				return 0, fmt.Errorf("process %v routes to %v via %v with cost %v (%v), the shortest path costs %v (%v)",
+					dp.Node, other.Node, r.Next, r.Cost, ok, want, reachable)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,172 @@
+package routing
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/routing"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+	}{
+		{"DV", "../../configs/routing/dv.data", "DV"},
+		{"LS", "../../configs/routing/ls.data", "LS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *routing.Checker
+			w := sample.Run(t, tt.config, 1000, true, func(w *world.World) { c = w.CheckRouting() })
+			got, err := Verify(w.ProcessesList, tt.key, w.Network.Links())
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if changed := w.Network.LinksChanged(); got <= changed {
+				t.Errorf("Verify() = %v, want after the link change at %v", got, changed)
+			}
+			if got != c.LastChange() {
+				t.Errorf("Checker.LastChange() = %v, want %v", c.LastChange(), got)
+			}
+			if m := c.Mismatches(w.Network.Links(), w.Nodes()); len(m) > 0 {
+				t.Errorf("Checker.Mismatches() = %v", m)
+			}
+		})
+	}
+}
+
+// run builds routes on a random connected graph with the message loss rate, then changes the latency
+// of a random link and removes another one, and checks that the routes follow the shortest paths.
+func run(t *testing.T, function string, key string, fields map[string]string, n int32, loss float64, rng *rand.Rand) {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 100000})
+	defer w.Stop()
+	c := w.CheckRouting()
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte(function))
+		if err := w.ProcessesList[i].SetContextFields(key, fields); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := int32(1); i < n; i++ {
+		w.Network.CreateLink(i, rng.Int31n(i), true, 1+rng.Int31n(3))
+		if j := rng.Int31n(n); j != i {
+			w.Network.CreateLink(i, j, true, 1+rng.Int31n(3))
+		}
+	}
+	w.Network.SetErrorRate(loss)
+	w.Network.SendMessage(-1, -1, messages.NewMessageByType(function+"_INIT"))
+	w.Wait(200)
+	if n > 1 {
+		from := 1 + rng.Int31n(n-1)
+		to := w.Network.Neighbours(from)[0]
+		w.Network.CreateLink(from, to, true, 1+rng.Int31n(5))
+		from = rng.Int31n(n)
+		to = w.Network.Neighbours(from)[0]
+		w.Network.RemoveLink(from, to, true)
+	}
+	w.Wait(1000)
+
+	if _, err := Verify(w.ProcessesList, key, w.Network.Links()); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if m := c.Mismatches(w.Network.Links(), w.Nodes()); len(m) > 0 {
+		t.Errorf("Checker.Mismatches() = %v", m)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+		fields   map[string]string
+		loss     float64
+	}{
+		{"DV", "DV", map[string]string{"Infinity": "64"}, 0.1},
+		{"DV", "DV", map[string]string{"SplitHorizon": "true"}, 0.1},
+		{"DV", "DV", map[string]string{"PoisonedReverse": "true"}, 0.1},
+		{"LS", "LS", map[string]string{}, 0},
+		{"LS", "LS", map[string]string{"Refresh": "3"}, 0.1},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 3, 5, 8} {
+			for i := 0; i < 5; i++ {
+				t.Run(fmt.Sprintf("%v-%v%v/%v", tt.function, tt.fields, n, i), func(t *testing.T) {
+					run(t, tt.function, tt.key, tt.fields, n, tt.loss, rng)
+				})
+			}
+		}
+	}
+}
+
+// TestCountToInfinity checks that split horizon and poisoned reverse speed up the convergence
+// after the removal of the link to the end of a line.
+func TestCountToInfinity(t *testing.T) {
+	converge := func(fields map[string]string) int64 {
+		w := world.NewWithOptions(world.Options{Seed: 1, MaxTicks: 1000})
+		defer w.Stop()
+		c := w.CheckRouting()
+		for i := int32(0); i < 5; i++ {
+			w.CreateProcess(i)
+			w.AssignWorkFunction(i, []byte("DV"))
+			if err := w.ProcessesList[i].SetContextFields("DV", fields); err != nil {
+				t.Fatal(err)
+			}
+			if i > 0 {
+				w.Network.CreateLink(i-1, i, true, 1)
+			}
+		}
+		w.Network.SendMessage(-1, -1, messages.NewMessageByType("DV_INIT"))
+		w.Wait(50)
+		w.Network.RemoveLink(3, 4, true)
+		w.Wait(500)
+		if m := c.Mismatches(w.Network.Links(), w.Nodes()); len(m) > 0 {
+			t.Errorf("Checker.Mismatches() = %v", m)
+		}
+		return c.LastChange() - w.Network.LinksChanged()
+	}
+	plain := converge(map[string]string{"Period": "5", "Infinity": "32"})
+	for _, f := range []string{"SplitHorizon", "PoisonedReverse"} {
+		if got := converge(map[string]string{"Period": "5", "Infinity": "32", f: "true"}); got >= plain {
+			t.Errorf("%v converges in %v ticks, want less than %v", f, got, plain)
+		}
+	}
+}
+
+// TestTables checks that the "tables" router delivers messages by the routing tables.
+func TestTables(t *testing.T) {
+	w := world.NewWithOptions(world.Options{Seed: 1, MaxTicks: 1000})
+	defer w.Stop()
+	r := trace.NewRecorder()
+	w.AddTracer(r)
+	if !w.ParseConfig([]byte("../../configs/routing/ls.data")) {
+		t.Fatalf("World.ParseConfig() = false")
+	}
+	w.Network.SendMessage(2, 6, messages.NewMessageByType("PING"))
+	w.Wait(50)
+	hops := 0
+	for _, e := range r.Events() {
+		if e.Kind == trace.Forward && e.Message == "PING" {
+			hops++
+		}
+		if e.Kind == trace.Deliver && e.Message == "PING" && e.To == 6 {
+			if hops == 0 {
+				t.Errorf("PING delivered without forwarding")
+			}
+			return
+		}
+	}
+	t.Errorf("PING not delivered, %v forwards", hops)
+}
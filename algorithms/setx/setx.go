@@ -0,0 +1,51 @@
+// Package setx implements the algorithm setting one value on all the processes.
+package setx
+
+import (
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// SetX is a context for setting one value algorithm.
+type SetX struct {
+	X int
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "SETX",
+		Description: "floods the value given by SETX_INIT to all the processes",
+		Work:        WorkFunction,
+		Contexts: map[string]context.Factory{
+			"SetX": func(int32) context.Context { return &SetX{} },
+		},
+	})
+}
+
+// WorkFunction handles SETX_INIT and SETX_SET messages.
+func WorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	nl := dp.Network
+	if !dp.IsMyMessage([]byte("SETX"), s) {
+		return false
+	}
+	if string(s) == "SETX_INIT" {
+		arg := m.GetInt32()
+		nl.SendMessage(dp.Node, dp.Node, messages.NewMessageByArgs(messages.NewMessageArg([]byte("SETX_SET")), messages.NewMessageArg(arg)))
+	} else if string(s) == "SETX_SET" {
+		arg := m.GetInt32()
+		logging.Infof("[%v]: SETX_SET received, arg=%v", dp.Node, arg)
+		ctx := process.Ctx[*SetX](dp, "SetX")
+		if ctx.X != int(arg) {
+			for _, v := range dp.Neighbours() {
+				nl.SendMessage(dp.Node, v, messages.NewMessageByArgs(messages.NewMessageArg([]byte("SETX_SET")), messages.NewMessageArg(arg)))
+			}
+		}
+		ctx.X = int(arg)
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package spantree
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// BFS is a context of the asynchronous breadth-first search.
+// Depth is the number of hops from the root, None if the process is not reached yet.
+type BFS struct {
+	Tree
+	Depth int32
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "BFS",
+		Description: "asynchronous breadth-first search tree construction, the root is started by BFS_INIT",
+		Work:        BFSWorkFunction,
+		Contexts: map[string]context.Factory{
+			"BFS": func(int32) context.Context { return &BFS{Tree: newTree(), Depth: None} },
+		},
+	})
+}
+
+// announce sends the depth of the process to all the neighbours.
+func (ctx *BFS) announce(dp *process.Process) {
+	for _, v := range dp.Others() {
+		if v != ctx.Parent {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("BFS_DEPTH", ctx.Depth))
+		}
+	}
+}
+
+// BFSWorkFunction handles BFS_INIT and BFS_DEPTH messages.
+// A process getting a shorter path to the root takes the sender as its parent
+// and tells the neighbours its new depth. When no messages are left, the depths are exact.
+func BFSWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("BFS"), s) {
+		return false
+	}
+	ctx := process.Ctx[*BFS](dp, "BFS")
+	switch string(s) {
+	case "BFS_INIT":
+		if ctx.Joined() {
+			break
+		}
+		ctx.Root, ctx.Depth = true, 0
+		ctx.announce(dp)
+	case "BFS_DEPTH":
+		d := m.GetInt32() + 1
+		if ctx.Depth == None || d < ctx.Depth {
+			ctx.Parent, ctx.Depth = m.From, d
+			ctx.announce(dp)
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package spantree
+
+import (
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Echo is a context of the echo algorithm.
+// Done is set on the root when the echo has returned from the whole tree.
+type Echo struct {
+	Tree
+	Children []int32
+	Done     bool
+	received int
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "ECHO",
+		Description: "echo (flooding) spanning tree construction, the root is started by ECHO_INIT",
+		Work:        EchoWorkFunction,
+		Contexts: map[string]context.Factory{
+			"Echo": func(int32) context.Context { return &Echo{Tree: newTree()} },
+		},
+	})
+}
+
+// EchoWorkFunction handles ECHO_INIT, ECHO_WAVE and ECHO_BACK messages.
+// The root floods the wave, every process takes the sender of the first wave as its parent
+// and passes the wave on to the other neighbours. After hearing from all the neighbours
+// a process sends the echo back to its parent.
+func EchoWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("ECHO"), s) {
+		return false
+	}
+	ctx := process.Ctx[*Echo](dp, "Echo")
+	ns := dp.Others()
+	switch string(s) {
+	case "ECHO_INIT":
+		if ctx.Joined() {
+			break
+		}
+		ctx.Root = true
+		for _, v := range ns {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("ECHO_WAVE"))
+		}
+	case "ECHO_WAVE", "ECHO_BACK":
+		ctx.received++
+		if string(s) == "ECHO_BACK" {
+			ctx.Children = append(ctx.Children, m.From)
+		}
+		if !ctx.Joined() {
+			ctx.Parent = m.From
+			for _, v := range ns {
+				if v != m.From {
+					dp.Network.SendMessage(dp.Node, v, messages.NewMessageByType("ECHO_WAVE"))
+				}
+			}
+		}
+	default:
+		return true
+	}
+	if ctx.received == len(ns) && !ctx.Done {
+		ctx.Done = true
+		if ctx.Root {
+			logging.Infof("[%v]: echo returned", dp.Node)
+		} else {
+			dp.Network.SendMessage(dp.Node, ctx.Parent, messages.NewMessageByType("ECHO_BACK"))
+		}
+	}
+	return true
+}
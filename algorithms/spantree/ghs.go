@@ -0,0 +1,296 @@
+package spantree
+
+import (
+	"math"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// States of GHS processes.
+const (
+	sleeping = iota
+	find
+	found
+)
+
+// States of GHS edges.
+const (
+	basic = iota
+	branch
+	rejected
+)
+
+// weight is a link latency made unique by the link ends.
+type weight struct {
+	cost, lo, hi int32
+}
+
+var infinity = weight{math.MaxInt32, math.MaxInt32, math.MaxInt32}
+
+func (w weight) less(u weight) bool {
+	if w.cost != u.cost {
+		return w.cost < u.cost
+	}
+	if w.lo != u.lo {
+		return w.lo < u.lo
+	}
+	return w.hi < u.hi
+}
+
+// ghsMessage is a decoded GHS message.
+type ghsMessage struct {
+	kind string
+	from int32
+	args []int32
+}
+
+// arity is the number of integer arguments of GHS messages.
+var arity = map[string]int{
+	"GHS_CONNECT":    1,
+	"GHS_INITIATE":   5,
+	"GHS_TEST":       4,
+	"GHS_ACCEPT":     0,
+	"GHS_REJECT":     0,
+	"GHS_REPORT":     3,
+	"GHS_CHANGEROOT": 0,
+}
+
+// GHS is a context of the Gallager–Humblet–Spira algorithm.
+// Level is the level of the fragment containing the process, Halted is set on the two core processes at the end.
+type GHS struct {
+	Tree
+	Level      int32
+	Halted     bool
+	state      int
+	edges      map[int32]int
+	fragment   weight
+	bestEdge   int32
+	bestWeight weight
+	testEdge   int32
+	inBranch   int32
+	findCount  int
+	deferred   []*ghsMessage
+}
+
+func init() {
+	registry.Register(registry.Algorithm{
+		Name:        "GHS",
+		Description: "Gallager–Humblet–Spira minimum spanning tree with link latencies as weights, any processes are woken up by GHS_INIT",
+		Work:        GHSWorkFunction,
+		Contexts: map[string]context.Factory{
+			"GHS": func(int32) context.Context {
+				return &GHS{Tree: newTree(), edges: make(map[int32]int), bestEdge: None, testEdge: None, inBranch: None}
+			},
+		},
+	})
+}
+
+func (ctx *GHS) weight(dp *process.Process, j int32) weight {
+	lo, hi := dp.Node, j
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return weight{dp.Network.GetLink(dp.Node, j), lo, hi}
+}
+
+func (ctx *GHS) send(dp *process.Process, to int32, kind string, args ...int32) {
+	dp.Network.SendMessage(dp.Node, to, messages.NewMessageByType(kind, args...))
+}
+
+func (ctx *GHS) wakeup(dp *process.Process) {
+	ctx.state = found
+	ctx.Level = 0
+	ctx.findCount = 0
+	var m int32 = None
+	for _, j := range dp.Others() {
+		if m == None || ctx.weight(dp, j).less(ctx.weight(dp, m)) {
+			m = j
+		}
+	}
+	if m == None {
+		ctx.Root, ctx.Halted = true, true
+		return
+	}
+	ctx.edges[m] = branch
+	ctx.send(dp, m, "GHS_CONNECT", 0)
+}
+
+func (ctx *GHS) test(dp *process.Process) {
+	ctx.testEdge = None
+	for _, j := range dp.Others() {
+		if ctx.edges[j] == basic && (ctx.testEdge == None || ctx.weight(dp, j).less(ctx.weight(dp, ctx.testEdge))) {
+			ctx.testEdge = j
+		}
+	}
+	if ctx.testEdge == None {
+		ctx.report(dp)
+		return
+	}
+	f := ctx.fragment
+	ctx.send(dp, ctx.testEdge, "GHS_TEST", ctx.Level, f.cost, f.lo, f.hi)
+}
+
+func (ctx *GHS) report(dp *process.Process) {
+	if ctx.findCount == 0 && ctx.testEdge == None {
+		ctx.state = found
+		w := ctx.bestWeight
+		ctx.send(dp, ctx.inBranch, "GHS_REPORT", w.cost, w.lo, w.hi)
+	}
+}
+
+func (ctx *GHS) changeRoot(dp *process.Process) {
+	if ctx.edges[ctx.bestEdge] == branch {
+		ctx.send(dp, ctx.bestEdge, "GHS_CHANGEROOT")
+		return
+	}
+	ctx.send(dp, ctx.bestEdge, "GHS_CONNECT", ctx.Level)
+	ctx.edges[ctx.bestEdge] = branch
+}
+
+// handle performs the message, it returns false if the message should be deferred.
+func (ctx *GHS) handle(dp *process.Process, m *ghsMessage) bool {
+	j := m.from
+	switch m.kind {
+	case "GHS_CONNECT":
+		if ctx.state == sleeping {
+			ctx.wakeup(dp)
+		}
+		l := m.args[0]
+		switch {
+		case l < ctx.Level:
+			ctx.edges[j] = branch
+			f := ctx.fragment
+			ctx.send(dp, j, "GHS_INITIATE", ctx.Level, f.cost, f.lo, f.hi, int32(ctx.state))
+			if ctx.state == find {
+				ctx.findCount++
+			}
+		case ctx.edges[j] == basic:
+			return false
+		default:
+			w := ctx.weight(dp, j)
+			ctx.send(dp, j, "GHS_INITIATE", ctx.Level+1, w.cost, w.lo, w.hi, find)
+		}
+	case "GHS_INITIATE":
+		ctx.Level = m.args[0]
+		ctx.fragment = weight{m.args[1], m.args[2], m.args[3]}
+		ctx.state = int(m.args[4])
+		ctx.inBranch, ctx.Parent = j, j
+		ctx.bestEdge, ctx.bestWeight = None, infinity
+		for _, i := range dp.Others() {
+			if i != j && ctx.edges[i] == branch {
+				ctx.send(dp, i, "GHS_INITIATE", m.args...)
+				if ctx.state == find {
+					ctx.findCount++
+				}
+			}
+		}
+		if ctx.state == find {
+			ctx.test(dp)
+		}
+	case "GHS_TEST":
+		if ctx.state == sleeping {
+			ctx.wakeup(dp)
+		}
+		l, f := m.args[0], weight{m.args[1], m.args[2], m.args[3]}
+		switch {
+		case l > ctx.Level:
+			return false
+		case f != ctx.fragment:
+			ctx.send(dp, j, "GHS_ACCEPT")
+		default:
+			if ctx.edges[j] == basic {
+				ctx.edges[j] = rejected
+			}
+			if ctx.testEdge != j {
+				ctx.send(dp, j, "GHS_REJECT")
+			} else {
+				ctx.test(dp)
+			}
+		}
+	case "GHS_ACCEPT":
+		ctx.testEdge = None
+		if w := ctx.weight(dp, j); w.less(ctx.bestWeight) {
+			ctx.bestEdge, ctx.bestWeight = j, w
+		}
+		ctx.report(dp)
+	case "GHS_REJECT":
+		if ctx.edges[j] == basic {
+			ctx.edges[j] = rejected
+		}
+		ctx.test(dp)
+	case "GHS_REPORT":
+		w := weight{m.args[0], m.args[1], m.args[2]}
+		switch {
+		case j != ctx.inBranch:
+			ctx.findCount--
+			if w.less(ctx.bestWeight) {
+				ctx.bestEdge, ctx.bestWeight = j, w
+			}
+			ctx.report(dp)
+		case ctx.state == find:
+			return false
+		case ctx.bestWeight.less(w):
+			ctx.changeRoot(dp)
+		case w == infinity && ctx.bestWeight == infinity:
+			ctx.halt(dp)
+		}
+	case "GHS_CHANGEROOT":
+		ctx.changeRoot(dp)
+	}
+	return true
+}
+
+// halt finishes the algorithm on a core process. The core process with the lower node number becomes the root.
+func (ctx *GHS) halt(dp *process.Process) {
+	ctx.Halted = true
+	if dp.Node < ctx.inBranch {
+		ctx.Root, ctx.Parent = true, None
+		logging.Infof("[%v]: minimum spanning tree is built", dp.Node)
+	}
+}
+
+// GHSWorkFunction handles GHS_INIT, GHS_CONNECT, GHS_INITIATE, GHS_TEST, GHS_ACCEPT, GHS_REJECT,
+// GHS_REPORT and GHS_CHANGEROOT messages.
+// Fragments of the minimum spanning tree join along their minimum outgoing edges, as described by
+// Gallager, Humblet and Spira. Messages which can not be handled yet are deferred until the state changes.
+func GHSWorkFunction(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	s := m.GetString()
+	if !dp.IsMyMessage([]byte("GHS"), s) {
+		return false
+	}
+	ctx := process.Ctx[*GHS](dp, "GHS")
+	if string(s) == "GHS_INIT" {
+		if ctx.state == sleeping {
+			ctx.wakeup(dp)
+		}
+		return true
+	}
+	n, ok := arity[string(s)]
+	if !ok {
+		return true
+	}
+	msg := &ghsMessage{kind: string(s), from: m.From, args: make([]int32, n)}
+	for i := range msg.args {
+		msg.args[i] = m.GetInt32()
+	}
+	if !ctx.handle(dp, msg) {
+		ctx.deferred = append(ctx.deferred, msg)
+		return true
+	}
+	for progress := true; progress; {
+		progress = false
+		for i, d := range ctx.deferred {
+			if ctx.handle(dp, d) {
+				ctx.deferred = append(ctx.deferred[:i], ctx.deferred[i+1:]...)
+				progress = true
+				break
+			}
+		}
+	}
+	return true
+}
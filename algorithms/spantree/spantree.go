@@ -0,0 +1,151 @@
+// Package spantree implements spanning tree construction algorithms on bidirected connected graphs:
+// echo (flooding), asynchronous breadth-first search and the minimum spanning tree algorithm
+// of Gallager, Humblet and Spira.
+//
+// The resulting parent pointers are kept in the contexts of the processes
+// and can be checked against the network graph by Verify, VerifyBFS and VerifyMST.
+package spantree
+
+import (
+	"fmt"
+
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/pkg/graph"
+)
+
+// None marks the absent parent.
+const None = -1
+
+// Tree is a part of the spanning tree kept by a process, it is embedded into contexts of all the algorithms.
+type Tree struct {
+	Parent int32
+	Root   bool
+}
+
+func newTree() Tree {
+	return Tree{Parent: None}
+}
+
+// Node returns the part of the spanning tree kept by the process.
+func (t *Tree) Node() *Tree {
+	return t
+}
+
+// Joined reports whether the process has joined the tree.
+func (t *Tree) Joined() bool {
+	return t.Root || t.Parent != None
+}
+
+// Outcome is implemented by contexts of all the spanning tree algorithms.
+type Outcome interface {
+	Node() *Tree
+}
+
+// parents collects the parent pointers of the processes by the context key.
+// The root has itself as a parent.
+func parents(ps []*process.Process, key string) (map[int32]int32, int32, error) {
+	res := make(map[int32]int32)
+	var root int32 = None
+	for _, dp := range ps {
+		if dp == nil {
+			continue
+		}
+		o, ok := process.LookupCtx[Outcome](dp, key)
+		if !ok {
+			return nil, None, fmt.Errorf("process %v has no spanning tree context %v", dp.Node, key)
+		}
+		t := o.Node()
+		switch {
+		case t.Root && root != None:
+			return nil, None, fmt.Errorf("processes %v and %v are both roots", root, dp.Node)
+		case t.Root:
+			root = dp.Node
+			res[dp.Node] = dp.Node
+		case t.Parent == None:
+			return nil, None, fmt.Errorf("process %v has not joined the tree", dp.Node)
+		default:
+			res[dp.Node] = t.Parent
+		}
+	}
+	if root == None {
+		return nil, None, fmt.Errorf("no root")
+	}
+	return res, root, nil
+}
+
+// depth returns the number of edges from the process to the root or an error if there is a cycle.
+func depth(parent map[int32]int32, v int32) (int, error) {
+	res := 0
+	for parent[v] != v {
+		v = parent[v]
+		res++
+		if res > len(parent) {
+			return 0, fmt.Errorf("parent pointers of process %v form a cycle", v)
+		}
+	}
+	return res, nil
+}
+
+// Verify checks that the parent pointers of the processes with the context key
+// form a spanning tree of the graph, usually obtained by Network.Links.
+// It returns the root of the tree.
+func Verify(ps []*process.Process, g graph.Graph, key string) (int32, error) {
+	parent, root, err := parents(ps, key)
+	if err != nil {
+		return None, err
+	}
+	for v, p := range parent {
+		if v == root {
+			continue
+		}
+		if _, ok := parent[p]; !ok {
+			return None, fmt.Errorf("parent %v of process %v is not a process", p, v)
+		}
+		if !g.HasEdge(p, v) || !g.HasEdge(v, p) {
+			return None, fmt.Errorf("no link between process %v and its parent %v", v, p)
+		}
+		if _, err := depth(parent, v); err != nil {
+			return None, err
+		}
+	}
+	return root, nil
+}
+
+// VerifyBFS checks that the parent pointers form a breadth-first search tree of the graph:
+// the depth of every process is the number of hops from the root.
+func VerifyBFS(ps []*process.Process, g graph.Graph, key string) (int32, error) {
+	root, err := Verify(ps, g, key)
+	if err != nil {
+		return None, err
+	}
+	parent, _, _ := parents(ps, key)
+	hops := g.Hops(root)
+	for v := range parent {
+		if d, _ := depth(parent, v); d != hops[v] {
+			return None, fmt.Errorf("process %v has depth %v, but it is %v hops from the root", v, d, hops[v])
+		}
+	}
+	return root, nil
+}
+
+// VerifyMST checks that the parent pointers form a minimum spanning tree of the graph,
+// where link latencies are the weights.
+func VerifyMST(ps []*process.Process, g graph.Graph, key string) (int32, error) {
+	root, err := Verify(ps, g, key)
+	if err != nil {
+		return None, err
+	}
+	parent, _, _ := parents(ps, key)
+	var weight int64
+	vertices := make([]int32, 0, len(parent))
+	for v, p := range parent {
+		vertices = append(vertices, v)
+		if v != root {
+			weight += int64(g[v][p])
+		}
+	}
+	if min, _ := g.MinimumSpanningTree(vertices); weight != min {
+		return None, fmt.Errorf("tree weight is %v, the minimum is %v", weight, min)
+	}
+	return root, nil
+}
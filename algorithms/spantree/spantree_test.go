@@ -0,0 +1,128 @@
+package spantree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/sample"
+	"github.com/trmigor/distr-model/internal/world"
+	"github.com/trmigor/distr-model/pkg/graph"
+)
+
+func TestMain(m *testing.M) {
+	sample.Main(m)
+}
+
+type verifier func(ps []*process.Process, g graph.Graph, key string) (int32, error)
+
+func TestSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+		verify verifier
+	}{
+		{"Echo", "../../configs/spantree/echo.data", "Echo", Verify},
+		{"BFS", "../../configs/spantree/bfs.data", "BFS", VerifyBFS},
+		{"GHS", "../../configs/spantree/ghs.data", "GHS", VerifyMST},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sample.Run(t, tt.config, 10000, false, nil)
+			if _, err := tt.verify(w.ProcessesList, w.Network.Links(), tt.key); err != nil {
+				t.Errorf("verify() error = %v", err)
+			}
+		})
+	}
+}
+
+// random creates a random connected graph of n processes.
+func random(n int32, function string, rng *rand.Rand) *world.World {
+	w := world.NewWithOptions(world.Options{Seed: rng.Int63(), MaxTicks: 100000})
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+	}
+	for i := int32(0); i < n; i++ {
+		if i > 0 {
+			w.Network.CreateLink(i, rng.Int31n(i), true, 1+rng.Int31n(9))
+		}
+		if j := rng.Int31n(n); j != i && rng.Intn(2) == 0 {
+			w.Network.CreateLink(i, j, true, 1+rng.Int31n(9))
+		}
+		w.AssignWorkFunction(i, []byte(function))
+	}
+	return w
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		function string
+		key      string
+		verify   verifier
+	}{
+		{"ECHO", "Echo", Verify},
+		{"BFS", "BFS", VerifyBFS},
+		{"GHS", "GHS", VerifyMST},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, n := range []int32{1, 2, 5, 12, 30} {
+			t.Run(fmt.Sprintf("%v%v", tt.function, n), func(t *testing.T) {
+				w := random(n, tt.function, rng)
+				defer w.Stop()
+				w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType(tt.function+"_INIT"))
+				if tt.function == "GHS" {
+					w.Network.SendMessage(-1, rng.Int31n(n), messages.NewMessageByType("GHS_INIT"))
+				}
+				if !w.Run() {
+					t.Fatalf("World.Run() = false")
+				}
+				if _, err := tt.verify(w.ProcessesList, w.Network.Links(), tt.key); err != nil {
+					t.Errorf("verify() error = %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	g := graph.New()
+	for _, e := range [][3]int32{{0, 1, 1}, {1, 2, 1}, {2, 3, 1}, {3, 0, 1}, {0, 2, 5}} {
+		g.AddEdge(e[0], e[1], e[2])
+		g.AddEdge(e[1], e[0], e[2])
+	}
+	tests := []struct {
+		name    string
+		trees   []Tree
+		verify  verifier
+		wantErr bool
+	}{
+		{"Valid", []Tree{{None, true}, {0, false}, {1, false}, {0, false}}, Verify, false},
+		{"BFS", []Tree{{None, true}, {0, false}, {0, false}, {0, false}}, VerifyBFS, false},
+		{"MST", []Tree{{None, true}, {0, false}, {1, false}, {0, false}}, VerifyMST, false},
+		{"NotBFS", []Tree{{None, true}, {0, false}, {1, false}, {0, false}}, VerifyBFS, true},
+		{"NotMST", []Tree{{None, true}, {0, false}, {0, false}, {0, false}}, VerifyMST, true},
+		{"NoLink", []Tree{{None, true}, {0, false}, {1, false}, {1, false}}, Verify, true},
+		{"NoRoot", []Tree{{1, false}, {0, false}, {1, false}, {0, false}}, Verify, true},
+		{"TwoRoots", []Tree{{None, true}, {None, true}, {1, false}, {0, false}}, Verify, true},
+		{"NotJoined", []Tree{{None, true}, {0, false}, {None, false}, {0, false}}, Verify, true},
+		{"Cycle", []Tree{{None, true}, {2, false}, {1, false}, {0, false}}, Verify, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := world.NewWithOptions(world.Options{})
+			defer w.Stop()
+			for i, tree := range tt.trees {
+				w.CreateProcess(int32(i))
+				w.AssignWorkFunction(int32(i), []byte("BFS"))
+				process.Ctx[*BFS](w.ProcessesList[i], "BFS").Tree = tree
+			}
+			if _, err := tt.verify(w.ProcessesList, g, "BFS"); (err != nil) != tt.wantErr {
+				t.Errorf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,889 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/trmigor/distr-model/internal/broadcast"
+	"github.com/trmigor/distr-model/internal/clock"
+	"github.com/trmigor/distr-model/internal/debugger"
+	"github.com/trmigor/distr-model/internal/explore"
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/metrics"
+	"github.com/trmigor/distr-model/internal/property"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/internal/replay"
+	"github.com/trmigor/distr-model/internal/routing"
+	"github.com/trmigor/distr-model/internal/scenario"
+	"github.com/trmigor/distr-model/internal/sweep"
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+// defaultConfig is a scenario used when none is given.
+const defaultConfig = "configs/config.data"
+
+// command is a subcommand of the model executable.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", "[flags] [scenario]", "run the scenario", run},
+		{"check", "scenario", "check the scenario without running it", check},
+		{"trace", "[flags] [scenario]", "run the scenario and write its message trace", traceRun},
+		{"sweep", "[flags] [scenario]", "run the scenario across a grid of parameters and seeds in virtual time", sweepRun},
+		{"debug", "[flags] [scenario]", "step through the scenario interactively in virtual time", debugRun},
+		{"explore", "[flags] [scenario]", "check the properties in all the orders of deliveries of the initial messages", exploreRun},
+		{"convert", "config.data [scenario.yaml|scenario.json]", "convert config.data into a structured scenario", convert},
+		{"list", "", "list the available algorithms", list},
+		{"help", "", "show this help", help},
+	}
+}
+
+// execute runs the subcommand named by the first argument.
+// Without a subcommand the scenario given by the first argument (or the default one) is run.
+func execute(args []string) int {
+	if len(args) > 0 {
+		for _, c := range commands {
+			if c.name == args[0] {
+				return c.run(args[1:])
+			}
+		}
+		if len(args[0]) > 0 && args[0][0] == '-' {
+			return help(nil)
+		}
+	}
+	return run(args)
+}
+
+func help([]string) int {
+	fmt.Fprintln(os.Stderr, "usage: model <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8v %v\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nscenario is a config.data file or a YAML/JSON scenario, "+defaultConfig+" by default")
+	fmt.Fprintln(os.Stderr, "use 'model <command> -h' for the command flags")
+	return 2
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		for _, c := range commands {
+			if c.name == name {
+				fmt.Fprintf(fs.Output(), "usage: model %v %v\n", c.name, c.args)
+			}
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runFlags are the flags common to the commands running scenarios.
+type runFlags struct {
+	seed        int64
+	maxTicks    int64
+	speed       float64
+	out         string
+	traceFormat string
+	logLevel    string
+	clockName   string
+	metricsName string
+	format      trace.Format
+	clocks      clock.Kind
+	record      string
+	replay      string
+	dashboard   string
+}
+
+func (f *runFlags) register(fs *flag.FlagSet, speed float64) {
+	fs.Int64Var(&f.seed, "seed", 0, "random seed, 0 for a time-based one")
+	fs.Int64Var(&f.maxTicks, "max-ticks", 0, "stop the model at this tick, 0 for no limit")
+	fs.Float64Var(&f.speed, "speed", speed, "ticks per second of real time, 0 for virtual time")
+	fs.StringVar(&f.out, "out", "", "output directory")
+	fs.StringVar(&f.traceFormat, "trace-format", string(trace.Text), "trace format: text, json or csv")
+	fs.StringVar(&f.logLevel, "log-level", "info", "logging level: error, info or debug")
+	fs.StringVar(&f.clockName, "clocks", clock.None.String(), "logical clocks: none, lamport, vector or matrix")
+	fs.StringVar(&f.metricsName, "metrics", "none", "metrics output: none, table or json")
+}
+
+// registerReplay registers the flags of the commands running a single scenario.
+func (f *runFlags) registerReplay(fs *flag.FlagSet) {
+	fs.StringVar(&f.record, "record", "", "record the random draws, the order of handled messages and timers into the file")
+	fs.StringVar(&f.replay, "replay", "", "replay the run recorded into the file, in virtual time")
+}
+
+// registerDashboard registers the flag of the commands serving the dashboard.
+func (f *runFlags) registerDashboard(fs *flag.FlagSet) {
+	fs.StringVar(&f.dashboard, "dashboard", "", "serve the live dashboard at the address, e.g. localhost:8080")
+}
+
+// serve starts the dashboard of the world, if requested. The returned function goes on serving
+// the final state of the run until the command is interrupted.
+func (f *runFlags) serve(w *world.World) (func(), error) {
+	if f.dashboard == "" {
+		return func() {}, nil
+	}
+	s, err := w.Serve(f.dashboard)
+	if err != nil {
+		return nil, err
+	}
+	logging.Infof("dashboard at http://%v/", s.Addr())
+	return func() {
+		logging.Infof("dashboard at http://%v/ shows the final state, interrupt to exit", s.Addr())
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		signal.Stop(interrupt)
+		s.Close()
+	}, nil
+}
+
+// parse parses the arguments and returns the scenario name.
+func (f *runFlags) parse(fs *flag.FlagSet, args []string) (string, bool) {
+	if err := fs.Parse(args); err != nil {
+		return "", false
+	}
+	level, ok := logging.ParseLevel(f.logLevel)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown log level '%v'\n", f.logLevel)
+		return "", false
+	}
+	logging.SetLevel(level)
+	if f.format, ok = trace.ParseFormat(f.traceFormat); !ok {
+		fmt.Fprintf(os.Stderr, "unknown trace format '%v'\n", f.traceFormat)
+		return "", false
+	}
+	if f.clocks, ok = clock.ParseKind(f.clockName); !ok {
+		fmt.Fprintf(os.Stderr, "unknown clocks '%v'\n", f.clockName)
+		return "", false
+	}
+	if f.metricsName != "none" && f.metricsName != "table" && f.metricsName != "json" {
+		fmt.Fprintf(os.Stderr, "unknown metrics output '%v'\n", f.metricsName)
+		return "", false
+	}
+	if f.replay != "" {
+		f.speed = 0
+	}
+	if f.speed < 0 || f.maxTicks < 0 || fs.NArg() > 1 || f.record != "" && f.replay != "" {
+		fs.Usage()
+		return "", false
+	}
+	if fs.NArg() == 0 {
+		return defaultConfig, true
+	}
+	return fs.Arg(0), true
+}
+
+// create opens a file in the output directory.
+func (f *runFlags) create(name string) (*os.File, error) {
+	if err := os.MkdirAll(f.out, 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(filepath.Join(f.out, name))
+}
+
+// newWorld creates a world, the work functions are taken from the registry.
+func newWorld(f *runFlags, seed int64) *world.World {
+	return world.NewWithOptions(world.Options{
+		Seed:     seed,
+		Speed:    f.speed,
+		MaxTicks: f.maxTicks,
+		Clocks:   f.clocks,
+	})
+}
+
+// load launches the model described by the scenario of any format.
+func load(w *world.World, config string) bool {
+	if _, ok := scenario.FormatOf(config); ok {
+		return w.ParseScenario([]byte(config))
+	}
+	return w.ParseConfig([]byte(config))
+}
+
+// simulate runs the scenario writing its trace to out, if any.
+func simulate(f *runFlags, config string, out io.Writer) int {
+	w := newWorld(f, f.seed)
+	wait, err := f.serve(w)
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	defer wait()
+	var record *replay.Recorder
+	if f.record != "" {
+		record = w.Record()
+	}
+	if f.replay != "" {
+		l, err := replay.Open(f.replay)
+		if err == nil {
+			err = w.Replay(l)
+		}
+		if err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+	}
+	tracers := trace.Multi{}
+	var writer *trace.Writer
+	if out != nil {
+		writer = trace.NewWriter(out, f.format)
+		tracers = append(tracers, writer)
+	}
+	if logging.Enabled(logging.Debug) {
+		tracers = append(tracers, trace.Func(func(e trace.Event) {
+			logging.Debugf("%v", e)
+		}))
+	}
+	var recorder *trace.Recorder
+	if f.clocks != clock.None {
+		recorder = trace.NewRecorder()
+		tracers = append(tracers, recorder)
+	}
+	if len(tracers) > 0 {
+		w.Network.Tracer = tracers
+	}
+	exclusion := w.CheckExclusion()
+	agreement := w.CheckAgreement()
+	broadcasts := w.CheckBroadcast()
+	routes := w.CheckRouting()
+
+	ok := load(w, config)
+	w.Stop()
+	if !ok {
+		logging.Errorf("can't run '%v'", config)
+		return 1
+	}
+	logging.Infof("model stopped at tick %v", w.Network.Tick)
+	if record != nil {
+		if err := writeReplay(f.record, record.Log()); err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+		logging.Infof("run recorded into '%v'", f.record)
+	}
+	if err := w.Diverged(); err != nil {
+		logging.Errorf("replay of '%v' diverged: %v", f.replay, err)
+		return 1
+	}
+	if writer != nil {
+		if err := writer.Flush(); err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+	}
+	if err := f.snapshots(w); err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	if err := f.curves(broadcasts, w.Nodes()); err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	if err := f.metrics(w.Metrics()); err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	if recorder != nil {
+		if err := trace.CheckCausality(recorder.Events()); err != nil {
+			logging.Errorf("causality violated: %v", err)
+			return 1
+		}
+		logging.Infof("%v clocks agree with causality", f.clocks)
+	}
+	if terminate(w) != 0 || agree(agreement, w) != 0 || deliver(broadcasts, w) != 0 || route(routes, w) != 0 {
+		return 1
+	}
+	if code := f.verify(w); code != 0 {
+		return code
+	}
+	return exclude(exclusion, w)
+}
+
+// writeReplay writes the recorded run into the file.
+func writeReplay(name string, l *replay.Log) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := l.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// verify logs the outcomes of the properties, if any, and reports the violated ones. Their counterexamples
+// are written into the output directory, if any, or to the standard output otherwise.
+func (f *runFlags) verify(w *world.World) int {
+	violations := make([]property.Result, 0)
+	for _, r := range w.Properties() {
+		if r.OK {
+			logging.Infof("%v", r)
+			continue
+		}
+		logging.Errorf("%v", r)
+		violations = append(violations, r)
+	}
+	if len(violations) == 0 {
+		return 0
+	}
+	out := io.Writer(os.Stdout)
+	if f.out != "" {
+		file, err := f.create("counterexamples.txt")
+		if err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+	for _, v := range violations {
+		if err := v.WriteCounterexample(out); err != nil {
+			logging.Errorf("%v", err)
+			break
+		}
+	}
+	return 1
+}
+
+// deliver logs the broadcast messages, if any, and reports violations of the properties of broadcast algorithms.
+func deliver(c *broadcast.Checker, w *world.World) int {
+	if c.Broadcasts() == 0 {
+		return 0
+	}
+	logging.Infof("broadcast: %v messages, %v deliveries, %v messages sent", c.Broadcasts(), c.Deliveries(),
+		w.Network.Metrics().Sent())
+	violations := c.Violations(w.Nodes())
+	for _, v := range violations {
+		logging.Errorf("%v", v)
+	}
+	if len(violations) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// route logs the changes of the routing tables built by routing protocols, if any,
+// and reports routes which differ from the shortest paths over the links.
+func route(c *routing.Checker, w *world.World) int {
+	if c.Changes() == 0 {
+		return 0
+	}
+	logging.Infof("routing: %v table changes, the last one at tick %v", c.Changes(), c.LastChange())
+	if changed := w.Network.LinksChanged(); changed > 0 && c.LastChange() >= changed {
+		logging.Infof("routing converged %v ticks after the last link change at tick %v", c.LastChange()-changed, changed)
+	}
+	mismatches := c.Mismatches(w.Network.Links(), w.Nodes())
+	for _, m := range mismatches {
+		logging.Errorf("%v", m)
+	}
+	if len(mismatches) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// agree logs the decisions of consensus algorithms, if any, and reports contradicting ones.
+func agree(a *world.Agreement, w *world.World) int {
+	if a.Decisions() == 0 {
+		return 0
+	}
+	logging.Infof("consensus: %v slots decided, %v decisions, %v messages", a.Slots(), a.Decisions(),
+		w.Network.Metrics().Sent())
+	conflicts := a.Conflicts()
+	for _, c := range conflicts {
+		logging.Errorf("tick %v: process %v decided %v in slot %v, process %v decided %v at tick %v",
+			c.Tick, c.Node, c.Value, c.Slot, c.Earlier.Node, c.Earlier.Value, c.Earlier.Tick)
+	}
+	if len(conflicts) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// terminate logs the termination detected by the model, if enabled, and reports premature detections.
+func terminate(w *world.World) int {
+	r, ok := w.Termination()
+	if !ok {
+		return 0
+	}
+	if !r.Detected {
+		logging.Infof("termination not detected, quiescence at tick %v", r.Quiescence)
+		return 0
+	}
+	logging.Infof("termination detected at tick %v, quiescence at tick %v, delay %v",
+		r.Detection, r.Quiescence, r.Delay())
+	if r.Premature {
+		logging.Errorf("termination detected prematurely")
+		return 1
+	}
+	return 0
+}
+
+// exclude logs the critical section entries, if any, and reports violations of mutual exclusion.
+func exclude(x *world.Exclusion, w *world.World) int {
+	if x.Entries() == 0 {
+		return 0
+	}
+	logging.Infof("critical section: %v entries, %v messages, %.2f messages per entry",
+		x.Entries(), w.Network.Metrics().Sent(), x.MessagesPerEntry())
+	violations := x.Violations()
+	for _, v := range violations {
+		logging.Errorf("tick %v: processes %v are in the critical section at once", v.Tick, v.Nodes)
+	}
+	if len(violations) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// snapshots logs the global snapshots taken by the model and writes them into the output directory, if any.
+func (f *runFlags) snapshots(w *world.World) error {
+	snapshots := w.Snapshots()
+	if len(snapshots) == 0 {
+		return nil
+	}
+	for _, g := range snapshots {
+		logging.Infof("snapshot %v initiated by %v at tick %v: %v processes recorded, complete: %v",
+			g.ID, g.Initiator, g.Tick, len(g.Processes), g.Complete)
+	}
+	if f.out == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(f.out, "snapshots.json"), data, 0644)
+}
+
+// metrics logs the message and time complexity of the run and writes its metrics in the chosen format
+// into the output directory, if any, or to the standard output otherwise.
+func (f *runFlags) metrics(m *metrics.Metrics) error {
+	logging.Infof("metrics: %v messages of %v bytes sent, %v delivered, %v dropped, last activity at tick %v",
+		m.Sent, m.Bytes, m.Delivered, m.Drops(), m.LastActivity)
+	if f.metricsName == "none" {
+		return nil
+	}
+	out := io.Writer(os.Stdout)
+	if f.out != "" {
+		name := "metrics.txt"
+		if f.metricsName == "json" {
+			name = "metrics.json"
+		}
+		file, err := f.create(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if f.metricsName == "json" {
+		return m.WriteJSON(out)
+	}
+	return m.WriteTable(out)
+}
+
+// curves logs how far the broadcast messages, if any, have spread and writes their convergence curves
+// into the output directory, if any.
+func (f *runFlags) curves(c *broadcast.Checker, nodes []int32) error {
+	if c.Broadcasts() == 0 {
+		return nil
+	}
+	for _, id := range c.IDs() {
+		curve := c.Curve(id, nodes)
+		if len(curve) == 0 {
+			continue
+		}
+		first, last := curve[0], curve[len(curve)-1]
+		logging.Debugf("message %v: %v of %v processes informed in %v ticks", id, last.Informed, len(nodes), last.Tick-first.Tick)
+	}
+	if f.out == "" {
+		return nil
+	}
+	file, err := f.create("convergence.csv")
+	if err != nil {
+		return err
+	}
+	if err := c.WriteCurves(file, nodes); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// output runs the scenario writing its trace into the output directory, if any, or to out otherwise.
+func output(f *runFlags, config string, out io.Writer) int {
+	if f.out == "" {
+		return simulate(f, config, out)
+	}
+	file, err := f.create("trace" + f.format.Extension())
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	defer file.Close()
+	return simulate(f, config, file)
+}
+
+func run(args []string) int {
+	f := &runFlags{}
+	fs := newFlagSet("run")
+	f.register(fs, 1)
+	f.registerReplay(fs)
+	f.registerDashboard(fs)
+	config, ok := f.parse(fs, args)
+	if !ok {
+		return 2
+	}
+	return output(f, config, nil)
+}
+
+func traceRun(args []string) int {
+	f := &runFlags{}
+	fs := newFlagSet("trace")
+	f.register(fs, 0)
+	f.registerReplay(fs)
+	f.registerDashboard(fs)
+	config, ok := f.parse(fs, args)
+	if !ok {
+		return 2
+	}
+	return output(f, config, os.Stdout)
+}
+
+func debugRun(args []string) int {
+	f := &runFlags{}
+	fs := newFlagSet("debug")
+	f.register(fs, 0)
+	f.registerDashboard(fs)
+	config, ok := f.parse(fs, args)
+	if !ok {
+		return 2
+	}
+	f.speed = 0
+	w := newWorld(f, f.seed)
+	wait, err := f.serve(w)
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	defer wait()
+	d := debugger.New(w, os.Stdin, os.Stdout)
+	fmt.Println("the run pauses before every message, type help for the commands")
+	ok = d.Run(func() bool {
+		return load(w, config)
+	})
+	w.Stop()
+	if !ok {
+		logging.Errorf("can't run '%v'", config)
+		return 1
+	}
+	return f.verify(w)
+}
+
+// parameters is a repeatable flag of sweep parameters.
+type parameters []sweep.Parameter
+
+func (p *parameters) String() string {
+	names := make([]string, 0, len(*p))
+	for _, q := range *p {
+		names = append(names, q.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (p *parameters) Set(s string) error {
+	q, err := sweep.ParseParameter(s)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, q)
+	return nil
+}
+
+// loadData launches the model described by the scenario text, its format is chosen by the name of the scenario.
+func loadData(w *world.World, config string, data []byte) bool {
+	if format, ok := scenario.FormatOf(config); ok {
+		s, err := scenario.Parse(data, format)
+		if err != nil {
+			logging.Errorf("%v: %v", config, err)
+			return false
+		}
+		return w.ApplyScenario(s)
+	}
+	return w.LoadConfig(data)
+}
+
+// writeCSV writes the rows into the file in the output directory, if any, or to the standard output otherwise.
+func (f *runFlags) writeCSV(name string, rows [][]string) error {
+	out := io.Writer(os.Stdout)
+	if f.out != "" {
+		file, err := f.create(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return csv.NewWriter(out).WriteAll(rows)
+}
+
+func sweepRun(args []string) int {
+	f := &runFlags{}
+	fs := newFlagSet("sweep")
+	f.register(fs, 0)
+	runs := fs.Int("runs", 10, "number of runs of every point, seeds are consecutive starting from the -seed one (1 by default)")
+	parallel := fs.Int("parallel", runtime.NumCPU(), "number of runs performed at once")
+	var params parameters
+	fs.Var(&params, "vary", "parameter substituted for ${name} in the scenario: name=from..to [step s] or name=v1,v2,...")
+	config, ok := f.parse(fs, args)
+	if !ok || *runs < 1 {
+		return 2
+	}
+	if f.seed == 0 {
+		f.seed = 1
+	}
+	data, err := ioutil.ReadFile(config)
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	grid := sweep.Grid(params)
+	text, unused := sweep.Expand(data, grid[0])
+	if len(unused) > 0 {
+		logging.Errorf("'%v' has no ${%v}", config, strings.Join(unused, "}, ${"))
+		return 2
+	}
+	if missing := sweep.Missing(text); len(missing) > 0 {
+		logging.Errorf("'%v' refers to ${%v} not given by -vary", config, strings.Join(missing, "}, ${"))
+		return 2
+	}
+	seeds := make([]int64, *runs)
+	for i := range seeds {
+		seeds[i] = f.seed + int64(i)
+	}
+
+	results := sweep.Execute(grid, seeds, *parallel, func(p sweep.Point, seed int64) *metrics.Metrics {
+		text, _ := sweep.Expand(data, p)
+		w := newWorld(f, seed)
+		ok := loadData(w, config, text)
+		w.Stop()
+		if !ok {
+			logging.Errorf("can't run '%v' with %v, seed %v", config, p, seed)
+			return nil
+		}
+		return w.Metrics()
+	})
+	logging.Infof("sweep: %v points, %v runs", len(grid), len(results))
+	if f.out != "" {
+		if err := f.writeCSV("runs.csv", sweep.Rows(results)); err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+	}
+	if err := f.writeCSV("sweep.csv", sweep.Summaries(results)); err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	for _, r := range results {
+		if r.Metrics == nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// explorable returns the scenario with the sends of its schedule made initial messages. Other steps
+// of the schedule and random losses are not explored.
+func explorable(s *scenario.Scenario) *scenario.Scenario {
+	res := *s
+	res.Messages = append([]scenario.Send{}, s.Messages...)
+	res.Schedule = nil
+	for _, st := range s.Schedule {
+		if st.Send != nil {
+			res.Messages = append(res.Messages, *st.Send)
+		} else {
+			logging.Infof("explore: the schedule is not performed except its sends")
+		}
+	}
+	if s.Faults != nil && s.Faults.ErrorRate > 0 {
+		faults := *s.Faults
+		faults.ErrorRate = 0
+		res.Faults = &faults
+		logging.Infof("explore: random losses are replaced by -losses")
+	}
+	return &res
+}
+
+func exploreRun(args []string) int {
+	f := &runFlags{}
+	fs := newFlagSet("explore")
+	f.register(fs, 0)
+	var o explore.Options
+	fs.IntVar(&o.MaxDepth, "max-depth", explore.DefaultMaxDepth, "maximum number of steps of a schedule")
+	fs.IntVar(&o.MaxStates, "max-states", explore.DefaultMaxStates, "maximum number of distinct states")
+	fs.IntVar(&o.Losses, "losses", 0, "number of messages between processes a schedule may lose")
+	fs.BoolVar(&o.NoReduction, "no-reduction", false, "explore all the orders of independent steps")
+	config, ok := f.parse(fs, args)
+	if !ok {
+		return 2
+	}
+	if f.seed == 0 {
+		f.seed = 1
+	}
+	s, err := scenario.Open(config)
+	if err != nil {
+		logging.Errorf("%v: %v", config, err)
+		return 1
+	}
+	if len(s.Properties) == 0 {
+		logging.Errorf("'%v' has no properties to check", config)
+		return 2
+	}
+	s = explorable(s)
+
+	level, _ := logging.ParseLevel(f.logLevel)
+	if level != logging.Debug {
+		// Every state is reached by a new run, their logs are not shown.
+		logging.SetLevel(logging.Error)
+	}
+	r, err := explore.Explore(func() (*world.World, error) {
+		w := world.NewWithOptions(world.Options{Seed: f.seed, Clocks: f.clocks})
+		if !w.Prepare(s) {
+			w.Stop()
+			return nil, fmt.Errorf("can't prepare '%v'", config)
+		}
+		return w, nil
+	}, o)
+	logging.SetLevel(level)
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	bound := "complete"
+	if !r.Complete {
+		bound = "bounded by -max-depth or -max-states"
+	}
+	logging.Infof("explore: %v states, %v transitions, %v", r.States, r.Transitions, bound)
+	if r.Violation == nil {
+		logging.Infof("explore: no violations")
+		return 0
+	}
+	logging.Errorf("%v after %v steps", r.Violation, len(r.Schedule))
+	out := io.Writer(os.Stdout)
+	if f.out != "" {
+		file, err := f.create("counterexample.txt")
+		if err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+	fmt.Fprintln(out, "schedule:")
+	for i, st := range r.Schedule {
+		fmt.Fprintf(out, "%v. %v\n", i+1, st)
+	}
+	if err := r.Violation.WriteCounterexample(out); err != nil {
+		logging.Errorf("%v", err)
+	}
+	return 1
+}
+
+// check parses a scenario without running it and reports found problems.
+func check(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: model check scenario")
+		return 2
+	}
+	s, err := scenario.Open(args[0])
+	if err != nil {
+		logging.Errorf("%v: %v", args[0], err)
+		return 1
+	}
+	r := scenario.Check(s, registry.Names(), registry.ContextNames())
+	fmt.Print(r)
+	if !r.OK() {
+		return 1
+	}
+	return 0
+}
+
+// convert translates a config.data file into a scenario.
+// The scenario format is chosen by the output file extension, YAML is written to stdout.
+// Output files with other extensions are rejected.
+func convert(args []string) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "usage: model convert config.data [scenario.yaml|scenario.json]")
+		return 2
+	}
+	format := scenario.YAML
+	if len(args) == 2 {
+		f, ok := scenario.FormatOf(args[1])
+		if !ok {
+			logging.Errorf("%v: unknown scenario format, want .yaml, .yml or .json", args[1])
+			return 2
+		}
+		format = f
+	}
+	s, err := scenario.Open(args[0])
+	if err != nil {
+		logging.Errorf("%v: %v", args[0], err)
+		return 1
+	}
+	out, err := s.Marshal(format)
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	if len(args) == 2 {
+		err = ioutil.WriteFile(args[1], out, 0644)
+	} else {
+		_, err = os.Stdout.Write(out)
+	}
+	if err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+	return 0
+}
+
+// list prints the registered algorithms with their contexts.
+func list(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "usage: model list")
+		return 2
+	}
+	for _, a := range registry.All() {
+		contexts := make([]string, 0, len(a.Contexts))
+		for name := range a.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+		fmt.Printf("%-12v %v\n", a.Name, a.Description)
+		if len(contexts) > 0 {
+			fmt.Printf("%-12v contexts: %v\n", "", strings.Join(contexts, ", "))
+		}
+	}
+	return 0
+}
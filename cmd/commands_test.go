@@ -0,0 +1,210 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/scenario"
+)
+
+// TestMain runs the commands from the root of the repository, so that the default scenario is found.
+func TestMain(m *testing.M) {
+	logging.SetLevel(logging.Error)
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// write creates the file with the text in the temporary directory of the test.
+func write(t *testing.T, name string, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExecute(t *testing.T) {
+	quiet := write(t, "quiet.data", "processes 0 1\nlink from 0 to 1 latency 1\n")
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"Help", []string{"help"}, 2},
+		{"Flag without command", []string{"-speed", "0"}, 2},
+		{"Bare scenario", []string{quiet}, 0},
+		{"Default scenario", []string{"run", "-speed", "0", "-log-level", "error"}, 0},
+		{"Run", []string{"run", "-speed", "0", "-log-level", "error", defaultConfig}, 0},
+		{"Trace", []string{"trace", "-log-level", "error", "-out", t.TempDir(), defaultConfig}, 0},
+		{"Missing scenario", []string{"run", "-speed", "0", "-log-level", "error", "configs/none.data"}, 1},
+		{"Check", []string{"check", defaultConfig}, 0},
+		{"Check without scenario", []string{"check"}, 2},
+		{"List", []string{"list"}, 0},
+		{"List with arguments", []string{"list", "x"}, 2},
+		{"Convert without scenario", []string{"convert"}, 2},
+		{"Convert", []string{"convert", defaultConfig, filepath.Join(t.TempDir(), "config.json")}, 0},
+		{"Convert to unknown format", []string{"convert", defaultConfig, filepath.Join(t.TempDir(), "config.txt")}, 2},
+		{"Convert missing scenario", []string{"convert", "configs/none.data", filepath.Join(t.TempDir(), "none.yaml")}, 1},
+		{"Explore without properties", []string{"explore", "-log-level", "error", quiet}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(tt.args); got != tt.want {
+				t.Errorf("execute(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFlags_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		config string
+		ok     bool
+	}{
+		{"Default scenario", []string{}, defaultConfig, true},
+		{"Scenario", []string{"-seed", "3", "a.data"}, "a.data", true},
+		{"Several scenarios", []string{"a.data", "b.data"}, "", false},
+		{"Negative speed", []string{"-speed", "-1"}, "", false},
+		{"Negative max ticks", []string{"-max-ticks", "-1"}, "", false},
+		{"Log level", []string{"-log-level", "loud"}, "", false},
+		{"Trace format", []string{"-trace-format", "xml"}, "", false},
+		{"Clocks", []string{"-clocks", "atomic"}, "", false},
+		{"Metrics", []string{"-metrics", "csv"}, "", false},
+		{"Record and replay", []string{"-record", "a", "-replay", "b"}, "", false},
+		{"Unknown flag", []string{"-fast"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &runFlags{}
+			fs := newFlagSet("run")
+			fs.SetOutput(ioutil.Discard)
+			f.register(fs, 1)
+			f.registerReplay(fs)
+			config, ok := f.parse(fs, append([]string{"-log-level", "error"}, tt.args...))
+			if config != tt.config || ok != tt.ok {
+				t.Errorf("runFlags.parse(%v) = %v, %v, want %v, %v", tt.args, config, ok, tt.config, tt.ok)
+			}
+		})
+	}
+	logging.SetLevel(logging.Error)
+}
+
+func TestRunFlags_parseReplay(t *testing.T) {
+	f := &runFlags{}
+	fs := newFlagSet("run")
+	f.register(fs, 1)
+	f.registerReplay(fs)
+	if _, ok := f.parse(fs, []string{"-log-level", "error", "-speed", "5", "-replay", "a"}); !ok || f.speed != 0 {
+		t.Errorf("runFlags.parse() with -replay: speed = %v, want virtual time", f.speed)
+	}
+}
+
+// TestFlags checks which commands accept the record, replay and dashboard flags.
+func TestFlags(t *testing.T) {
+	bad := "localhost:-1"
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"Run dashboard", []string{"run", "-speed", "0", "-log-level", "error", "-dashboard", bad}, 1},
+		{"Trace dashboard", []string{"trace", "-log-level", "error", "-dashboard", bad}, 1},
+		{"Debug dashboard", []string{"debug", "-log-level", "error", "-dashboard", bad}, 1},
+		{"Sweep dashboard", []string{"sweep", "-dashboard", bad}, 2},
+		{"Explore dashboard", []string{"explore", "-dashboard", bad}, 2},
+		{"Debug record", []string{"debug", "-record", "a"}, 2},
+		{"Debug replay", []string{"debug", "-replay", "a"}, 2},
+		{"Sweep record", []string{"sweep", "-record", "a"}, 2},
+		{"Replay of nothing", []string{"run", "-log-level", "error", "-replay", "configs/none.replay"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(tt.args); got != tt.want {
+				t.Errorf("execute(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "rumor.replay")
+	args := []string{"run", "-speed", "0", "-log-level", "error", "-record", record, "configs/gossip/rumor.data"}
+	if got := execute(args); got != 0 {
+		t.Fatalf("execute(%v) = %v, want 0", args, got)
+	}
+	if _, err := os.Stat(record); err != nil {
+		t.Fatalf("run -record has not written the run: %v", err)
+	}
+	args = []string{"run", "-log-level", "error", "-replay", record, "configs/gossip/rumor.data"}
+	if got := execute(args); got != 0 {
+		t.Errorf("execute(%v) = %v, want 0", args, got)
+	}
+	args = []string{"run", "-log-level", "error", "-replay", record, "configs/election/lcr.data"}
+	if got := execute(args); got != 1 {
+		t.Errorf("execute(%v) of another scenario = %v, want 1", args, got)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	template := "processes 0 ${n}\nlink from all to all latency 1\nsend from -1 to 0 PING\nwait 2\n"
+	plain := write(t, "plain.data", template)
+	lossy := write(t, "lossy.data", "errorRate ${errorRate}\n"+template)
+	out := t.TempDir()
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"Parameters", []string{"-vary", "n=1,2", plain}, 0},
+		{"Error rate", []string{"-vary", "n=1", "-vary", "errorRate=0,0.5", lossy}, 0},
+		{"Error rate not referred to", []string{"-vary", "n=1", "-vary", "errorRate=0,0.5", plain}, 2},
+		{"Parameter not referred to", []string{"-vary", "n=1", "-vary", "k=1", plain}, 2},
+		{"Parameter not given", []string{"-vary", "n=1", lossy}, 2},
+		{"No runs", []string{"-runs", "0", "-vary", "n=1", plain}, 2},
+		{"Bad parameter", []string{"-vary", "n", plain}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"sweep", "-runs", "2", "-log-level", "error", "-out", out}, tt.args...)
+			if got := execute(args); got != tt.want {
+				t.Errorf("execute(%v) = %v, want %v", args, got, tt.want)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(out, "sweep.csv")); err != nil {
+		t.Errorf("sweep has not written the summaries: %v", err)
+	}
+}
+
+func TestExplorable(t *testing.T) {
+	wait := 3
+	s := &scenario.Scenario{
+		Faults:   &scenario.Faults{ErrorRate: 0.2},
+		Messages: []scenario.Send{{From: -1, To: 0, Type: "A"}},
+		Schedule: []scenario.Step{
+			{Send: &scenario.Send{From: -1, To: 1, Type: "B"}},
+			{Wait: &wait},
+			{Send: &scenario.Send{From: -1, To: 2, Type: "C"}},
+		},
+	}
+	got := explorable(s)
+	want := []scenario.Send{{From: -1, To: 0, Type: "A"}, {From: -1, To: 1, Type: "B"}, {From: -1, To: 2, Type: "C"}}
+	if !reflect.DeepEqual(got.Messages, want) {
+		t.Errorf("explorable().Messages = %v, want %v", got.Messages, want)
+	}
+	if got.Schedule != nil || got.Faults.ErrorRate != 0 {
+		t.Errorf("explorable() = %+v, want no schedule and no error rate", got)
+	}
+	if len(s.Messages) != 1 || len(s.Schedule) != 3 || s.Faults.ErrorRate != 0.2 {
+		t.Errorf("explorable() has changed the scenario: %+v", s)
+	}
+}
@@ -0,0 +1,357 @@
+// Package broadcast checks properties of broadcast algorithms by their traces.
+//
+// Broadcast algorithms report every broadcast message and every delivery of a broadcast message to the network
+// tracer as broadcast and accept events. Messages are identified by their origins and sequence numbers,
+// so a run should use a single broadcast algorithm.
+// Algorithms declare the properties they guarantee by Guarantee, so that the checker knows what to check.
+// The checker also keeps the ticks processes deliver messages at, giving convergence curves of dissemination.
+package broadcast
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Property is a property of broadcast algorithms.
+type Property string
+
+const (
+	// Integrity: every process delivers a message at most once and only if it has been broadcast.
+	Integrity Property = "integrity"
+	// Validity: every broadcast message is delivered by all the processes.
+	Validity Property = "validity"
+	// Agreement: a message delivered by a process is delivered by all the processes.
+	Agreement Property = "agreement"
+	// FIFO: the messages of an origin are delivered in the order they have been broadcast.
+	FIFO Property = "fifo"
+	// Causal: a message is delivered after all the messages delivered or broadcast by its origin before it.
+	Causal Property = "causal"
+	// Total: all the processes deliver the messages in the same order.
+	Total Property = "total"
+)
+
+// Properties returns all the properties.
+func Properties() []Property {
+	return []Property{Integrity, Validity, Agreement, FIFO, Causal, Total}
+}
+
+var (
+	guaranteesMutex sync.Mutex
+	guarantees      = make(map[string][]Property)
+)
+
+// Guarantee declares the properties guaranteed by the algorithm with the name.
+// It is called by algorithm packages in init.
+func Guarantee(name string, properties ...Property) {
+	guaranteesMutex.Lock()
+	defer guaranteesMutex.Unlock()
+	guarantees[name] = properties
+}
+
+// Guarantees returns the properties guaranteed by the algorithm with the name, Integrity for unknown algorithms.
+func Guarantees(name string) []Property {
+	guaranteesMutex.Lock()
+	defer guaranteesMutex.Unlock()
+	if res, ok := guarantees[name]; ok {
+		return res
+	}
+	return []Property{Integrity}
+}
+
+// ID identifies a broadcast message.
+type ID struct {
+	Origin int32
+	Seq    int32
+}
+
+func (id ID) String() string {
+	return fmt.Sprintf("%v:%v", id.Origin, id.Seq)
+}
+
+// Violation is a violation of a property.
+type Violation struct {
+	Property  Property
+	Algorithm string
+	Node      int32
+	Message   ID
+	Reason    string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%v of %v violated by process %v on message %v: %v", v.Property, v.Algorithm, v.Node, v.Message, v.Reason)
+}
+
+// sent is a broadcast message with its causal past: the greatest sequence numbers of the messages
+// of every origin delivered or broadcast by the origin before it.
+type sent struct {
+	algorithm string
+	tick      int64
+	past      map[int32]int32
+}
+
+// Checker is a tracer keeping the broadcast and accept events of a run, so that the properties
+// of broadcast algorithms can be checked.
+type Checker struct {
+	mutex      sync.Mutex
+	sent       map[ID]sent
+	order      []ID
+	deliveries map[int32][]ID
+	past       map[int32]map[int32]int32
+	informed   map[ID]map[int32]int64
+	violations []Violation
+}
+
+// NewChecker creates a checker without events.
+func NewChecker() *Checker {
+	return &Checker{
+		sent:       make(map[ID]sent),
+		order:      make([]ID, 0),
+		deliveries: make(map[int32][]ID),
+		past:       make(map[int32]map[int32]int32),
+		informed:   make(map[ID]map[int32]int64),
+		violations: make([]Violation, 0),
+	}
+}
+
+// Record implements trace.Tracer.
+func (c *Checker) Record(e trace.Event) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var name string
+	var id ID
+	switch e.Kind {
+	case trace.Broadcast, trace.Accept:
+		if _, err := fmt.Sscanf(e.Message, "%s %d %d", &name, &id.Origin, &id.Seq); err != nil {
+			return
+		}
+	default:
+		return
+	}
+	past := c.past[e.From]
+	if past == nil {
+		past = make(map[int32]int32)
+		c.past[e.From] = past
+	}
+	if e.Kind == trace.Broadcast {
+		if _, ok := c.sent[id]; ok {
+			return
+		}
+		s := sent{algorithm: name, tick: e.Tick, past: make(map[int32]int32, len(past))}
+		for origin, seq := range past {
+			s.past[origin] = seq
+		}
+		c.sent[id] = s
+		c.order = append(c.order, id)
+		if id.Seq > past[id.Origin] {
+			past[id.Origin] = id.Seq
+		}
+		return
+	}
+	c.deliveries[e.From] = append(c.deliveries[e.From], id)
+	if c.informed[id] == nil {
+		c.informed[id] = make(map[int32]int64)
+	}
+	if _, ok := c.informed[id][e.From]; !ok {
+		c.informed[id][e.From] = e.Tick
+	}
+	s, ok := c.sent[id]
+	if !ok {
+		c.violate(Integrity, name, e.From, id, "the message has not been broadcast")
+		return
+	}
+	for origin, seq := range s.past {
+		if seq > past[origin] {
+			past[origin] = seq
+		}
+	}
+	if id.Seq > past[id.Origin] {
+		past[id.Origin] = id.Seq
+	}
+}
+
+func (c *Checker) violate(p Property, algorithm string, node int32, id ID, format string, a ...interface{}) {
+	c.violations = append(c.violations, Violation{p, algorithm, node, id, fmt.Sprintf(format, a...)})
+}
+
+// Broadcasts returns the number of broadcast messages.
+func (c *Checker) Broadcasts() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.sent)
+}
+
+// Deliveries returns the number of deliveries of broadcast messages.
+func (c *Checker) Deliveries() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := 0
+	for _, d := range c.deliveries {
+		res += len(d)
+	}
+	return res
+}
+
+// IDs returns the broadcast messages in the order they are broadcast.
+func (c *Checker) IDs() []ID {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]ID(nil), c.order...)
+}
+
+// Point is a point of a convergence curve: the fraction of the processes informed of a message by the tick.
+type Point struct {
+	Tick     int64
+	Informed int
+	Fraction float64
+}
+
+// Curve returns the convergence curve of the message over the processes of the nodes: a point for the tick
+// it is broadcast and for every tick some of the processes deliver it for the first time.
+func (c *Checker) Curve(id ID, nodes []int32) []Point {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s, ok := c.sent[id]
+	if !ok || len(nodes) == 0 {
+		return nil
+	}
+	ticks := make([]int64, 0, len(nodes))
+	for _, node := range nodes {
+		if tick, ok := c.informed[id][node]; ok {
+			ticks = append(ticks, tick)
+		}
+	}
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i] < ticks[j] })
+	res := []Point{{Tick: s.tick}}
+	for i, tick := range ticks {
+		last := &res[len(res)-1]
+		if tick > last.Tick {
+			res = append(res, Point{Tick: tick})
+			last = &res[len(res)-1]
+		}
+		last.Informed = i + 1
+		last.Fraction = float64(i+1) / float64(len(nodes))
+	}
+	return res
+}
+
+// WriteCurves writes the convergence curves of all the messages over the processes of the nodes as CSV.
+func (c *Checker) WriteCurves(w io.Writer, nodes []int32) error {
+	if _, err := fmt.Fprintln(w, "message,tick,informed,fraction"); err != nil {
+		return err
+	}
+	for _, id := range c.IDs() {
+		for _, p := range c.Curve(id, nodes) {
+			if _, err := fmt.Fprintf(w, "%v,%v,%v,%.4f\n", id, p.Tick, p.Informed, p.Fraction); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Violations checks the properties guaranteed by the algorithms of the broadcast messages
+// for the processes with the nodes and returns their violations.
+func (c *Checker) Violations(nodes []int32) []Violation {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := append([]Violation{}, c.violations...)
+	violate := func(p Property, node int32, id ID, format string, a ...interface{}) {
+		res = append(res, Violation{p, c.sent[id].algorithm, node, id, fmt.Sprintf(format, a...)})
+	}
+	guaranteed := func(id ID, p Property) bool {
+		for _, g := range Guarantees(c.sent[id].algorithm) {
+			if g == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	// positions of the messages in the sequences of deliveries
+	positions := make(map[int32]map[ID]int)
+	for _, node := range nodes {
+		positions[node] = make(map[ID]int)
+		for i, id := range c.deliveries[node] {
+			if _, ok := c.sent[id]; !ok {
+				continue
+			}
+			if _, ok := positions[node][id]; ok {
+				if guaranteed(id, Integrity) {
+					violate(Integrity, node, id, "the message is delivered twice")
+				}
+				continue
+			}
+			positions[node][id] = i
+		}
+	}
+
+	for _, id := range c.order {
+		delivered := make([]int32, 0)
+		for _, node := range nodes {
+			if _, ok := positions[node][id]; ok {
+				delivered = append(delivered, node)
+			}
+		}
+		for _, node := range nodes {
+			pos, ok := positions[node][id]
+			if !ok {
+				if guaranteed(id, Validity) {
+					violate(Validity, node, id, "the message is not delivered")
+				} else if len(delivered) > 0 && guaranteed(id, Agreement) {
+					violate(Agreement, node, id, "the message is not delivered, but processes %v delivered it", delivered)
+				}
+				continue
+			}
+			if guaranteed(id, FIFO) {
+				if m, ok := missing(positions[node], pos, map[int32]int32{id.Origin: id.Seq - 1}); ok {
+					violate(FIFO, node, id, "the message is delivered before %v", m)
+				}
+			}
+			if guaranteed(id, Causal) {
+				if m, ok := missing(positions[node], pos, c.sent[id].past); ok {
+					violate(Causal, node, id, "the message is delivered before %v", m)
+				}
+			}
+		}
+	}
+
+	for i, p := range nodes {
+		for _, q := range nodes[i+1:] {
+			var last ID
+			found := false
+			for j, id := range c.deliveries[q] {
+				pos, ok := positions[p][id]
+				if !ok || positions[q][id] != j || !guaranteed(id, Total) {
+					continue
+				}
+				if found && pos < positions[p][last] {
+					violate(Total, q, id, "the message is delivered after %v, process %v delivered them in the reverse order", last, p)
+				}
+				last, found = id, true
+			}
+		}
+	}
+	return res
+}
+
+// missing returns a message of the past, which is not delivered before the position:
+// the past holds the greatest sequence numbers of the messages of every origin.
+func missing(positions map[ID]int, at int, past map[int32]int32) (ID, bool) {
+	origins := make([]int32, 0, len(past))
+	for origin := range past {
+		origins = append(origins, origin)
+	}
+	sort.Slice(origins, func(i, j int) bool { return origins[i] < origins[j] })
+	for _, origin := range origins {
+		for seq := int32(1); seq <= past[origin]; seq++ {
+			if p, ok := positions[ID{origin, seq}]; !ok || p > at {
+				return ID{origin, seq}, true
+			}
+		}
+	}
+	return ID{}, false
+}
@@ -0,0 +1,181 @@
+package broadcast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+func init() {
+	Guarantee("TEST_BEB", Integrity, Validity)
+	Guarantee("TEST_RB", Integrity, Agreement)
+	Guarantee("TEST_FIFO", FIFO)
+	Guarantee("TEST_CAUSAL", Causal)
+	Guarantee("TEST_TOTAL", Total)
+}
+
+func bcast(node int32, name string, seq int32) trace.Event {
+	return trace.Event{Kind: trace.Broadcast, From: node, To: node, Message: fmt.Sprintf("%v %v %v 0", name, node, seq)}
+}
+
+func accept(node int32, name string, origin int32, seq int32) trace.Event {
+	return trace.Event{Kind: trace.Accept, From: node, To: node, Message: fmt.Sprintf("%v %v %v 0", name, origin, seq)}
+}
+
+func TestGuarantees(t *testing.T) {
+	if got, want := Guarantees("TEST_RB"), []Property{Integrity, Agreement}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Guarantees() = %v, want %v", got, want)
+	}
+	if got, want := Guarantees("UNKNOWN"), []Property{Integrity}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Guarantees() = %v, want %v", got, want)
+	}
+}
+
+func TestChecker_Violations(t *testing.T) {
+	tests := []struct {
+		name   string
+		nodes  []int32
+		events []trace.Event
+		want   []Violation
+	}{
+		{
+			"Valid",
+			[]int32{0, 1},
+			[]trace.Event{bcast(0, "TEST_BEB", 1), accept(0, "TEST_BEB", 0, 1), accept(1, "TEST_BEB", 0, 1)},
+			[]Violation{},
+		},
+		{
+			"Created",
+			[]int32{0, 1},
+			[]trace.Event{accept(1, "TEST_BEB", 0, 1)},
+			[]Violation{{Integrity, "TEST_BEB", 1, ID{0, 1}, "the message has not been broadcast"}},
+		},
+		{
+			"Duplicated",
+			[]int32{0, 1},
+			[]trace.Event{bcast(0, "TEST_BEB", 1), accept(0, "TEST_BEB", 0, 1), accept(1, "TEST_BEB", 0, 1), accept(1, "TEST_BEB", 0, 1)},
+			[]Violation{{Integrity, "TEST_BEB", 1, ID{0, 1}, "the message is delivered twice"}},
+		},
+		{
+			"Invalid",
+			[]int32{0, 1},
+			[]trace.Event{bcast(0, "TEST_BEB", 1), accept(0, "TEST_BEB", 0, 1)},
+			[]Violation{{Validity, "TEST_BEB", 1, ID{0, 1}, "the message is not delivered"}},
+		},
+		{
+			"Disagreed",
+			[]int32{0, 1},
+			[]trace.Event{bcast(0, "TEST_RB", 1), bcast(0, "TEST_RB", 2), accept(0, "TEST_RB", 0, 1)},
+			[]Violation{{Agreement, "TEST_RB", 1, ID{0, 1}, "the message is not delivered, but processes [0] delivered it"}},
+		},
+		{
+			"FIFO",
+			[]int32{0, 1},
+			[]trace.Event{
+				bcast(0, "TEST_FIFO", 1), bcast(0, "TEST_FIFO", 2),
+				accept(0, "TEST_FIFO", 0, 1), accept(0, "TEST_FIFO", 0, 2),
+				accept(1, "TEST_FIFO", 0, 2), accept(1, "TEST_FIFO", 0, 1),
+			},
+			[]Violation{{FIFO, "TEST_FIFO", 1, ID{0, 2}, "the message is delivered before 0:1"}},
+		},
+		{
+			"Causal",
+			[]int32{0, 1, 2},
+			[]trace.Event{
+				bcast(0, "TEST_CAUSAL", 1), accept(0, "TEST_CAUSAL", 0, 1), accept(1, "TEST_CAUSAL", 0, 1),
+				bcast(1, "TEST_CAUSAL", 1), accept(1, "TEST_CAUSAL", 1, 1),
+				accept(2, "TEST_CAUSAL", 1, 1), accept(2, "TEST_CAUSAL", 0, 1),
+				accept(0, "TEST_CAUSAL", 1, 1),
+			},
+			[]Violation{{Causal, "TEST_CAUSAL", 2, ID{1, 1}, "the message is delivered before 0:1"}},
+		},
+		{
+			"Total",
+			[]int32{0, 1, 2},
+			[]trace.Event{
+				bcast(0, "TEST_TOTAL", 1), bcast(1, "TEST_TOTAL", 1),
+				accept(0, "TEST_TOTAL", 0, 1), accept(0, "TEST_TOTAL", 1, 1),
+				accept(1, "TEST_TOTAL", 1, 1), accept(1, "TEST_TOTAL", 0, 1),
+				accept(2, "TEST_TOTAL", 0, 1), accept(2, "TEST_TOTAL", 1, 1),
+			},
+			[]Violation{
+				{Total, "TEST_TOTAL", 1, ID{0, 1}, "the message is delivered after 1:1, process 0 delivered them in the reverse order"},
+				{Total, "TEST_TOTAL", 2, ID{1, 1}, "the message is delivered after 0:1, process 1 delivered them in the reverse order"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker()
+			for _, e := range tt.events {
+				c.Record(e)
+			}
+			if got := c.Violations(tt.nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Checker.Violations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_Counters(t *testing.T) {
+	c := NewChecker()
+	for _, e := range []trace.Event{
+		{Kind: trace.Send, From: 0, To: 1},
+		bcast(0, "TEST_BEB", 1), bcast(0, "TEST_BEB", 1), accept(0, "TEST_BEB", 0, 1), accept(1, "TEST_BEB", 0, 1),
+		{Kind: trace.Accept, From: 1, To: 1, Message: "broken"},
+	} {
+		c.Record(e)
+	}
+	if got := c.Broadcasts(); got != 1 {
+		t.Errorf("Checker.Broadcasts() = %v, want 1", got)
+	}
+	if got := c.Deliveries(); got != 2 {
+		t.Errorf("Checker.Deliveries() = %v, want 2", got)
+	}
+}
+
+// at sets the tick of the event.
+func at(tick int64, e trace.Event) trace.Event {
+	e.Tick = tick
+	return e
+}
+
+func TestChecker_Curve(t *testing.T) {
+	c := NewChecker()
+	for _, e := range []trace.Event{
+		at(2, bcast(0, "TEST_BEB", 1)), at(2, accept(0, "TEST_BEB", 0, 1)),
+		at(5, accept(1, "TEST_BEB", 0, 1)), at(5, accept(3, "TEST_BEB", 0, 1)),
+		at(7, accept(1, "TEST_BEB", 0, 1)), at(9, accept(2, "TEST_BEB", 0, 1)),
+	} {
+		c.Record(e)
+	}
+	tests := []struct {
+		name  string
+		id    ID
+		nodes []int32
+		want  []Point
+	}{
+		{"All", ID{0, 1}, []int32{0, 1, 2, 3}, []Point{{2, 1, 0.25}, {5, 3, 0.75}, {9, 4, 1}}},
+		{"Part", ID{0, 1}, []int32{1, 2, 4, 5}, []Point{{2, 0, 0}, {5, 1, 0.25}, {9, 2, 0.5}}},
+		{"Unknown", ID{1, 1}, []int32{0, 1}, nil},
+		{"NoNodes", ID{0, 1}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Curve(tt.id, tt.nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Checker.Curve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	var b strings.Builder
+	if err := c.WriteCurves(&b, []int32{0, 1}); err != nil {
+		t.Fatalf("Checker.WriteCurves() error = %v", err)
+	}
+	want := "message,tick,informed,fraction\n0:1,2,1,0.5000\n0:1,5,2,1.0000\n"
+	if got := b.String(); got != want {
+		t.Errorf("Checker.WriteCurves() = %q, want %q", got, want)
+	}
+}
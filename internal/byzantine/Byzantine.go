@@ -0,0 +1,113 @@
+// Package byzantine implements adversary strategies of Byzantine processes.
+// The network passes every message sent by a Byzantine process to another process through its strategy,
+// which may rewrite or suppress it. Strategies rewrite the value of a message, its last integer argument
+// by convention, so that forged messages stay well-formed and reach the work functions of the receivers.
+package byzantine
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+// Names of the strategies.
+const (
+	// Random replaces the value with a random one: its lowest bit is random, so binary values are uniformly random.
+	Random = "random"
+	// Equivocate tells different receivers different values: the lowest bit of the value is flipped
+	// for receivers with odd nodes.
+	Equivocate = "equivocate"
+	// Silent suppresses all the messages.
+	Silent = "silent"
+)
+
+// Strategies returns the names of all the strategies.
+func Strategies() []string {
+	return []string{Random, Equivocate, Silent}
+}
+
+// Strategy is an adversary strategy of Byzantine processes.
+type Strategy interface {
+	// Name returns the name of the strategy.
+	Name() string
+	// Rewrite returns the body of the message sent to the receiver, nil to suppress it.
+	// The body must not be changed in place.
+	Rewrite(body []byte, to int32, rng *rand.Rand) []byte
+}
+
+// New returns the strategy by its name.
+func New(name string) (Strategy, error) {
+	switch name {
+	case Random:
+		return random{}, nil
+	case Equivocate:
+		return equivocate{}, nil
+	case Silent:
+		return silent{}, nil
+	}
+	return nil, fmt.Errorf("unknown Byzantine strategy %v", name)
+}
+
+type random struct{}
+
+func (random) Name() string {
+	return Random
+}
+
+func (random) Rewrite(body []byte, to int32, rng *rand.Rand) []byte {
+	return rewrite(body, func(v int32) int32 { return v ^ rng.Int31n(2) })
+}
+
+type equivocate struct{}
+
+func (equivocate) Name() string {
+	return Equivocate
+}
+
+func (equivocate) Rewrite(body []byte, to int32, rng *rand.Rand) []byte {
+	return rewrite(body, func(v int32) int32 { return v ^ to&1 })
+}
+
+type silent struct{}
+
+func (silent) Name() string {
+	return Silent
+}
+
+func (silent) Rewrite(body []byte, to int32, rng *rand.Rand) []byte {
+	return nil
+}
+
+// rewrite returns a copy of the message body with the value changed by the function.
+// Messages without integer arguments are not changed.
+func rewrite(body []byte, f func(int32) int32) []byte {
+	at := value(body)
+	if at < 0 {
+		return body
+	}
+	m := messages.NewMessage(-1, -1, body)
+	m.Ptr = at
+	arg := messages.NewMessageArg(f(m.GetInt32()))
+	res := append([]byte{}, body[:at]...)
+	res = append(res, arg.Body...)
+	return append(res, body[m.Ptr:]...)
+}
+
+// value returns the offset of the last integer argument in the message body, -1 if there is none.
+func value(body []byte) (res int) {
+	res = -1
+	m := messages.NewMessage(-1, -1, body)
+	defer func() {
+		if recover() != nil {
+			res = -1
+		}
+	}()
+	for m.Ptr < len(body) {
+		at := m.Ptr
+		if _, ok := m.GetData().(int32); ok {
+			res = at
+		}
+	}
+	return res
+}
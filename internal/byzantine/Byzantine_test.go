@@ -0,0 +1,67 @@
+package byzantine
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+// body creates a message body of the type with integer arguments.
+func body(t string, args ...int32) []byte {
+	res := []*messages.MessageArg{messages.NewMessageArg([]byte(t))}
+	for _, a := range args {
+		res = append(res, messages.NewMessageArg(a))
+	}
+	return messages.NewMessageByArgs(res...).Body
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Strategies() {
+		s, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%v) error = %v", name, err)
+		}
+		if got := s.Name(); got != name {
+			t.Errorf("Strategy.Name() = %v, want %v", got, name)
+		}
+	}
+	if _, err := New("lying"); err == nil {
+		t.Errorf("New(lying) error = nil, want error")
+	}
+}
+
+func TestStrategy_Rewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		body     []byte
+		to       int32
+		want     [][]byte
+	}{
+		{"EquivocateEven", Equivocate, body("A", 3, 1), 2, [][]byte{body("A", 3, 1)}},
+		{"EquivocateOdd", Equivocate, body("A", 3, 1), 1, [][]byte{body("A", 3, 0)}},
+		{"RandomBinary", Random, body("A", 7, 0), 1, [][]byte{body("A", 7, 0), body("A", 7, 1)}},
+		{"NoValue", Equivocate, body("A"), 1, [][]byte{body("A")}},
+		{"Silent", Silent, body("A", 1), 1, [][]byte{nil}},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := New(tt.strategy)
+			orig := append([]byte{}, tt.body...)
+			got := s.Rewrite(tt.body, tt.to, rng)
+			found := false
+			for _, w := range tt.want {
+				found = found || reflect.DeepEqual(got, w)
+			}
+			if !found {
+				t.Errorf("Strategy.Rewrite() = %v, want one of %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.body, orig) {
+				t.Errorf("Strategy.Rewrite() changed the body to %v", tt.body)
+			}
+		})
+	}
+}
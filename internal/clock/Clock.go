@@ -0,0 +1,215 @@
+package clock
+
+// Kind is a kind of logical clocks. Every kind maintains the clocks of the previous kinds as well:
+// vector clocks keep Lamport time and matrix clocks keep the vector clock as their own row.
+type Kind int
+
+const (
+	// None disables logical clocks.
+	None Kind = iota
+	// Lamport is a scalar Lamport clock.
+	Lamport
+	// Vector is a vector clock.
+	Vector
+	// Matrix is a matrix clock.
+	Matrix
+)
+
+var names = []string{"none", "lamport", "vector", "matrix"}
+
+// ParseKind returns the kind of clocks by its name.
+func ParseKind(name string) (Kind, bool) {
+	for i, n := range names {
+		if n == name {
+			return Kind(i), true
+		}
+	}
+	return None, false
+}
+
+func (k Kind) String() string {
+	if k < None || k > Matrix {
+		return "unknown"
+	}
+	return names[k]
+}
+
+// Stamp is a timestamp piggybacked on messages.
+type Stamp struct {
+	Lamport int64
+	Vector  []int64
+	Matrix  [][]int64
+}
+
+// Clock is a logical clock of a process. Vectors grow as new processes are heard of,
+// absent components are zeros.
+type Clock struct {
+	kind    Kind
+	node    int32
+	lamport int64
+	vector  []int64
+	matrix  [][]int64
+}
+
+// New creates a clock of the kind for the process with given node.
+func New(kind Kind, node int32) *Clock {
+	return &Clock{kind: kind, node: node}
+}
+
+// Kind returns the kind of the clock.
+func (c *Clock) Kind() Kind {
+	return c.kind
+}
+
+// Tick advances the clock by a local event.
+func (c *Clock) Tick() {
+	c.lamport++
+	if c.kind >= Vector {
+		c.vector = grow(c.vector, int(c.node)+1)
+		c.vector[c.node]++
+	}
+}
+
+// Send advances the clock by a send event and returns the timestamp for the message.
+func (c *Clock) Send() *Stamp {
+	c.Tick()
+	return c.Stamp()
+}
+
+// Receive merges the timestamp of a received message, if any, and advances the clock.
+func (c *Clock) Receive(s *Stamp) {
+	if s != nil {
+		if s.Lamport > c.lamport {
+			c.lamport = s.Lamport
+		}
+		if c.kind >= Vector {
+			c.vector = merge(c.vector, s.Vector)
+		}
+		if c.kind == Matrix {
+			c.matrix = grow2(c.matrix, len(s.Matrix))
+			for i, row := range s.Matrix {
+				c.matrix[i] = merge(c.matrix[i], row)
+			}
+		}
+	}
+	c.Tick()
+}
+
+// Stamp returns the current timestamp without advancing the clock.
+func (c *Clock) Stamp() *Stamp {
+	res := &Stamp{Lamport: c.lamport}
+	if c.kind >= Vector {
+		res.Vector = c.Vector()
+	}
+	if c.kind == Matrix {
+		res.Matrix = c.Matrix()
+	}
+	return res
+}
+
+// Time returns the Lamport time.
+func (c *Clock) Time() int64 {
+	return c.lamport
+}
+
+// Vector returns a copy of the vector clock, nil for Lamport clocks.
+func (c *Clock) Vector() []int64 {
+	if c.kind < Vector {
+		return nil
+	}
+	return append(make([]int64, 0, len(c.vector)), c.vector...)
+}
+
+// Matrix returns a copy of the matrix clock, nil for other clocks.
+// Its row i is the vector clock of the process i as known to this process, its own row is its vector clock.
+func (c *Clock) Matrix() [][]int64 {
+	if c.kind < Matrix {
+		return nil
+	}
+	c.matrix = grow2(c.matrix, int(c.node)+1)
+	res := make([][]int64, len(c.matrix))
+	for i, row := range c.matrix {
+		if i == int(c.node) {
+			row = c.vector
+		}
+		res[i] = append(make([]int64, 0, len(row)), row...)
+	}
+	return res
+}
+
+// Known returns the number of events of the process with given node known to all the processes
+// the matrix clock has heard of. It is 0 for other clocks.
+func (c *Clock) Known(node int32) int64 {
+	var res int64 = -1
+	for _, row := range c.Matrix() {
+		var v int64
+		if int(node) < len(row) {
+			v = row[node]
+		}
+		if res < 0 || v < res {
+			res = v
+		}
+	}
+	if res < 0 {
+		return 0
+	}
+	return res
+}
+
+// HappensBefore reports whether the vector timestamp a precedes b:
+// all its components are not greater and the timestamps differ.
+func HappensBefore(a []int64, b []int64) bool {
+	less := false
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := at(a, i), at(b, i)
+		if x > y {
+			return false
+		}
+		less = less || x < y
+	}
+	return less
+}
+
+// Concurrent reports whether the vector timestamps differ and neither of them precedes the other.
+func Concurrent(a []int64, b []int64) bool {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if at(a, i) != at(b, i) {
+			return !HappensBefore(a, b) && !HappensBefore(b, a)
+		}
+	}
+	return false
+}
+
+func at(v []int64, i int) int64 {
+	if i < len(v) {
+		return v[i]
+	}
+	return 0
+}
+
+// grow extends the vector with zeros up to the size.
+func grow(v []int64, size int) []int64 {
+	for len(v) < size {
+		v = append(v, 0)
+	}
+	return v
+}
+
+// grow2 extends the matrix with empty rows up to the size.
+func grow2(m [][]int64, size int) [][]int64 {
+	for len(m) < size {
+		m = append(m, nil)
+	}
+	return m
+}
+
+// merge returns the componentwise maximum of the vectors, reusing the first one.
+func merge(v []int64, u []int64) []int64 {
+	v = grow(v, len(u))
+	for i, x := range u {
+		if x > v[i] {
+			v[i] = x
+		}
+	}
+	return v
+}
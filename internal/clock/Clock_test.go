@@ -0,0 +1,88 @@
+package clock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKind(t *testing.T) {
+	for k := None; k <= Matrix; k++ {
+		if got, ok := ParseKind(k.String()); !ok || got != k {
+			t.Errorf("ParseKind(%v) = %v, %v", k, got, ok)
+		}
+	}
+	if _, ok := ParseKind("hybrid"); ok {
+		t.Errorf("ParseKind(hybrid) succeeded")
+	}
+}
+
+func TestClock(t *testing.T) {
+	tests := []struct {
+		kind       Kind
+		wantTime   int64
+		wantVector []int64
+		wantMatrix [][]int64
+	}{
+		{Lamport, 5, nil, nil},
+		{Vector, 5, []int64{3, 2}, nil},
+		{Matrix, 5, []int64{3, 2}, [][]int64{{3, 2}, {2, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			a, b := New(tt.kind, 0), New(tt.kind, 1)
+			a.Tick()
+			b.Receive(a.Send())
+			a.Receive(b.Send())
+			if got := a.Time(); got != tt.wantTime {
+				t.Errorf("Clock.Time() = %v, want %v", got, tt.wantTime)
+			}
+			if got := a.Vector(); !reflect.DeepEqual(got, tt.wantVector) {
+				t.Errorf("Clock.Vector() = %v, want %v", got, tt.wantVector)
+			}
+			if got := a.Matrix(); !reflect.DeepEqual(got, tt.wantMatrix) {
+				t.Errorf("Clock.Matrix() = %v, want %v", got, tt.wantMatrix)
+			}
+		})
+	}
+}
+
+func TestClock_Known(t *testing.T) {
+	a, b, c := New(Matrix, 0), New(Matrix, 1), New(Matrix, 2)
+	b.Receive(a.Send())
+	c.Receive(b.Send())
+	if got := c.Known(1); got != 0 {
+		t.Errorf("Clock.Known(1) = %v, want 0: process 0 does not know of events of process 1", got)
+	}
+	a.Receive(c.Send())
+	if got := a.Known(1); got != 2 {
+		t.Errorf("Clock.Known(1) = %v, want 2", got)
+	}
+	if got := New(Vector, 0).Known(0); got != 0 {
+		t.Errorf("Clock.Known() = %v for a vector clock, want 0", got)
+	}
+}
+
+func TestHappensBefore(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b       []int64
+		want       bool
+		concurrent bool
+	}{
+		{"Before", []int64{1, 0}, []int64{1, 1}, true, false},
+		{"Shorter", []int64{1}, []int64{1, 2, 0}, true, false},
+		{"Equal", []int64{1, 2}, []int64{1, 2, 0}, false, false},
+		{"After", []int64{2, 2}, []int64{1, 2}, false, false},
+		{"Concurrent", []int64{2, 0}, []int64{1, 1}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HappensBefore(tt.a, tt.b); got != tt.want {
+				t.Errorf("HappensBefore() = %v, want %v", got, tt.want)
+			}
+			if got := Concurrent(tt.a, tt.b); got != tt.concurrent {
+				t.Errorf("Concurrent() = %v, want %v", got, tt.concurrent)
+			}
+		})
+	}
+}
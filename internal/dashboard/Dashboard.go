@@ -0,0 +1,177 @@
+// Package dashboard serves a live view of a running model over HTTP: a self-contained page drawing
+// the network with the messages in flight, the sizes of the queues of the processes and the processes
+// coloured by a field of their contexts chosen on the page. The page polls the state published by the model.
+package dashboard
+
+import (
+	// embed is needed for the page embedding.
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/trmigor/distr-model/user/context"
+)
+
+//go:embed dashboard.html
+var page []byte
+
+// State is the state of the model shown by the page.
+type State struct {
+	Tick int64 `json:"tick"`
+	// TickMillis is the duration of a tick in real time in milliseconds, 0 in virtual time.
+	TickMillis int64     `json:"tickMillis"`
+	Nodes      []Node    `json:"nodes"`
+	Links      []Link    `json:"links"`
+	Messages   []Message `json:"messages"`
+}
+
+// Node is a process with the number of its pending messages and the fields of its contexts by "Key.Field".
+type Node struct {
+	Node   int32             `json:"node"`
+	Queue  int               `json:"queue"`
+	Fields map[string]string `json:"fields"`
+}
+
+// Link is a link with its latency.
+type Link struct {
+	From    int32 `json:"from"`
+	To      int32 `json:"to"`
+	Latency int32 `json:"latency"`
+}
+
+// Message is a message in flight queued at the node: the receiver or the next hop of routed messages.
+type Message struct {
+	From     int32  `json:"from"`
+	To       int32  `json:"to"`
+	Node     int32  `json:"node"`
+	Message  string `json:"message"`
+	Send     int64  `json:"send"`
+	Delivery int64  `json:"delivery"`
+}
+
+// maxField is the length formatted fields are truncated to.
+const maxField = 64
+
+// Fields formats the exported fields of the contexts by "Key.Field", fields of embedded structures
+// are promoted as in Go.
+func Fields(contexts map[string]context.Context) map[string]string {
+	res := make(map[string]string)
+	for key, c := range contexts {
+		fields(res, key, reflect.ValueOf(c))
+	}
+	return res
+}
+
+func fields(res map[string]string, key string, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		switch {
+		case f.Anonymous:
+			fields(res, key, v.Field(i))
+		case f.IsExported():
+			s := fmt.Sprintf("%v", v.Field(i).Interface())
+			if len(s) > maxField {
+				s = s[:maxField] + "..."
+			}
+			res[key+"."+f.Name] = s
+		}
+	}
+}
+
+// Server serves the page and the latest published state.
+type Server struct {
+	mutex    sync.Mutex
+	state    []byte
+	listener net.Listener
+	server   *http.Server
+}
+
+// New creates a server with an empty state.
+func New() *Server {
+	s := &Server{}
+	s.Update(State{})
+	return s
+}
+
+// Handler returns the handler of the page at / and the state at /state.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.Write(page)
+	})
+	mux.HandleFunc("/state", func(rw http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		state := s.state
+		s.mutex.Unlock()
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.Write(state)
+	})
+	return mux
+}
+
+// Start starts serving at the address, e.g. "localhost:8080", in background.
+func (s *Server) Start(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = l
+	s.server = &http.Server{Handler: s.Handler()}
+	go s.server.Serve(l)
+	return nil
+}
+
+// Addr returns the address the server listens at, empty if it is not started.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Close stops serving.
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// Update publishes the state.
+func (s *Server) Update(state State) {
+	if state.Nodes == nil {
+		state.Nodes = make([]Node, 0)
+	}
+	if state.Links == nil {
+		state.Links = make([]Link, 0)
+	}
+	if state.Messages == nil {
+		state.Messages = make([]Message, 0)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state = data
+}
@@ -0,0 +1,122 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trmigor/distr-model/user/context"
+)
+
+type base struct {
+	UID    int32
+	hidden int
+}
+
+type election struct {
+	base
+	Leader int32
+	Done   bool
+	Log    string
+}
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		contexts map[string]context.Context
+		want     map[string]string
+	}{
+		{
+			name:     "no contexts",
+			contexts: map[string]context.Context{},
+			want:     map[string]string{},
+		},
+		{
+			name: "promoted and truncated fields",
+			contexts: map[string]context.Context{
+				"Election": &election{base: base{UID: 7, hidden: 1}, Leader: 3, Log: strings.Repeat("x", 70)},
+				"Common":   &struct{}{},
+				"Nil":      (*election)(nil),
+			},
+			want: map[string]string{
+				"Election.UID":    "7",
+				"Election.Leader": "3",
+				"Election.Done":   "false",
+				"Election.Log":    strings.Repeat("x", 64) + "...",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fields(tt.contexts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	s := New()
+	h := s.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/state", nil))
+	if rec.Body.String() != `{"tick":0,"tickMillis":0,"nodes":[],"links":[],"messages":[]}` {
+		t.Errorf("Server.Handler() /state = %v, want an empty state", rec.Body.String())
+	}
+
+	want := State{
+		Tick:     5,
+		Nodes:    []Node{{Node: 0, Queue: 1, Fields: map[string]string{"LCR.Leader": "0"}}, {Node: 1, Fields: map[string]string{}}},
+		Links:    []Link{{From: 0, To: 1, Latency: 2}},
+		Messages: []Message{{From: 0, To: 1, Node: 1, Message: "HELLO", Send: 4, Delivery: 6}},
+	}
+	s.Update(want)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/state", nil))
+	var got State
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Server.Handler() /state = %v (%v), want %v", got, err, want)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<svg") {
+		t.Errorf("Server.Handler() / = %v, want the page", rec.Code)
+	}
+	for _, external := range []string{`src="http`, `href="http`, "@import"} {
+		if strings.Contains(rec.Body.String(), external) {
+			t.Errorf("Server.Handler() / loads external resources by %v", external)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Server.Handler() /missing = %v, want %v", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServer_Start(t *testing.T) {
+	s := New()
+	if err := s.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Server.Start() error = %v", err)
+	}
+	defer s.Close()
+	resp, err := http.Get("http://" + s.Addr() + "/state")
+	if err != nil {
+		t.Fatalf("GET /state error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"tick":0`) {
+		t.Errorf("GET /state = %v %s, want the state", resp.StatusCode, body)
+	}
+	if err := New().Start(s.Addr()); err == nil {
+		t.Errorf("Server.Start() at a used address error = nil, want an error")
+	}
+}
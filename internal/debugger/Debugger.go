@@ -0,0 +1,390 @@
+// Package debugger steps through model runs in virtual time interactively. The run pauses before messages
+// are handled, at every one of them or at breakpoints on processes and message types, and the user inspects
+// the contexts, the pending messages and the links, drops or delays pending messages and resumes the run.
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+// mode is the way the run goes on after a pause.
+type mode int
+
+const (
+	// stepping pauses before the next message.
+	stepping mode = iota
+	// ticking pauses before the first message of a later tick.
+	ticking
+	// running pauses at breakpoints only.
+	running
+	// detached never pauses.
+	detached
+)
+
+// breakpoint pauses the run before messages handled by the process of the node or of the type.
+type breakpoint struct {
+	node   int32
+	prefix string
+}
+
+func (b breakpoint) String() string {
+	if b.prefix != "" {
+		return "message " + b.prefix
+	}
+	return fmt.Sprintf("node %v", b.node)
+}
+
+// matches reports whether the message of the type queued at the node hits the breakpoint.
+func (b breakpoint) matches(node int32, kind string) bool {
+	if b.prefix != "" {
+		return strings.HasPrefix(kind, b.prefix)
+	}
+	return node == b.node
+}
+
+// pause is a message the run is paused before.
+type pause struct {
+	node    int32
+	message *messages.Message
+}
+
+// Debugger controls a run of a world in virtual time by commands.
+type Debugger struct {
+	w           *world.World
+	in          *bufio.Scanner
+	out         io.Writer
+	mode        mode
+	since       int64
+	breakpoints []breakpoint
+	paused      chan pause
+	resume      chan bool
+}
+
+// New creates a debugger of the world reading commands from in and writing to out.
+// The world should be in virtual time and not launched yet.
+func New(w *world.World, in io.Reader, out io.Writer) *Debugger {
+	d := &Debugger{
+		w:           w,
+		in:          bufio.NewScanner(in),
+		out:         out,
+		breakpoints: make([]breakpoint, 0),
+		paused:      make(chan pause),
+		resume:      make(chan bool),
+	}
+	w.BeforeStep(d.pause)
+	w.AddTracer(d)
+	return d
+}
+
+// Record implements trace.Tracer: the events are shown while stepping through messages or ticks.
+func (d *Debugger) Record(e trace.Event) {
+	if d.mode == stepping || d.mode == ticking {
+		fmt.Fprintf(d.out, "%v\n", e)
+	}
+}
+
+// pause blocks the run before the message until the user resumes it, if the run should pause there.
+func (d *Debugger) pause(node int32, m *messages.Message) {
+	switch d.mode {
+	case stepping:
+	case ticking:
+		if d.w.Network.Tick == d.since {
+			return
+		}
+	case running:
+		kind := string(m.Type())
+		hit := false
+		for _, b := range d.breakpoints {
+			hit = hit || b.matches(node, kind)
+		}
+		if !hit {
+			return
+		}
+	default:
+		return
+	}
+	d.paused <- pause{node: node, message: m}
+	<-d.resume
+}
+
+// Run launches the model by the function and performs the commands of the user until the quit command
+// or the end of the input. The run pauses before its first message. It returns the result of the function.
+func (d *Debugger) Run(launch func() bool) bool {
+	done := make(chan bool, 1)
+	go func() {
+		done <- launch()
+	}()
+	finished, result := false, false
+	for {
+		if !finished {
+			select {
+			case p := <-d.paused:
+				fmt.Fprintf(d.out, "paused before %v\n", format(p.node, p.message))
+			case result = <-done:
+				finished = true
+				fmt.Fprintf(d.out, "the run has finished at tick %v with %v pending messages\n",
+					d.w.Network.Tick, d.w.Pending())
+			}
+		}
+		if d.prompt(finished) {
+			continue
+		}
+		if !finished {
+			d.mode = detached
+			d.resume <- true
+			result = <-done
+		}
+		return result
+	}
+}
+
+// prompt performs commands until one resumes the run, then it returns true, or the user quits.
+// After the run has finished only inspecting commands are performed.
+func (d *Debugger) prompt(finished bool) bool {
+	for {
+		fmt.Fprintf(d.out, "[%v]> ", d.w.Network.Tick)
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			return false
+		}
+		words := strings.Fields(d.in.Text())
+		if len(words) == 0 {
+			continue
+		}
+		switch words[0] {
+		case "step", "s", "tick", "t", "continue", "c":
+			if finished {
+				fmt.Fprintln(d.out, "the run has finished")
+				continue
+			}
+			d.mode, d.since = running, d.w.Network.Tick
+			switch words[0][0] {
+			case 's':
+				d.mode = stepping
+			case 't':
+				d.mode = ticking
+			}
+			d.resume <- true
+			return true
+		case "quit", "exit":
+			return false
+		}
+		if err := d.perform(words); err != nil {
+			fmt.Fprintf(d.out, "%v\n", err)
+		}
+	}
+}
+
+// help describes the commands.
+const help = `step, s               handle the message and pause before the next one
+tick, t               continue to the first message of a later tick
+continue, c           continue to a breakpoint or the end of the run
+break node N          pause before messages handled by the process N
+break message TYPE    pause before messages whose first argument starts with TYPE
+breakpoints           list the breakpoints
+delete [I]            delete the breakpoint I or all of them
+context N [KEY]       show the contexts of the process N
+queue N               show the pending messages of the process N
+pending               show all the pending messages
+links [N]             show the links, from the process N only if given
+drop N I              drop the pending message I of the process N
+delay N I TICKS       delay the pending message I of the process N
+quit, exit            finish the run without pauses and exit
+`
+
+// perform performs an inspecting command.
+func (d *Debugger) perform(words []string) error {
+	if words[0] == "help" || words[0] == "h" {
+		fmt.Fprint(d.out, help)
+		return nil
+	}
+	if words[0] == "break" || words[0] == "b" {
+		return d.addBreakpoint(words[1:])
+	}
+	if words[0] == "context" && len(words) >= 2 {
+		node, err := parseNode(words[1])
+		if err != nil {
+			return err
+		}
+		return d.context(node, words[2:])
+	}
+	args := make([]int64, 0, len(words)-1)
+	for _, w := range words[1:] {
+		n, err := strconv.ParseInt(w, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", w)
+		}
+		args = append(args, n)
+	}
+	switch {
+	case words[0] == "breakpoints" && len(args) == 0:
+		for i, b := range d.breakpoints {
+			fmt.Fprintf(d.out, "%v. %v\n", i+1, b)
+		}
+		return nil
+	case words[0] == "delete" && len(args) <= 1:
+		return d.deleteBreakpoint(args)
+	case words[0] == "queue" && len(args) == 1:
+		return d.queue(int32(args[0]))
+	case words[0] == "pending" && len(args) == 0:
+		for _, node := range d.w.Nodes() {
+			d.queue(node)
+		}
+		return nil
+	case words[0] == "links" && len(args) <= 1:
+		d.links(args)
+		return nil
+	case words[0] == "drop" && len(args) == 2:
+		f, err := d.message(int32(args[0]), args[1])
+		if err == nil {
+			d.w.Drop(f)
+		}
+		return err
+	case words[0] == "delay" && len(args) == 3 && args[2] > 0:
+		f, err := d.message(int32(args[0]), args[1])
+		if err == nil {
+			d.w.Delay(f, args[2])
+		}
+		return err
+	}
+	return fmt.Errorf("unknown command or wrong arguments: %v, see help", strings.Join(words, " "))
+}
+
+// parseNode parses the node of a process.
+func parseNode(s string) (int32, error) {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a node", s)
+	}
+	return int32(n), nil
+}
+
+func (d *Debugger) addBreakpoint(words []string) error {
+	if len(words) != 2 {
+		return fmt.Errorf("usage: break node N or break message TYPE")
+	}
+	switch words[0] {
+	case "node":
+		n, err := parseNode(words[1])
+		if err != nil {
+			return err
+		}
+		d.breakpoints = append(d.breakpoints, breakpoint{node: n})
+	case "message":
+		d.breakpoints = append(d.breakpoints, breakpoint{prefix: words[1]})
+	default:
+		return fmt.Errorf("usage: break node N or break message TYPE")
+	}
+	fmt.Fprintf(d.out, "%v. %v\n", len(d.breakpoints), d.breakpoints[len(d.breakpoints)-1])
+	return nil
+}
+
+func (d *Debugger) deleteBreakpoint(args []int64) error {
+	if len(args) == 0 {
+		d.breakpoints = d.breakpoints[:0]
+		return nil
+	}
+	i := int(args[0]) - 1
+	if i < 0 || i >= len(d.breakpoints) {
+		return fmt.Errorf("no breakpoint %v", args[0])
+	}
+	d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+	return nil
+}
+
+// context shows the contexts of the process with the keys, all of them if none is given.
+func (d *Debugger) context(node int32, keys []string) error {
+	p := d.process(node)
+	if p == nil {
+		return fmt.Errorf("no process %v", node)
+	}
+	if len(keys) == 0 {
+		for key := range p.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	for _, key := range keys {
+		c, ok := p.Context[key]
+		if !ok {
+			return fmt.Errorf("process %v has no context %v", node, key)
+		}
+		fmt.Fprintf(d.out, "%v: %+v\n", key, c)
+	}
+	return nil
+}
+
+// queue shows the pending messages of the process numbered in order of delivery.
+func (d *Debugger) queue(node int32) error {
+	p := d.process(node)
+	if p == nil {
+		return fmt.Errorf("no process %v", node)
+	}
+	for i, m := range p.MessagesQueue.Messages() {
+		fmt.Fprintf(d.out, "%v %v. %v\n", node, i+1, format(node, m))
+	}
+	return nil
+}
+
+// links shows the links with their latencies and capacities, from the node only if given.
+func (d *Debugger) links(args []int64) {
+	g := d.w.Network.Links()
+	from := make([]int32, 0, len(g))
+	for n := range g {
+		if len(args) == 0 || int64(n) == args[0] {
+			from = append(from, n)
+		}
+	}
+	sort.Slice(from, func(i, j int) bool { return from[i] < from[j] })
+	for _, n := range from {
+		for _, to := range g.Neighbours(n) {
+			fmt.Fprintf(d.out, "%v -> %v latency %v", n, to, g[n][to])
+			if c := d.w.Network.GetCapacity(n, to); c.Bandwidth > 0 || c.Buffer > 0 {
+				fmt.Fprintf(d.out, " bandwidth %v buffer %v", c.Bandwidth, c.Buffer)
+			}
+			fmt.Fprintln(d.out)
+		}
+	}
+}
+
+// message returns the pending message of the process by its number.
+func (d *Debugger) message(node int32, i int64) (world.InFlight, error) {
+	p := d.process(node)
+	if p == nil {
+		return world.InFlight{}, fmt.Errorf("no process %v", node)
+	}
+	ms := p.MessagesQueue.Messages()
+	if i < 1 || int(i) > len(ms) {
+		return world.InFlight{}, fmt.Errorf("process %v has no pending message %v", node, i)
+	}
+	return world.InFlight{Node: node, Message: ms[i-1]}, nil
+}
+
+// process returns the process of the node, nil if there is none.
+func (d *Debugger) process(node int32) *process.Process {
+	for _, p := range d.w.ProcessesList {
+		if p != nil && p.Node == node {
+			return p
+		}
+	}
+	return nil
+}
+
+// format formats the message queued at the node.
+func format(node int32, m *messages.Message) string {
+	res := fmt.Sprintf("%v -> %v: %v (delivery at %v)", m.From, m.To, m, m.DeliveryTime)
+	if node != m.To {
+		res += fmt.Sprintf(" at %v", node)
+	}
+	return res
+}
@@ -0,0 +1,108 @@
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+type flooded struct {
+	Got bool
+}
+
+func flood(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	if !dp.IsMyMessage([]byte("FLOOD"), m.GetString()) {
+		return false
+	}
+	c := process.Ctx[*flooded](dp, "Flood")
+	if c.Got {
+		return true
+	}
+	c.Got = true
+	for _, v := range dp.Neighbours() {
+		dp.Network.SendMessage(dp.Node, v, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_MSG"))))
+	}
+	return true
+}
+
+func TestDebugger(t *testing.T) {
+	w := world.NewWithOptions(world.Options{Seed: 1})
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("FLOOD"), flood)
+	for i := int32(0); i < 3; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte("FLOOD"))
+		w.ProcessesList[i].Context["Flood"] = &flooded{}
+	}
+	for i := int32(0); i < 2; i++ {
+		w.Network.CreateLink(i, i+1, true, 2)
+	}
+	commands := []string{
+		"queue 0", "break node 2", "break message FLOOD_MSG", "breakpoints", "delete 2", "c",
+		"delay 2 1 3", "queue 2", "c", "drop 2 1", "s", "context 2", "s", "links 1", "drop 1", "help",
+	}
+	var out bytes.Buffer
+	d := New(w, strings.NewReader(strings.Join(commands, "\n")), &out)
+	ok := d.Run(func() bool {
+		w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_INIT"))))
+		w.Wait(10)
+		return true
+	})
+	if !ok {
+		t.Errorf("Debugger.Run() = false, want true")
+	}
+	for _, s := range []string{
+		"paused before -1 -> 0: FLOOD_INIT (delivery at 0)",
+		"[0] send -1 -> 0: FLOOD_INIT",
+		"0 1. -1 -> 0: FLOOD_INIT (delivery at 0)",
+		"1. node 2\n2. message FLOOD_MSG\n",
+		"paused before 1 -> 2: FLOOD_MSG (delivery at 4)",
+		"2 1. 1 -> 2: FLOOD_MSG (delivery at 7)",
+		"paused before 1 -> 2: FLOOD_MSG (delivery at 7)",
+		"the run has finished at tick 10 with 0 pending messages",
+		"Flood: &{Got:false}",
+		"the run has finished\n",
+		"1 -> 0 latency 2\n1 -> 2 latency 2\n",
+		"unknown command or wrong arguments: drop 1",
+		"step, s",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("Debugger.Run() output has no %q:\n%v", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "deliver 0 -> 1") {
+		t.Errorf("Debugger.Run() shows events while continuing:\n%v", out.String())
+	}
+}
+
+func TestDebugger_Quit(t *testing.T) {
+	w := world.NewWithOptions(world.Options{Seed: 1})
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("FLOOD"), flood)
+	for i := int32(0); i < 3; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte("FLOOD"))
+		w.ProcessesList[i].Context["Flood"] = &flooded{}
+		w.Network.CreateLink(i, (i+1)%3, true, 1)
+	}
+	var out bytes.Buffer
+	d := New(w, strings.NewReader("s\nquit\n"), &out)
+	d.Run(func() bool {
+		w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_INIT"))))
+		w.Wait(10)
+		return true
+	})
+	for _, p := range w.ProcessesList {
+		if !process.Ctx[*flooded](p, "Flood").Got {
+			t.Errorf("Debugger.Run() has not finished the run after quit")
+		}
+	}
+	if strings.Count(out.String(), "paused") != 2 {
+		t.Errorf("Debugger.Run() paused after quit:\n%v", out.String())
+	}
+}
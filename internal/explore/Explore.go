@@ -0,0 +1,306 @@
+// Package explore checks small configurations exhaustively: it enumerates the orders of deliveries of the messages
+// in flight and their losses on deterministic worlds and reports the shortest schedule violating a property.
+//
+// The exploration is a depth-first search over schedules. Every state is reached by replaying its schedule
+// on a fresh world, so work functions must be deterministic given the seed of the world. States are identified
+// by the hashes of the contexts of the processes and the messages in flight, the model time does not move.
+// Sleep sets prune the orders of independent steps (deliveries to different processes and losses),
+// which assumes work functions of different processes do not share state, such as the network random generator.
+package explore
+
+import (
+	"fmt"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/property"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+const (
+	// DefaultMaxDepth is the default limit of the length of schedules.
+	DefaultMaxDepth = 50
+	// DefaultMaxStates is the default limit of the number of states.
+	DefaultMaxStates = 100000
+)
+
+// Setup creates a world in virtual time in the initial state of the exploration, with the properties to check.
+// It must create equal worlds on every call.
+type Setup func() (*world.World, error)
+
+// Options bound the exploration.
+type Options struct {
+	// MaxDepth limits the length of schedules, 0 means DefaultMaxDepth.
+	MaxDepth int
+	// MaxStates limits the number of distinct states, 0 means DefaultMaxStates.
+	MaxStates int
+	// Losses is the number of messages between processes a schedule may lose.
+	Losses int
+	// NoReduction disables the partial-order reduction.
+	NoReduction bool
+}
+
+// Step is a step of a schedule: the message queued at the node is delivered or lost.
+type Step struct {
+	Node    int32
+	From    int32
+	To      int32
+	Message string
+	Lose    bool
+	body    string
+}
+
+func newStep(f world.InFlight, lose bool) Step {
+	return Step{
+		Node:    f.Node,
+		From:    f.Message.From,
+		To:      f.Message.To,
+		Message: f.Message.String(),
+		Lose:    lose,
+		body:    string(f.Message.Body),
+	}
+}
+
+func (s Step) String() string {
+	res := fmt.Sprintf("deliver %v -> %v: %v", s.From, s.To, s.Message)
+	if s.Lose {
+		res = fmt.Sprintf("lose %v -> %v: %v", s.From, s.To, s.Message)
+	}
+	if s.Node != s.To {
+		res += fmt.Sprintf(" (at %v)", s.Node)
+	}
+	return res
+}
+
+// message identifies the message of the step, equal messages queued at the same node are not distinguished.
+func (s Step) message() string {
+	return fmt.Sprintf("%v %v %v %q", s.Node, s.From, s.To, s.body)
+}
+
+// key identifies the step.
+func (s Step) key() string {
+	return fmt.Sprint(s.Lose, " ", s.message())
+}
+
+// matches reports whether the step delivers or loses the message.
+func (s Step) matches(f world.InFlight) bool {
+	return f.Node == s.Node && f.Message.From == s.From && f.Message.To == s.To && string(f.Message.Body) == s.body
+}
+
+// independent reports whether the steps commute and do not disable each other: they involve different messages
+// and do not change the same process.
+func independent(a Step, b Step) bool {
+	return a.message() != b.message() && (a.Node != b.Node || a.Lose || b.Lose)
+}
+
+// lossy reports whether the message may be lost: messages of the model and timeouts are not.
+func lossy(m *messages.Message) bool {
+	return m.From >= 0 && m.From != m.To
+}
+
+// Report is the outcome of an exploration.
+type Report struct {
+	// States is the number of distinct states visited.
+	States int
+	// Transitions is the number of steps performed.
+	Transitions int
+	// Complete is false if the exploration has been cut by its bounds.
+	Complete bool
+	// Schedule is the shortest schedule found violating a property, nil if there is none.
+	Schedule []Step
+	// Violation is the violated property with its counterexample.
+	Violation *property.Result
+}
+
+// visit is a visited state: the least depth it has been reached at and the steps not explored from it.
+type visit struct {
+	depth int
+	sleep map[string]Step
+}
+
+type explorer struct {
+	setup   Setup
+	options Options
+	visited map[[32]byte]*visit
+	report  *Report
+}
+
+// Explore checks the invariants in all the states reachable from the initial one by delivering and losing
+// the messages in flight in any order, and the eventual properties in the final states, where no messages
+// are left. It returns the shortest schedule violating any of them within the bounds.
+func Explore(setup Setup, o Options) (*Report, error) {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	if o.MaxStates <= 0 {
+		o.MaxStates = DefaultMaxStates
+	}
+	e := &explorer{
+		setup:   setup,
+		options: o,
+		visited: make(map[[32]byte]*visit),
+		report:  &Report{Complete: true},
+	}
+	if err := e.dfs(nil, 0, map[string]Step{}); err != nil {
+		return nil, err
+	}
+	return e.report, nil
+}
+
+// Replay performs the schedule on the world.
+func Replay(w *world.World, schedule []Step) error {
+	for i, s := range schedule {
+		found := false
+		for _, f := range w.InFlight() {
+			if s.matches(f) {
+				if s.Lose {
+					found = w.Lose(f)
+				} else {
+					found = w.Deliver(f)
+				}
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("step %v: no message to %v, the world is not deterministic", i+1, s)
+		}
+	}
+	return nil
+}
+
+// violation returns the outcome of the first violated property, if any.
+func violation(w *world.World) (property.Result, bool) {
+	for _, r := range w.Properties() {
+		if !r.OK {
+			return r, true
+		}
+	}
+	return property.Result{}, false
+}
+
+func (e *explorer) dfs(schedule []Step, losses int, sleep map[string]Step) error {
+	w, err := e.setup()
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+	if err := Replay(w, schedule); err != nil {
+		return err
+	}
+	if r, ok := violation(w); ok {
+		e.found(schedule, r)
+		return nil
+	}
+
+	enabled := e.enabled(w, losses)
+	if len(enabled) == 0 {
+		w.Stop()
+		if r, ok := violation(w); ok {
+			e.found(schedule, r)
+		}
+		return nil
+	}
+	limit := e.options.MaxDepth
+	if e.report.Schedule != nil && len(e.report.Schedule)-1 < limit {
+		limit = len(e.report.Schedule) - 1
+	} else if len(schedule) >= limit {
+		e.report.Complete = false
+	}
+	if len(schedule) >= limit {
+		return nil
+	}
+
+	explored := make([]Step, 0, len(enabled))
+	h := hash(w, e.options.Losses-losses)
+	v, ok := e.visited[h]
+	switch {
+	case !ok && len(e.visited) >= e.options.MaxStates:
+		e.report.Complete = false
+		return nil
+	case !ok || len(schedule) < v.depth:
+		if !ok {
+			e.report.States++
+		}
+		e.visited[h] = &visit{depth: len(schedule), sleep: sleep}
+		for _, s := range enabled {
+			if _, asleep := sleep[s.key()]; !asleep {
+				explored = append(explored, s)
+			}
+		}
+	default:
+		// The state has been explored at a lesser depth, only the steps asleep then and awake now are left.
+		left := make(map[string]Step)
+		for key, s := range v.sleep {
+			if _, asleep := sleep[key]; asleep {
+				left[key] = s
+			}
+		}
+		for _, s := range enabled {
+			_, before := v.sleep[s.key()]
+			_, now := sleep[s.key()]
+			if before && !now {
+				explored = append(explored, s)
+			}
+		}
+		v.sleep = left
+	}
+
+	done := make(map[string]Step, len(sleep))
+	for key, s := range sleep {
+		done[key] = s
+	}
+	for _, s := range explored {
+		next := make(map[string]Step)
+		if !e.options.NoReduction {
+			for key, d := range done {
+				if independent(d, s) {
+					next[key] = d
+				}
+			}
+		}
+		e.report.Transitions++
+		lost := losses
+		if s.Lose {
+			lost++
+		}
+		if err := e.dfs(append(append([]Step{}, schedule...), s), lost, next); err != nil {
+			return err
+		}
+		done[s.key()] = s
+	}
+	return nil
+}
+
+// enabled returns the steps possible in the state of the world: deliveries of the messages in flight,
+// only the first ones of every link in FIFO networks, and their losses while the schedule may lose messages.
+func (e *explorer) enabled(w *world.World, losses int) []Step {
+	res := make([]Step, 0)
+	seen := make(map[string]bool)
+	links := make(map[[3]int32]bool)
+	for _, f := range w.InFlight() {
+		if w.Network.FIFO && f.Message.From >= 0 {
+			link := [3]int32{f.Node, f.Message.From, f.Message.To}
+			if links[link] {
+				continue
+			}
+			links[link] = true
+		}
+		s := newStep(f, false)
+		if seen[s.key()] {
+			continue
+		}
+		seen[s.key()] = true
+		res = append(res, s)
+		if losses < e.options.Losses && lossy(f.Message) {
+			res = append(res, newStep(f, true))
+		}
+	}
+	return res
+}
+
+// found records the violation if its schedule is the shortest one.
+func (e *explorer) found(schedule []Step, r property.Result) {
+	if e.report.Schedule == nil || len(schedule) < len(e.report.Schedule) {
+		e.report.Schedule = append([]Step{}, schedule...)
+		e.report.Violation = &r
+	}
+}
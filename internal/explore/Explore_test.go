@@ -0,0 +1,173 @@
+package explore
+
+import (
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/property"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+type coordinator struct {
+	Holder  int32
+	Waiting []int32
+}
+
+type client struct {
+	InCS bool
+	Done bool
+}
+
+func send(dp *process.Process, to int32, body string) {
+	dp.Network.SendMessage(dp.Node, to, messages.NewMessageByArgs(messages.NewMessageArg([]byte(body))))
+}
+
+// mutex grants the critical section to clients 1 and 2 by coordinator 0, which does not wait for releases if buggy.
+func mutex(buggy bool) process.WorkFunction {
+	return func(dp *process.Process, m *messages.Message) bool {
+		m.Ptr = 0
+		if !dp.IsMyMessage([]byte("MUTEX"), m.GetString()) {
+			return false
+		}
+		m.Ptr = 0
+		switch string(m.GetString()) {
+		case "MUTEX_START":
+			send(dp, 0, "MUTEX_CLAIM")
+		case "MUTEX_CLAIM":
+			c := process.Ctx[*coordinator](dp, "Coordinator")
+			if c.Holder >= 0 && !buggy {
+				c.Waiting = append(c.Waiting, m.From)
+				return true
+			}
+			c.Holder = m.From
+			send(dp, m.From, "MUTEX_GRANT")
+		case "MUTEX_GRANT":
+			process.Ctx[*client](dp, "Client").InCS = true
+			dp.Network.SendTimeout(dp.Node, 1, messages.NewMessageByArgs(messages.NewMessageArg([]byte("MUTEX_EXIT"))))
+		case "MUTEX_EXIT":
+			c := process.Ctx[*client](dp, "Client")
+			c.InCS, c.Done = false, true
+			send(dp, 0, "MUTEX_RELEASE")
+		case "MUTEX_RELEASE":
+			c := process.Ctx[*coordinator](dp, "Coordinator")
+			c.Holder = -1
+			if len(c.Waiting) > 0 {
+				c.Holder, c.Waiting = c.Waiting[0], c.Waiting[1:]
+				send(dp, c.Holder, "MUTEX_GRANT")
+			}
+		}
+		return true
+	}
+}
+
+func setup(buggy bool) Setup {
+	return func() (*world.World, error) {
+		w := world.NewWithOptions(world.Options{Seed: 1})
+		w.RegisterWorkFunction([]byte("MUTEX"), mutex(buggy))
+		for i := int32(0); i < 3; i++ {
+			w.CreateProcess(i)
+			w.AssignWorkFunction(i, []byte("MUTEX"))
+		}
+		for i := int32(1); i < 3; i++ {
+			w.Network.CreateLink(0, i, true, 1)
+			w.ProcessesList[i].Context["Client"] = &client{}
+		}
+		w.ProcessesList[0].Context["Coordinator"] = &coordinator{Holder: -1}
+		exclusive, err := property.Parse("atmost 1 Client.InCS")
+		if err != nil {
+			return nil, err
+		}
+		done, err := property.Parse("all Client.Done")
+		if err != nil {
+			return nil, err
+		}
+		w.Invariant("exclusive", exclusive)
+		w.Eventually("done", 0, done)
+		for i := int32(1); i < 3; i++ {
+			w.Network.SendMessage(-1, i, messages.NewMessageByArgs(messages.NewMessageArg([]byte("MUTEX_START"))))
+		}
+		return w, nil
+	}
+}
+
+func TestExplore(t *testing.T) {
+	tests := []struct {
+		name      string
+		buggy     bool
+		options   Options
+		violation string
+		steps     int
+		lose      bool
+	}{
+		{"Correct", false, Options{}, "", 0, false},
+		{"Correct without reduction", false, Options{NoReduction: true}, "", 0, false},
+		{"Buggy", true, Options{}, "exclusive", 6, false},
+		{"Buggy without reduction", true, Options{NoReduction: true}, "exclusive", 6, false},
+		{"Loss", false, Options{Losses: 1}, "done", 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Explore(setup(tt.buggy), tt.options)
+			if err != nil {
+				t.Fatalf("Explore() error = %v", err)
+			}
+			if !r.Complete || r.States == 0 {
+				t.Errorf("Explore() = %+v, want a complete exploration", r)
+			}
+			if tt.violation == "" {
+				if r.Violation != nil {
+					t.Errorf("Explore() violation = %v with %v, want none", r.Violation, r.Schedule)
+				}
+				return
+			}
+			if r.Violation == nil || r.Violation.Name != tt.violation || len(r.Schedule) != tt.steps {
+				t.Fatalf("Explore() violation = %v with %v, want %v in %v steps", r.Violation, r.Schedule, tt.violation, tt.steps)
+			}
+			lose := false
+			for _, s := range r.Schedule {
+				lose = lose || s.Lose
+			}
+			if lose != tt.lose {
+				t.Errorf("Explore() schedule = %v, losses %v, want %v", r.Schedule, lose, tt.lose)
+			}
+
+			w, _ := setup(tt.buggy)()
+			defer w.Stop()
+			if err := Replay(w, r.Schedule); err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if tt.violation == "done" {
+				w.Stop()
+			}
+			if v, ok := violation(w); !ok || v.Name != tt.violation {
+				t.Errorf("Replay() does not violate %v", tt.violation)
+			}
+		})
+	}
+
+	reduced, _ := Explore(setup(false), Options{})
+	full, _ := Explore(setup(false), Options{NoReduction: true})
+	if reduced.Transitions >= full.Transitions {
+		t.Errorf("Explore() made %v transitions with reduction, %v without", reduced.Transitions, full.Transitions)
+	}
+	bounded, _ := Explore(setup(false), Options{MaxDepth: 4})
+	if bounded.Complete {
+		t.Errorf("Explore() with MaxDepth 4 is complete")
+	}
+}
+
+func TestStep_String(t *testing.T) {
+	tests := []struct {
+		step Step
+		want string
+	}{
+		{Step{Node: 1, From: 0, To: 1, Message: "PING"}, "deliver 0 -> 1: PING"},
+		{Step{Node: 1, From: 0, To: 2, Message: "PING", Lose: true}, "lose 0 -> 2: PING (at 1)"},
+	}
+	for _, tt := range tests {
+		if got := tt.step.String(); got != tt.want {
+			t.Errorf("Step.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package explore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+// hash identifies the state of the world: the contexts of the processes, the messages in flight,
+// as a multiset or, in FIFO networks, as sequences per link, and the number of messages left to lose.
+func hash(w *world.World, losses int) [32]byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "losses %v\n", losses)
+	for _, p := range w.ProcessesList {
+		if p == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "process %v\n", p.Node)
+		keys := make([]string, 0, len(p.Context))
+		for key := range p.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%v=", key)
+			encode(&b, reflect.ValueOf(p.Context[key]), make(map[uintptr]bool))
+			b.WriteByte('\n')
+		}
+	}
+
+	type flight struct {
+		node, from, to int32
+		s              string
+	}
+	flights := make([]flight, 0)
+	for _, f := range w.InFlight() {
+		flights = append(flights, flight{f.Node, f.Message.From, f.Message.To, fmt.Sprintf("%q", f.Message.Body)})
+	}
+	sort.SliceStable(flights, func(i, j int) bool {
+		a, c := flights[i], flights[j]
+		if a.node != c.node {
+			return a.node < c.node
+		}
+		if a.from != c.from {
+			return a.from < c.from
+		}
+		if a.to != c.to {
+			return a.to < c.to
+		}
+		return !w.Network.FIFO && a.s < c.s
+	})
+	for _, f := range flights {
+		fmt.Fprintf(&b, "message %v %v %v %v\n", f.node, f.from, f.to, f.s)
+	}
+	return sha256.Sum256(b.Bytes())
+}
+
+// encode writes the value canonically: pointers are followed, maps are sorted by their encoded keys,
+// unexported fields are included. Pointers already being encoded are written as cycles.
+func encode(b *bytes.Buffer, v reflect.Value, visiting map[uintptr]bool) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		fmt.Fprint(b, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprint(b, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprint(b, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprint(b, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		fmt.Fprint(b, v.Complex())
+	case reflect.String:
+		fmt.Fprintf(b, "%q", v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		if visiting[v.Pointer()] {
+			b.WriteString("cycle")
+			return
+		}
+		visiting[v.Pointer()] = true
+		b.WriteByte('&')
+		encode(b, v.Elem(), visiting)
+		delete(visiting, v.Pointer())
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		encode(b, v.Elem(), visiting)
+	case reflect.Struct:
+		fmt.Fprintf(b, "%v{", v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(b, "%v:", v.Type().Field(i).Name)
+			encode(b, v.Field(i), visiting)
+			b.WriteByte(' ')
+		}
+		b.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			encode(b, v.Index(i), visiting)
+			b.WriteByte(' ')
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		entries := make([]string, 0, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			var e bytes.Buffer
+			encode(&e, it.Key(), visiting)
+			e.WriteByte(':')
+			encode(&e, it.Value(), visiting)
+			entries = append(entries, e.String())
+		}
+		sort.Strings(entries)
+		fmt.Fprintf(b, "map%v", entries)
+	default:
+		// Functions, channels and unsafe pointers do not make up the state.
+		fmt.Fprintf(b, "%v", v.Kind())
+	}
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Level is a logging level.
+type Level int
+
+const (
+	// Error level logs only errors.
+	Error Level = iota
+	// Info level logs errors and general information about the run.
+	Info
+	// Debug level logs everything, including each event of the run.
+	Debug
+)
+
+var names = map[string]Level{
+	"error": Error,
+	"info":  Info,
+	"debug": Debug,
+}
+
+var (
+	level  = Info
+	logger = log.New(os.Stderr, "", 0)
+)
+
+// ParseLevel checks the level name.
+func ParseLevel(name string) (Level, bool) {
+	l, ok := names[name]
+	return l, ok
+}
+
+// SetLevel sets the current logging level.
+func SetLevel(l Level) {
+	level = l
+}
+
+// SetOutput sets the destination of logs, which is stderr by default.
+func SetOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
+
+// Enabled reports whether messages of the level are logged.
+func Enabled(l Level) bool {
+	return l <= level
+}
+
+func logf(l Level, prefix string, format string, a ...interface{}) {
+	if Enabled(l) {
+		logger.Print(prefix + fmt.Sprintf(format, a...))
+	}
+}
+
+// Errorf logs an error.
+func Errorf(format string, a ...interface{}) {
+	logf(Error, "error: ", format, a...)
+}
+
+// Infof logs general information.
+func Infof(format string, a ...interface{}) {
+	logf(Info, "", format, a...)
+}
+
+// Debugf logs debugging information.
+func Debugf(format string, a ...interface{}) {
+	logf(Debug, "debug: ", format, a...)
+}
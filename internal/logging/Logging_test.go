@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		level Level
+		want  string
+	}{
+		{"error", Error, "error: e\n"},
+		{"info", Info, "error: e\ni\n"},
+		{"debug", Debug, "error: e\ni\ndebug: d\n"},
+	}
+	defer SetLevel(Info)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			SetOutput(&b)
+			l, ok := ParseLevel(tt.name)
+			if !ok || l != tt.level {
+				t.Fatalf("ParseLevel() = %v, %v, want %v", l, ok, tt.level)
+			}
+			SetLevel(l)
+			Errorf("%v", "e")
+			Infof("%v", "i")
+			Debugf("%v", "d")
+			if got := b.String(); got != tt.want {
+				t.Errorf("logged %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, ok := ParseLevel("verbose"); ok {
+		t.Errorf("ParseLevel(verbose) succeeded")
+	}
+}
@@ -1,6 +1,16 @@
 package messages
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trmigor/distr-model/internal/clock"
+)
+
 // Message type represents a message between processes.
+// Stamp is the logical timestamp of the sender, if logical clocks are enabled in the network.
+// Hops is the number of links a routed message has passed, DeliveryTime of such a message is the time
+// it reaches the next hop.
 type Message struct {
 	SendTime     int64
 	DeliveryTime int64
@@ -8,6 +18,8 @@ type Message struct {
 	To           int32
 	Ptr          int
 	Body         []byte
+	Stamp        *clock.Stamp
+	Hops         int
 }
 
 // NewMessage creates new instance of Message type by sender number, receiver number and body.
@@ -33,6 +45,15 @@ func NewMessageByArgs(args ...*MessageArg) *Message {
 	return msg
 }
 
+// NewMessageByType creates new instance of Message type by its type, the first argument, and integer arguments.
+func NewMessageByType(t string, args ...int32) *Message {
+	res := []*MessageArg{NewMessageArg([]byte(t))}
+	for _, a := range args {
+		res = append(res, NewMessageArg(a))
+	}
+	return NewMessageByArgs(res...)
+}
+
 // GetInt32 extracts the earliest message argument that is not yet extracted if it is of type int32.
 // If it is not or there is nothing to extract, panics.
 func (msg *Message) GetInt32() int32 {
@@ -48,6 +69,15 @@ func (msg *Message) GetInt32() int32 {
 	panic("Expected int32")
 }
 
+// GetInt32s extracts all the message arguments that are not yet extracted, they must be of type int32.
+func (msg *Message) GetInt32s() []int32 {
+	res := make([]int32, 0)
+	for msg.Ptr < len(msg.Body) {
+		res = append(res, msg.GetInt32())
+	}
+	return res
+}
+
 // GetInt64 extracts the earliest message argument that is not yet extracted if it is of type int64.
 // If it is not or there is nothing to extract, panics.
 func (msg *Message) GetInt64() int64 {
@@ -101,3 +131,41 @@ func (msg *Message) GetData() interface{} {
 func Greater(first *Message, second *Message) bool {
 	return first.DeliveryTime > second.DeliveryTime
 }
+
+// Args decodes all the message arguments without moving the read pointer.
+// If data is broken, the arguments decoded before are returned.
+func (msg *Message) Args() (args []interface{}) {
+	m := *msg
+	m.Ptr = 0
+	defer func() {
+		_ = recover()
+	}()
+	for a := m.GetData(); a != nil; a = m.GetData() {
+		args = append(args, a)
+	}
+	return args
+}
+
+// Type returns the first message argument if it is of type []byte, which is the message type by convention.
+func (msg *Message) Type() []byte {
+	if len(msg.Body) > 0 && msg.Body[0] == StringType {
+		m := *msg
+		m.Ptr = 0
+		return m.GetString()
+	}
+	return nil
+}
+
+// String formats the message arguments separated by spaces.
+func (msg *Message) String() string {
+	args := msg.Args()
+	res := make([]string, 0, len(args))
+	for _, a := range args {
+		if b, ok := a.([]byte); ok {
+			res = append(res, string(b))
+		} else {
+			res = append(res, fmt.Sprint(a))
+		}
+	}
+	return strings.Join(res, " ")
+}
@@ -2,15 +2,19 @@ package messages
 
 import (
 	"container/heap"
+	"sort"
 	"sync"
 
 	"github.com/trmigor/distr-model/pkg/priorityq"
 )
 
 // A MessageQueue is a PriorityQueue structure with mutex for parallel usage.
+// Messages are ordered by delivery time, messages with the same delivery time are kept in order of arrival.
 type MessageQueue struct {
 	queue priorityq.PriorityQueue
 	mutex sync.Mutex
+	count int
+	max   int
 }
 
 // NewMessageQueue establishes the heap invariants.
@@ -27,10 +31,15 @@ func (mq *MessageQueue) Enqueue(msg *Message) {
 
 	item := priorityq.Item{
 		Value:    msg,
-		Priority: int(msg.DeliveryTime),
+		Priority: -int(msg.DeliveryTime),
+		Order:    mq.count,
 	}
+	mq.count++
 
 	heap.Push(&mq.queue, &item)
+	if mq.queue.Len() > mq.max {
+		mq.max = mq.queue.Len()
+	}
 }
 
 // Dequeue removes and returns the object of the priority queue with the minimum priority.
@@ -38,18 +47,52 @@ func (mq *MessageQueue) Dequeue() *Message {
 	mq.mutex.Lock()
 	defer mq.mutex.Unlock()
 
-	ret := mq.queue.Top().(*priorityq.Item).Value.(*Message)
-	heap.Pop(&mq.queue)
-
-	return ret
+	return heap.Pop(&mq.queue).(*priorityq.Item).Value.(*Message)
 }
 
 // Peek returns the object of the priority queue with the minimum priority without removing it.
 func (mq *MessageQueue) Peek() *Message {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
 	return mq.queue.Top().(*priorityq.Item).Value.(*Message)
 }
 
 // Size gets the number of elements contained in the priority queue.
 func (mq *MessageQueue) Size() int {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
 	return mq.queue.Len()
 }
+
+// MaxSize gets the greatest number of elements the priority queue has contained.
+func (mq *MessageQueue) MaxSize() int {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+	return mq.max
+}
+
+// Messages returns the messages of the priority queue in the order they would be dequeued.
+func (mq *MessageQueue) Messages() []*Message {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+	items := append(priorityq.PriorityQueue{}, mq.queue...)
+	sort.Slice(items, func(i, j int) bool { return items.Less(i, j) })
+	res := make([]*Message, 0, len(items))
+	for _, item := range items {
+		res = append(res, item.Value.(*Message))
+	}
+	return res
+}
+
+// Remove removes the message from the priority queue. It returns false if the queue has no such message.
+func (mq *MessageQueue) Remove(msg *Message) bool {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+	for _, item := range mq.queue {
+		if item.Value.(*Message) == msg {
+			heap.Remove(&mq.queue, item.Index)
+			return true
+		}
+	}
+	return false
+}
@@ -57,6 +57,7 @@ func TestMessageQueue_Dequeue(t *testing.T) {
 	}{
 		{"One", []*Message{{DeliveryTime: 123}}, Message{DeliveryTime: 123}},
 		{"Multiple", []*Message{{DeliveryTime: 456}, {DeliveryTime: 123}}, Message{DeliveryTime: 123}},
+		{"Many", []*Message{{DeliveryTime: 5}, {DeliveryTime: 1}, {DeliveryTime: 9}, {DeliveryTime: 3}, {DeliveryTime: 7}}, Message{DeliveryTime: 1}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -93,3 +94,52 @@ func TestMessageQueue_Size(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageQueue_MaxSize(t *testing.T) {
+	mq := NewMessageQueue()
+	mq.Enqueue(&Message{DeliveryTime: 1})
+	mq.Enqueue(&Message{DeliveryTime: 2})
+	mq.Dequeue()
+	mq.Enqueue(&Message{DeliveryTime: 3})
+	mq.Dequeue()
+	mq.Dequeue()
+	if got := mq.MaxSize(); got != 2 {
+		t.Errorf("MessageQueue.MaxSize() = %v, want 2", got)
+	}
+}
+
+func TestMessageQueue_Order(t *testing.T) {
+	mq := NewMessageQueue()
+	times := []int64{5, 1, 9, 3, 7, 3, 1, 5}
+	for i, d := range times {
+		mq.Enqueue(&Message{DeliveryTime: d, From: int32(i)})
+	}
+	prev := &Message{DeliveryTime: -1}
+	for mq.Size() > 0 {
+		m := mq.Dequeue()
+		if m.DeliveryTime < prev.DeliveryTime || (m.DeliveryTime == prev.DeliveryTime && m.From < prev.From) {
+			t.Errorf("MessageQueue.Dequeue(): %+v after %+v", *m, *prev)
+		}
+		prev = m
+	}
+}
+
+func TestMessageQueue_Remove(t *testing.T) {
+	mq := NewMessageQueue()
+	ms := []*Message{{DeliveryTime: 3}, {DeliveryTime: 1}, {DeliveryTime: 2}, {DeliveryTime: 1}}
+	for _, m := range ms {
+		mq.Enqueue(m)
+	}
+	if got := mq.Messages(); !reflect.DeepEqual(got, []*Message{ms[1], ms[3], ms[2], ms[0]}) || got[0] != ms[1] {
+		t.Errorf("MessageQueue.Messages() = %v, want in order of delivery and arrival", got)
+	}
+	if !mq.Remove(ms[2]) || mq.Remove(ms[2]) || mq.Remove(&Message{}) {
+		t.Errorf("MessageQueue.Remove() removes messages not in the queue")
+	}
+	want := []*Message{ms[1], ms[3], ms[0]}
+	for i, w := range want {
+		if got := mq.Dequeue(); got != w {
+			t.Errorf("MessageQueue.Dequeue() #%v = %v, want %v", i, got, w)
+		}
+	}
+}
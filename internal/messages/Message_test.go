@@ -70,6 +70,30 @@ func TestNewMessageByArgs(t *testing.T) {
 	}
 }
 
+func TestNewMessageByType(t *testing.T) {
+	tests := []struct {
+		name string
+		t    string
+		args []int32
+		want *Message
+	}{
+		{"Type", "PING", nil, NewMessageByArgs(NewMessageArg([]byte("PING")))},
+		{"Args", "PING", []int32{1, -2}, NewMessageByArgs(NewMessageArg([]byte("PING")), NewMessageArg(int32(1)), NewMessageArg(int32(-2)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMessageByType(tt.t, tt.args...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewMessageByType() = %v, want %v", got, tt.want)
+			}
+			got.GetString()
+			if args := got.GetInt32s(); len(args) != len(tt.args) || len(args) > 0 && !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("Message.GetInt32s() = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
 func TestMessage_GetInt32(t *testing.T) {
 	type fields struct {
 		Body []byte
@@ -219,3 +243,31 @@ func TestGreater(t *testing.T) {
 		})
 	}
 }
+
+func TestMessage_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *Message
+		want     string
+		wantType []byte
+	}{
+		{"Args", NewMessageByArgs(NewMessageArg([]byte("SETX_SET")), NewMessageArg(int32(5)), NewMessageArg(int64(-1))), "SETX_SET 5 -1", []byte("SETX_SET")},
+		{"NoType", NewMessageByArgs(NewMessageArg(int32(5))), "5", nil},
+		{"Broken", &Message{Body: append(append([]byte("CA"), 0), 64)}, "A", []byte("A")},
+		{"Empty", &Message{}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.msg.Ptr = 1
+			if got := tt.msg.String(); got != tt.want {
+				t.Errorf("Message.String() = %q, want %q", got, tt.want)
+			}
+			if got := tt.msg.Type(); !reflect.DeepEqual(got, tt.wantType) {
+				t.Errorf("Message.Type() = %q, want %q", got, tt.wantType)
+			}
+			if tt.msg.Ptr != 1 {
+				t.Errorf("Message.String() moved the read pointer")
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package metrics collects the metrics of model runs: message and time complexity and loads of processes and links.
+//
+// The collector is a tracer counting sends, deliveries, drops and forwards of messages and the loads of the links.
+// Every network has one, all the message counts of the model are taken from it. The world completes its metrics
+// by the counters of the processes: unhandled messages and the greatest lengths of their queues.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Process is the load of a process. Sent and Bytes count the messages sent to other processes,
+// Local counts timers and messages sent by the process to itself, Unhandled counts the delivered messages no work function has accepted,
+// MaxQueue is the greatest number of messages waiting in the queue of the process.
+type Process struct {
+	Node      int32 `json:"node"`
+	Sent      int64 `json:"sent"`
+	Bytes     int64 `json:"bytes"`
+	Local     int64 `json:"local"`
+	Delivered int64 `json:"delivered"`
+	Unhandled int64 `json:"unhandled"`
+	MaxQueue  int   `json:"maxQueue"`
+}
+
+// Link is the load of a link: the messages sent or forwarded over it.
+type Link struct {
+	From     int32 `json:"from"`
+	To       int32 `json:"to"`
+	Messages int64 `json:"messages"`
+	Bytes    int64 `json:"bytes"`
+}
+
+// Metrics are the metrics of a run. Sent and Bytes count the messages sent by processes to other processes
+// and accepted by the network, which is the message complexity of the run, Local counts timers and messages
+// sent by processes to themselves and Injected counts messages sent by the model. Dropped counts messages
+// by the reasons of drops: messages lost to the error rate are counted in Dropped["loss"] and not in Sent,
+// Local or Injected, so the attempts to send messages are Sent plus the losses. LastActivity is the tick
+// of the last event of the run, which is its time complexity, Ticks is the tick the model has stopped at.
+type Metrics struct {
+	Sent         int64            `json:"sent"`
+	Bytes        int64            `json:"bytes"`
+	Local        int64            `json:"local"`
+	Injected     int64            `json:"injected"`
+	Forwarded    int64            `json:"forwarded"`
+	Delivered    int64            `json:"delivered"`
+	Dropped      map[string]int64 `json:"dropped"`
+	Unhandled    int64            `json:"unhandled"`
+	LastActivity int64            `json:"lastActivity"`
+	Ticks        int64            `json:"ticks"`
+	Processes    []Process        `json:"processes"`
+	Links        []Link           `json:"links"`
+}
+
+// Drops returns the number of dropped messages.
+func (m *Metrics) Drops() int64 {
+	var res int64
+	for _, n := range m.Dropped {
+		res += n
+	}
+	return res
+}
+
+// Sends returns the number of all sent messages, including local and injected ones.
+func (m *Metrics) Sends() int64 {
+	return m.Sent + m.Local + m.Injected
+}
+
+// Sends returns the number of all messages sent by the process, including local ones.
+func (p *Process) Sends() int64 {
+	return p.Sent + p.Local
+}
+
+// Process returns the load of the process, adding it if there is none.
+func (m *Metrics) Process(node int32) *Process {
+	i := sort.Search(len(m.Processes), func(i int) bool { return m.Processes[i].Node >= node })
+	if i == len(m.Processes) || m.Processes[i].Node != node {
+		m.Processes = append(m.Processes, Process{})
+		copy(m.Processes[i+1:], m.Processes[i:])
+		m.Processes[i] = Process{Node: node}
+	}
+	return &m.Processes[i]
+}
+
+// WriteJSON writes the metrics as an indented JSON object.
+func (m *Metrics) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// WriteTable writes the metrics as tables: the totals, the loads of the processes and the loads of the links.
+func (m *Metrics) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "sent\tbytes\tlocal\tinjected\tforwarded\tdelivered\tdropped\tunhandled\tlast activity\tticks\t")
+	fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t\n", m.Sent, m.Bytes, m.Local, m.Injected,
+		m.Forwarded, m.Delivered, m.Drops(), m.Unhandled, m.LastActivity, m.Ticks)
+	if len(m.Dropped) > 0 {
+		reasons := make([]string, 0, len(m.Dropped))
+		for r := range m.Dropped {
+			reasons = append(reasons, r)
+		}
+		sort.Strings(reasons)
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "drop reason\tmessages\t")
+		for _, r := range reasons {
+			fmt.Fprintf(tw, "%v\t%v\t\n", r, m.Dropped[r])
+		}
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "process\tsent\tbytes\tlocal\tdelivered\tunhandled\tmax queue\t")
+	for _, p := range m.Processes {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t\n", p.Node, p.Sent, p.Bytes, p.Local, p.Delivered, p.Unhandled,
+			p.MaxQueue)
+	}
+	if len(m.Links) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "link\tmessages\tbytes\t")
+		for _, l := range m.Links {
+			fmt.Fprintf(tw, "%v->%v\t%v\t%v\t\n", l.From, l.To, l.Messages, l.Bytes)
+		}
+	}
+	return tw.Flush()
+}
+
+// Collector is a tracer collecting the metrics of a run by its events and the hops of messages over links.
+type Collector struct {
+	mutex   sync.Mutex
+	metrics Metrics
+	links   map[[2]int32]Link
+}
+
+// NewCollector creates a collector without events.
+func NewCollector() *Collector {
+	return &Collector{metrics: Metrics{Dropped: make(map[string]int64)}, links: make(map[[2]int32]Link)}
+}
+
+// Record implements trace.Tracer.
+func (c *Collector) Record(e trace.Event) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	m := &c.metrics
+	switch e.Kind {
+	case trace.Send:
+		switch {
+		case e.From < 0:
+			m.Injected++
+		case e.From == e.To:
+			m.Local++
+			m.Process(e.From).Local++
+		default:
+			m.Sent++
+			m.Bytes += int64(e.Size)
+			p := m.Process(e.From)
+			p.Sent++
+			p.Bytes += int64(e.Size)
+		}
+	case trace.Forward:
+		m.Forwarded++
+	case trace.Deliver:
+		m.Delivered++
+		m.Process(e.To).Delivered++
+	case trace.Drop:
+		m.Dropped[e.Reason]++
+	default:
+		return
+	}
+	if e.Tick > m.LastActivity {
+		m.LastActivity = e.Tick
+	}
+}
+
+// Carry counts the message of the size passed over the link, either sent or forwarded.
+func (c *Collector) Carry(from int32, to int32, size int) {
+	if from < 0 || from == to {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	l := c.links[[2]int32{from, to}]
+	l.From, l.To = from, to
+	l.Messages++
+	l.Bytes += int64(size)
+	c.links[[2]int32{from, to}] = l
+}
+
+// Sent returns the number of messages sent by the processes to each other, the message complexity of the run.
+// Messages lost to the error rate are not counted.
+func (c *Collector) Sent() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.metrics.Sent
+}
+
+// Sends returns the number of all messages sent by the process with given node, including local ones.
+func (c *Collector) Sends(node int32) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ps := c.metrics.Processes
+	i := sort.Search(len(ps), func(i int) bool { return ps[i].Node >= node })
+	if i == len(ps) || ps[i].Node != node {
+		return 0
+	}
+	return ps[i].Sends()
+}
+
+// Metrics returns a copy of the metrics collected so far.
+func (c *Collector) Metrics() *Metrics {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := c.metrics
+	res.Dropped = make(map[string]int64, len(c.metrics.Dropped))
+	for r, n := range c.metrics.Dropped {
+		res.Dropped[r] = n
+	}
+	res.Processes = append([]Process{}, c.metrics.Processes...)
+	res.Links = make([]Link, 0, len(c.links))
+	for _, l := range c.links {
+		res.Links = append(res.Links, l)
+	}
+	sort.Slice(res.Links, func(i, j int) bool {
+		if res.Links[i].From != res.Links[j].From {
+			return res.Links[i].From < res.Links[j].From
+		}
+		return res.Links[i].To < res.Links[j].To
+	})
+	return &res
+}
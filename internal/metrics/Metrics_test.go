@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	events := []trace.Event{
+		{Tick: 0, Kind: trace.Send, From: -1, To: 0, Size: 4},
+		{Tick: 0, Kind: trace.Deliver, From: -1, To: 0, Size: 4},
+		{Tick: 0, Kind: trace.Send, From: 0, To: 2, Size: 10},
+		{Tick: 0, Kind: trace.Send, From: 0, To: 0, Size: 3},
+		{Tick: 1, Kind: trace.Drop, From: 0, To: 1, Reason: trace.ReasonLoss},
+		{Tick: 2, Kind: trace.Forward, From: 1, To: 2, Size: 10},
+		{Tick: 3, Kind: trace.Deliver, From: 0, To: 2, Size: 10},
+		{Tick: 3, Kind: trace.Drop, From: 2, To: 1, Reason: trace.ReasonOverflow},
+		{Tick: 3, Kind: trace.Drop, From: 2, To: 1, Reason: trace.ReasonOverflow},
+		{Tick: 9, Kind: trace.Enter, From: 2, To: 2},
+	}
+	for _, e := range events {
+		c.Record(e)
+	}
+	got := c.Metrics()
+	want := &Metrics{
+		Sent:         1,
+		Bytes:        10,
+		Local:        1,
+		Injected:     1,
+		Forwarded:    1,
+		Delivered:    2,
+		Dropped:      map[string]int64{trace.ReasonLoss: 1, trace.ReasonOverflow: 2},
+		LastActivity: 3,
+		Processes:    []Process{{Node: 0, Sent: 1, Bytes: 10, Local: 1, Delivered: 1}, {Node: 2, Delivered: 1}},
+		Links:        []Link{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Collector.Metrics() = %+v, want %+v", got, want)
+	}
+	if got.Drops() != 3 || got.Sends() != 3 {
+		t.Errorf("Metrics.Drops(), Metrics.Sends() = %v, %v, want 3, 3", got.Drops(), got.Sends())
+	}
+	got.Dropped[trace.ReasonLoss]++
+	if c.Metrics().Dropped[trace.ReasonLoss] != 1 {
+		t.Errorf("Collector.Metrics() shares the drop counters")
+	}
+}
+
+func TestCollector_Counters(t *testing.T) {
+	c := NewCollector()
+	for _, e := range []trace.Event{
+		{Kind: trace.Send, From: -1, To: 0},
+		{Kind: trace.Send, From: 0, To: 1},
+		{Kind: trace.Send, From: 0, To: 0},
+		{Kind: trace.Send, From: 1, To: 2},
+	} {
+		c.Record(e)
+	}
+	if got := c.Sent(); got != 2 {
+		t.Errorf("Collector.Sent() = %v, want 2", got)
+	}
+	for _, tt := range []struct {
+		node int32
+		want int64
+	}{{-1, 0}, {0, 2}, {1, 1}, {2, 0}} {
+		if got := c.Sends(tt.node); got != tt.want {
+			t.Errorf("Collector.Sends(%v) = %v, want %v", tt.node, got, tt.want)
+		}
+	}
+	c.Carry(1, 2, 5)
+	c.Carry(0, 1, 3)
+	c.Carry(1, 2, 5)
+	c.Carry(-1, 0, 4)
+	c.Carry(2, 2, 4)
+	want := []Link{{From: 0, To: 1, Messages: 1, Bytes: 3}, {From: 1, To: 2, Messages: 2, Bytes: 10}}
+	if got := c.Metrics().Links; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collector.Metrics().Links = %+v, want %+v", got, want)
+	}
+}
+
+func TestMetrics_Process(t *testing.T) {
+	m := &Metrics{}
+	for _, node := range []int32{3, 1, 2, 1} {
+		m.Process(node).Sent++
+	}
+	want := []Process{{Node: 1, Sent: 2}, {Node: 2, Sent: 1}, {Node: 3, Sent: 1}}
+	if !reflect.DeepEqual(m.Processes, want) {
+		t.Errorf("Metrics.Processes = %+v, want %+v", m.Processes, want)
+	}
+}
+
+func TestMetrics_Write(t *testing.T) {
+	m := &Metrics{
+		Sent:      5,
+		Dropped:   map[string]int64{trace.ReasonLoss: 2},
+		Processes: []Process{{Node: 0, Sent: 5, MaxQueue: 2}},
+		Links:     []Link{{From: 0, To: 1, Messages: 5, Bytes: 50}},
+	}
+	var b bytes.Buffer
+	if err := m.WriteJSON(&b); err != nil {
+		t.Fatalf("Metrics.WriteJSON() error = %v", err)
+	}
+	var decoded Metrics
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil || !reflect.DeepEqual(&decoded, m) {
+		t.Errorf("Metrics.WriteJSON() = %v, error = %v", b.String(), err)
+	}
+	b.Reset()
+	if err := m.WriteTable(&b); err != nil {
+		t.Fatalf("Metrics.WriteTable() error = %v", err)
+	}
+	for _, s := range []string{"drop reason", "loss", "max queue", "0->1"} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("Metrics.WriteTable() = %q, want %q in it", b.String(), s)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package network
+
+// Capacity limits a link: Bandwidth is the number of bytes it transmits per tick and Buffer is the number
+// of messages it holds, including the one being transmitted. Zeros mean no limits.
+type Capacity struct {
+	Bandwidth int64
+	Buffer    int
+}
+
+// transmitter is the sending end of a link with a capacity: messages are transmitted one by one,
+// free is the tick the last of them is transmitted at and queue holds the ticks of the buffered ones.
+type transmitter struct {
+	Capacity
+	free  int64
+	queue []int64
+}
+
+// SetCapacity limits the existing link, could be bidirectional. Zero capacity removes the limits.
+func (nl *Network) SetCapacity(from int32, to int32, bidirectional bool, c Capacity) {
+	nl.setCapacity(from, to, c)
+	if bidirectional {
+		nl.setCapacity(to, from, c)
+	}
+}
+
+func (nl *Network) setCapacity(from int32, to int32, c Capacity) {
+	if _, ok := nl.networkMap[from][to]; !ok {
+		return
+	}
+	nl.transmitMutex.Lock()
+	defer nl.transmitMutex.Unlock()
+	if c == (Capacity{}) {
+		delete(nl.transmitters, [2]int32{from, to})
+		return
+	}
+	if t, ok := nl.transmitters[[2]int32{from, to}]; ok {
+		t.Capacity = c
+		return
+	}
+	nl.transmitters[[2]int32{from, to}] = &transmitter{Capacity: c}
+}
+
+// GetCapacity returns the capacity of the link, zero for unlimited or nonexistent links.
+func (nl *Network) GetCapacity(from int32, to int32) Capacity {
+	nl.transmitMutex.Lock()
+	defer nl.transmitMutex.Unlock()
+	if t, ok := nl.transmitters[[2]int32{from, to}]; ok {
+		return t.Capacity
+	}
+	return Capacity{}
+}
+
+// transmit puts the message of the size into the buffer of the link and returns the tick it is transmitted at:
+// after the messages buffered before and the serialisation time, the size divided by the bandwidth rounded up.
+// The second result is false if the buffer is full.
+func (nl *Network) transmit(from int32, to int32, size int) (int64, bool) {
+	nl.transmitMutex.Lock()
+	defer nl.transmitMutex.Unlock()
+	t, ok := nl.transmitters[[2]int32{from, to}]
+	if !ok {
+		return nl.Tick, true
+	}
+	sent := 0
+	for sent < len(t.queue) && t.queue[sent] <= nl.Tick {
+		sent++
+	}
+	t.queue = t.queue[sent:]
+	if t.Buffer > 0 && len(t.queue) >= t.Buffer {
+		return 0, false
+	}
+	if t.free < nl.Tick {
+		t.free = nl.Tick
+	}
+	if t.Bandwidth > 0 {
+		t.free += (int64(size) + t.Bandwidth - 1) / t.Bandwidth
+	}
+	t.queue = append(t.queue, t.free)
+	return t.free, true
+}
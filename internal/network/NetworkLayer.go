@@ -2,54 +2,214 @@ package network
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
 	mt "github.com/seehuhn/mt19937"
+	"github.com/trmigor/distr-model/internal/byzantine"
+	"github.com/trmigor/distr-model/internal/clock"
 	"github.com/trmigor/distr-model/internal/errors"
 	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/metrics"
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/pkg/graph"
 	"github.com/trmigor/distr-model/pkg/set"
 )
 
 // Network is a network infrastructure. Every process have to register in it.
 // It also registers connections between processes and sends messages to them.
+// An observer, if set, is notified of handled messages and timer messages, e.g. to record runs.
+// In FIFO networks messages of every link are delivered in order of sending, even if the link latency decreases.
+// If logical clocks are enabled, the network stamps sent messages and updates the clocks of receivers on delivery.
+// Messages sent by Byzantine processes to other processes are rewritten by their adversary strategies.
+// If a router is set, messages to processes without a link from their senders are forwarded hop by hop.
+// Links with a capacity transmit messages one by one, so their delivery times include serialisation
+// and queueing delays, and drop messages which overflow their buffers.
 type Network struct {
-	QueueMap    []*messages.MessageQueue
-	ErrorRate   float64
-	Rng         *rand.Rand
-	Tick        int64
-	StopFlag    bool
-	networkSize int32
-	networkMap  map[int32]map[int32]int32
-	globalTimer chan bool
+	QueueMap      []*messages.MessageQueue
+	ErrorRate     float64
+	Rng           *rand.Rand
+	Tick          int64
+	TickDuration  time.Duration
+	StopFlag      bool
+	Tracer        trace.Tracer
+	Router        Router
+	FIFO          bool
+	virtual       bool
+	fifoMutex     sync.Mutex
+	deliveries    map[[2]int32]int64
+	clockKind     clock.Kind
+	clockMutex    sync.Mutex
+	clocks        map[int32]*clock.Clock
+	metrics       *metrics.Collector
+	faultyMutex   sync.Mutex
+	faulty        map[int32]byzantine.Strategy
+	networkSize   int32
+	networkMap    graph.Graph
+	linked        int64
+	transmitMutex sync.Mutex
+	transmitters  map[[2]int32]*transmitter
+	stateMutex    sync.RWMutex
+	observer      Observer
+	observeMutex  sync.Mutex
+	globalTimer   chan bool
 }
 
 func globalTimerExecutor(nl *Network) {
 	start := time.Now()
 	for !nl.StopFlag {
 		cl := time.Since(start)
-		nl.Tick = cl.Milliseconds() / 1000
-		time.Sleep(100 * time.Millisecond)
+		nl.Tick = int64(cl / nl.TickDuration)
+		nap := nl.TickDuration / 10
+		if nap > 100*time.Millisecond {
+			nap = 100 * time.Millisecond
+		}
+		time.Sleep(nap)
 	}
 	nl.globalTimer <- true
 }
 
 // New creates a new instance of a network layer.
+// Its global timer ticks every second of real time.
 func New() *Network {
+	nl := NewVirtual()
+	nl.virtual = false
+	nl.TickDuration = time.Second
+	go globalTimerExecutor(nl)
+	return nl
+}
+
+// NewVirtual creates a new instance of a network layer with virtual time.
+// Its global timer is not started, ticks are advanced by the network user.
+func NewVirtual() *Network {
 	nl := &Network{
-		Rng:         rand.New(mt.New()),
-		networkMap:  make(map[int32]map[int32]int32),
-		globalTimer: make(chan bool),
+		Rng:          rand.New(mt.New()),
+		virtual:      true,
+		networkMap:   graph.New(),
+		globalTimer:  make(chan bool),
+		transmitters: make(map[[2]int32]*transmitter),
+		metrics:      metrics.NewCollector(),
 	}
 	nl.Rng.Seed(time.Now().UnixNano())
-	go globalTimerExecutor(nl)
 	return nl
 }
 
+// IsVirtual reports whether the network time is virtual.
+func (nl *Network) IsVirtual() bool {
+	return nl.virtual
+}
+
+// Seed initialises the random number generator for reproducible runs.
+func (nl *Network) Seed(seed int64) {
+	nl.Rng.Seed(seed)
+}
+
+// SetSpeed sets the number of ticks per second of real time.
+// It should be called before the network usage.
+func (nl *Network) SetSpeed(ticks float64) {
+	nl.TickDuration = time.Duration(float64(time.Second) / ticks)
+}
+
 // Stop is a destructor, stopping the global timer.
 // It should be called at the end of network usage.
 func (nl *Network) Stop() {
 	nl.StopFlag = true
-	<-nl.globalTimer
+	if !nl.virtual {
+		<-nl.globalTimer
+	}
+}
+
+// Record counts an event in the metrics and passes it to the network tracer, if any.
+func (nl *Network) Record(e trace.Event) {
+	nl.metrics.Record(e)
+	if nl.Tracer != nil {
+		nl.Tracer.Record(e)
+	}
+}
+
+// RecordMessage counts an event related to the message in the metrics and passes it to the network tracer, if any.
+func (nl *Network) RecordMessage(kind trace.Kind, m *messages.Message, reason string) {
+	nl.recordStamped(kind, m, reason, m.Stamp)
+}
+
+// recordStamped counts an event related to the message with given timestamp in the metrics
+// and passes it to the network tracer, if any. The message is formatted only for the tracer.
+func (nl *Network) recordStamped(kind trace.Kind, m *messages.Message, reason string, stamp *clock.Stamp) {
+	e := trace.Event{
+		Tick:     nl.Tick,
+		Kind:     kind,
+		From:     m.From,
+		To:       m.To,
+		Size:     len(m.Body),
+		Delivery: m.DeliveryTime,
+		Reason:   reason,
+	}
+	nl.metrics.Record(e)
+	if nl.Tracer == nil {
+		return
+	}
+	e.Message = m.String()
+	if stamp != nil {
+		e.Lamport, e.Vector = stamp.Lamport, stamp.Vector
+	}
+	nl.Tracer.Record(e)
+}
+
+// EnableClocks makes the network maintain logical clocks of the kind for all the processes.
+// It should be called before the network usage.
+func (nl *Network) EnableClocks(kind clock.Kind) {
+	nl.clockMutex.Lock()
+	defer nl.clockMutex.Unlock()
+	nl.clockKind = kind
+	nl.clocks = make(map[int32]*clock.Clock)
+}
+
+// Clock returns the logical clock of the process with given node or nil if clocks are disabled.
+func (nl *Network) Clock(node int32) *clock.Clock {
+	if node < 0 || nl.clockKind == clock.None {
+		return nil
+	}
+	nl.clockMutex.Lock()
+	defer nl.clockMutex.Unlock()
+	c, ok := nl.clocks[node]
+	if !ok {
+		c = clock.New(nl.clockKind, node)
+		nl.clocks[node] = c
+	}
+	return c
+}
+
+// stamp advances the clock of the sender and stamps the message.
+func (nl *Network) stamp(m *messages.Message) {
+	if c := nl.Clock(m.From); c != nil {
+		m.Stamp = c.Send()
+	}
+}
+
+// Deliver updates the clock of the receiver by the message timestamp and records the delivery.
+// It is called by processes before handling messages.
+func (nl *Network) Deliver(m *messages.Message) {
+	c := nl.Clock(m.To)
+	if c == nil {
+		nl.RecordMessage(trace.Deliver, m, "")
+		return
+	}
+	c.Receive(m.Stamp)
+	nl.recordStamped(trace.Deliver, m, "", c.Stamp())
+}
+
+// Busy marks the network busy with handling a message until the returned function is called.
+// Processes running in real time handle messages concurrently, but not while their state is inspected.
+func (nl *Network) Busy() func() {
+	nl.stateMutex.RLock()
+	return nl.stateMutex.RUnlock
+}
+
+// Inspect calls the function while no message is handled, so that it can read the contexts of processes.
+func (nl *Network) Inspect(f func()) {
+	nl.stateMutex.Lock()
+	defer nl.stateMutex.Unlock()
+	f()
 }
 
 // SetErrorRate sets rate of connection errors.
@@ -63,16 +223,44 @@ func (nl *Network) CreateLink(from int32, to int32, bidirectional bool, cost int
 	if from == to {
 		return
 	}
+	nl.setLink(from, to, cost)
+	if bidirectional {
+		nl.setLink(to, from, cost)
+	}
+}
+
+// RemoveLink disables connection between two processes. Could be bidirectional.
+func (nl *Network) RemoveLink(from int32, to int32, bidirectional bool) {
+	nl.unsetLink(from, to)
+	if bidirectional {
+		nl.unsetLink(to, from)
+	}
+}
+
+// setLink creates or changes the link.
+func (nl *Network) setLink(from int32, to int32, cost int32) {
 	if _, ok := nl.networkMap[from]; !ok {
 		nl.networkMap[from] = make(map[int32]int32)
 	}
 	nl.networkMap[from][to] = cost
-	if bidirectional {
-		if _, ok := nl.networkMap[to]; !ok {
-			nl.networkMap[to] = make(map[int32]int32)
-		}
-		nl.networkMap[to][from] = cost
+	nl.linked = nl.Tick
+}
+
+// unsetLink removes the link, if any.
+func (nl *Network) unsetLink(from int32, to int32) {
+	if _, ok := nl.networkMap[from][to]; !ok {
+		return
 	}
+	delete(nl.networkMap[from], to)
+	nl.transmitMutex.Lock()
+	delete(nl.transmitters, [2]int32{from, to})
+	nl.transmitMutex.Unlock()
+	nl.linked = nl.Tick
+}
+
+// LinksChanged returns the tick links have been created, changed or removed at for the last time.
+func (nl *Network) LinksChanged() int64 {
+	return nl.linked
 }
 
 // GetLink returns the cost of message sending or -1 if there is no connection.
@@ -103,67 +291,179 @@ func (nl *Network) SendMessage(fromProcess int32, toProcess int32, msg *messages
 
 // SendBytes sends a byte vector from one process to another.
 func (nl *Network) SendBytes(fromProcess int32, toProcess int32, msg []byte) errors.ErrorCode {
+	m := messages.NewMessage(fromProcess, toProcess, msg)
+	nl.stamp(m)
 	if toProcess >= nl.networkSize {
+		nl.RecordMessage(trace.Drop, m, trace.ReasonNoProcess)
 		return errors.SizeTooBig
 	}
-	m := messages.NewMessage(fromProcess, toProcess, msg)
+	reason := ""
+	if s := nl.Byzantine(fromProcess); s != nil && fromProcess != toProcess {
+		body := s.Rewrite(m.Body, toProcess, nl.Rng)
+		if body == nil {
+			nl.RecordMessage(trace.Drop, m, trace.ReasonByzantine)
+			return errors.OK
+		}
+		m.Body, reason = body, trace.ReasonByzantine
+	}
 	if nl.ErrorRate > 0 && nl.Rng.Float64() < nl.ErrorRate {
+		nl.RecordMessage(trace.Drop, m, trace.ReasonLoss)
 		return errors.TimeOut
 	}
 	if nl.QueueMap[toProcess] == nil {
+		nl.RecordMessage(trace.Drop, m, trace.ReasonNoProcess)
 		return errors.ItemNotFound
 	}
+	m.SendTime = nl.Tick
 	p := nl.GetLink(fromProcess, toProcess)
+	if p < 0 && nl.Router != nil {
+		return nl.hop(fromProcess, m, trace.Send, reason)
+	}
 	if p < 0 {
+		nl.RecordMessage(trace.Drop, m, trace.ReasonNoLink)
 		return errors.ItemNotFound
 	}
-	m.SendTime = nl.Tick
-	m.DeliveryTime = nl.Tick + int64(p)
+	sent, ok := nl.transmit(fromProcess, toProcess, len(m.Body))
+	if !ok {
+		nl.RecordMessage(trace.Drop, m, trace.ReasonOverflow)
+		return errors.SizeTooBig
+	}
+	m.DeliveryTime = nl.fifo(fromProcess, toProcess, sent+int64(p))
+	nl.RecordMessage(trace.Send, m, reason)
 	nl.QueueMap[toProcess].Enqueue(m)
+	nl.metrics.Carry(fromProcess, toProcess, len(m.Body))
+	return errors.OK
+}
+
+// Forward passes the routed message reached the process to the next hop on its way to the receiver.
+// It is called by processes instead of handling messages to other processes.
+func (nl *Network) Forward(node int32, m *messages.Message) errors.ErrorCode {
+	if nl.ErrorRate > 0 && nl.Rng.Float64() < nl.ErrorRate {
+		nl.dropHop(m, trace.Forward, trace.ReasonLoss)
+		return errors.TimeOut
+	}
+	if nl.Router == nil {
+		nl.dropHop(m, trace.Forward, trace.ReasonNoRoute)
+		return errors.ItemNotFound
+	}
+	return nl.hop(node, m, trace.Forward, "")
+}
+
+// hop enqueues the routed message at the process to the next hop chosen by the router and records it
+// as an event of the kind: a send by its sender or a forward by an intermediate process.
+// Messages passed more links than there are processes are dropped as looping.
+func (nl *Network) hop(node int32, m *messages.Message, kind trace.Kind, reason string) errors.ErrorCode {
+	next, ok := nl.Router.NextHop(node, m.To)
+	p := nl.GetLink(node, next)
+	if !ok || next == node || p < 0 || m.Hops >= int(nl.networkSize) {
+		nl.dropHop(m, kind, trace.ReasonNoRoute)
+		return errors.ItemNotFound
+	}
+	if next < 0 || next >= nl.networkSize || nl.QueueMap[next] == nil {
+		nl.dropHop(m, kind, trace.ReasonNoProcess)
+		return errors.ItemNotFound
+	}
+	sent, ok := nl.transmit(node, next, len(m.Body))
+	if !ok {
+		nl.dropHop(m, kind, trace.ReasonOverflow)
+		return errors.SizeTooBig
+	}
+	m.Hops++
+	m.DeliveryTime = nl.fifo(node, next, sent+int64(p))
+	if kind == trace.Send {
+		nl.RecordMessage(trace.Send, m, reason)
+	} else {
+		nl.Record(trace.Event{Tick: nl.Tick, Kind: trace.Forward, From: node, To: next, Message: m.String(),
+			Size: len(m.Body), Delivery: m.DeliveryTime})
+	}
+	nl.QueueMap[next].Enqueue(m)
+	nl.metrics.Carry(node, next, len(m.Body))
+	return errors.OK
+}
+
+// dropHop records the drop of the routed message at the hop of the kind. Only the sender drops the message
+// with its timestamp: the drops by intermediate processes are recorded without one, since the timestamp
+// belongs to the earlier send and would break the causal order of the events of the sender.
+func (nl *Network) dropHop(m *messages.Message, kind trace.Kind, reason string) {
+	if kind == trace.Send {
+		nl.RecordMessage(trace.Drop, m, reason)
+		return
+	}
+	nl.recordStamped(trace.Drop, m, reason, nil)
+}
+
+// SetByzantine makes the process Byzantine: its messages to other processes are passed through the strategy.
+// A nil strategy makes the process correct again.
+func (nl *Network) SetByzantine(node int32, s byzantine.Strategy) {
+	nl.faultyMutex.Lock()
+	defer nl.faultyMutex.Unlock()
+	if nl.faulty == nil {
+		nl.faulty = make(map[int32]byzantine.Strategy)
+	}
+	if s == nil {
+		delete(nl.faulty, node)
+		return
+	}
+	nl.faulty[node] = s
+}
+
+// Byzantine returns the strategy of the process, nil if the process is correct.
+func (nl *Network) Byzantine(node int32) byzantine.Strategy {
+	nl.faultyMutex.Lock()
+	defer nl.faultyMutex.Unlock()
+	return nl.faulty[node]
+}
+
+// Metrics returns the collector counting the messages of the network.
+func (nl *Network) Metrics() *metrics.Collector {
+	return nl.metrics
+}
+
+// fifo delays the delivery, if needed, so that messages of a link are not reordered in FIFO networks.
+func (nl *Network) fifo(from int32, to int32, delivery int64) int64 {
+	if !nl.FIFO || from < 0 {
+		return delivery
+	}
+	nl.fifoMutex.Lock()
+	defer nl.fifoMutex.Unlock()
+	if nl.deliveries == nil {
+		nl.deliveries = make(map[[2]int32]int64)
+	}
+	link := [2]int32{from, to}
+	if last := nl.deliveries[link]; delivery < last {
+		delivery = last
+	}
+	nl.deliveries[link] = delivery
+	return delivery
+}
+
+// SendTimeout sends a message from the process to itself, which is delivered after the delay.
+// Such messages model local timeouts, so they are never lost.
+func (nl *Network) SendTimeout(node int32, delay int64, msg *messages.Message) errors.ErrorCode {
+	m := messages.NewMessage(node, node, msg.Body)
+	nl.stamp(m)
+	if node < 0 || node >= nl.networkSize || nl.QueueMap[node] == nil {
+		nl.RecordMessage(trace.Drop, m, trace.ReasonNoProcess)
+		return errors.ItemNotFound
+	}
+	m.SendTime = nl.Tick
+	m.DeliveryTime = nl.Tick + delay
+	nl.RecordMessage(trace.Send, m, "")
+	nl.QueueMap[node].Enqueue(m)
 	return errors.OK
 }
 
 // AddLinksToAll adds connections from requested process to all of others.
 func (nl *Network) AddLinksToAll(from int32, bidirectional bool, latency int32) {
-	if _, ok := nl.networkMap[from]; !ok {
-		nl.networkMap[from] = make(map[int32]int32)
-	}
 	for i := int32(0); i < nl.networkSize; i++ {
-		if from != i {
-			nl.networkMap[from][i] = latency
-		}
-	}
-	if bidirectional {
-		for i := int32(0); i < nl.networkSize; i++ {
-			if _, ok := nl.networkMap[i]; !ok {
-				nl.networkMap[i] = make(map[int32]int32)
-			}
-			if from != i {
-				nl.networkMap[i][from] = latency
-			}
-		}
+		nl.CreateLink(from, i, bidirectional, latency)
 	}
 }
 
 // AddLinksFromAll adds connections to requested process from all of others.
 func (nl *Network) AddLinksFromAll(to int32, bidirectional bool, latency int32) {
 	for i := int32(0); i < nl.networkSize; i++ {
-		if to != i {
-			if _, ok := nl.networkMap[i]; !ok {
-				nl.networkMap[i] = make(map[int32]int32)
-			}
-			nl.networkMap[i][to] = latency
-		}
-	}
-	if bidirectional {
-		if _, ok := nl.networkMap[to]; !ok {
-			nl.networkMap[to] = make(map[int32]int32)
-		}
-		for i := int32(0); i < nl.networkSize; i++ {
-			if to != i {
-				nl.networkMap[to][i] = latency
-			}
-		}
+		nl.CreateLink(i, to, bidirectional, latency)
 	}
 }
 
@@ -185,6 +485,34 @@ func (nl *Network) Neibs(from int32) *set.Set {
 	return res
 }
 
+// Links returns a copy of the network graph: Links()[from][to] is the latency of the link.
+func (nl *Network) Links() graph.Graph {
+	res := graph.New()
+	for from, m := range nl.networkMap {
+		for to, cost := range m {
+			res.AddEdge(from, to, cost)
+		}
+	}
+	return res
+}
+
+// Neighbours returns a sorted list of neighbours of requested process.
+// Unlike Neibs, iteration over it does not break the determinism of virtual time runs.
+func (nl *Network) Neighbours(from int32) []int32 {
+	return nl.networkMap.Neighbours(from)
+}
+
+// Nodes returns the sorted nodes of all the processes registered in the network.
+func (nl *Network) Nodes() []int32 {
+	res := make([]int32, 0, len(nl.QueueMap))
+	for i, q := range nl.QueueMap {
+		if q != nil {
+			res = append(res, int32(i))
+		}
+	}
+	return res
+}
+
 // Process ensures requirements for process structure.
 type Process interface {
 	NetworkLayer() **Network
@@ -205,14 +533,19 @@ func (nl *Network) RegisterProcess(node int32, dp Process) errors.ErrorCode {
 	return errors.OK
 }
 
-// TimerSender sends timer message every nap seconds.
+// TimerMessage returns the current-th timer message.
+func TimerMessage(current int32) *messages.Message {
+	arg1 := messages.NewMessageArg([]byte("*TIME"))
+	arg2 := messages.NewMessageArg(current)
+	return messages.NewMessageByArgs(arg1, arg2)
+}
+
+// TimerSender sends timer message every nap ticks.
 func TimerSender(nl *Network, nap int) {
 	current := int32(0)
 	for !nl.StopFlag {
-		arg1 := messages.NewMessageArg([]byte("*TIME"))
-		arg2 := messages.NewMessageArg(current)
-		nl.SendMessage(-1, -1, messages.NewMessageByArgs(arg1, arg2))
+		nl.SendTimer(current)
 		current++
-		time.Sleep(time.Duration(nap) * time.Second)
+		time.Sleep(time.Duration(nap) * nl.TickDuration)
 	}
 }
@@ -3,11 +3,17 @@ package network
 import (
 	"math/rand"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/trmigor/distr-model/internal/byzantine"
+	"github.com/trmigor/distr-model/internal/clock"
 	"github.com/trmigor/distr-model/internal/errors"
 	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/metrics"
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/pkg/graph"
 	"github.com/trmigor/distr-model/pkg/set"
 )
 
@@ -474,3 +480,473 @@ func TestTimerSender(t *testing.T) {
 		})
 	}
 }
+
+func TestNetwork_Neighbours(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.CreateLink(0, 3, false, 1)
+	nl.CreateLink(0, 1, false, 1)
+	nl.CreateLink(0, 2, false, 1)
+	if got, want := nl.Neighbours(0), []int32{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Network.Neighbours() = %v, want %v", got, want)
+	}
+}
+
+func TestNetwork_Nodes(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	for _, node := range []int32{3, 0, 1} {
+		nl.RegisterProcess(node, &process{mq: messages.NewMessageQueue()})
+	}
+	if got, want := nl.Nodes(), []int32{0, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Network.Nodes() = %v, want %v", got, want)
+	}
+}
+
+func TestNetwork_Links(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.CreateLink(0, 1, true, 3)
+	nl.CreateLink(1, 2, false, 2)
+	got := nl.Links()
+	want := graph.Graph{0: {1: 3}, 1: {0: 3, 2: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Network.Links() = %v, want %v", got, want)
+	}
+	got[0][1] = 5
+	if nl.GetLink(0, 1) != 3 {
+		t.Errorf("Network.Links() returned the network graph itself")
+	}
+}
+
+func TestNewVirtual(t *testing.T) {
+	nl := NewVirtual()
+	if !nl.IsVirtual() {
+		t.Errorf("NewVirtual(): network is not virtual")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if nl.Tick != 0 {
+		t.Errorf("NewVirtual(): tick advanced to %v", nl.Tick)
+	}
+	nl.Stop()
+}
+
+func TestNetwork_SetSpeed(t *testing.T) {
+	nl := New()
+	nl.SetSpeed(100)
+	time.Sleep(200 * time.Millisecond)
+	nl.Stop()
+	if nl.IsVirtual() || nl.Tick < 10 {
+		t.Errorf("Network.SetSpeed(): tick = %v after 200ms at 100 ticks per second", nl.Tick)
+	}
+}
+
+func TestNetwork_Seed(t *testing.T) {
+	draw := func() []float64 {
+		nl := NewVirtual()
+		defer nl.Stop()
+		nl.Seed(7)
+		return []float64{nl.Rng.Float64(), nl.Rng.Float64()}
+	}
+	if first, second := draw(), draw(); !reflect.DeepEqual(first, second) {
+		t.Errorf("Network.Seed(): %v and %v differ", first, second)
+	}
+}
+
+func TestNetwork_SendTimeout(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.networkSize = 2
+	nl.QueueMap = []*messages.MessageQueue{messages.NewMessageQueue(), nil}
+	nl.Tick = 4
+	nl.SetErrorRate(1)
+	msg := messages.NewMessageByArgs(messages.NewMessageArg([]byte("A")))
+	if got := nl.SendTimeout(0, 3, msg); got != errors.OK {
+		t.Fatalf("Network.SendTimeout() = %v, want %v", got, errors.OK)
+	}
+	if m := nl.QueueMap[0].Peek(); m.From != 0 || m.To != 0 || m.DeliveryTime != 7 {
+		t.Errorf("Network.SendTimeout() enqueued %+v", m)
+	}
+	for _, node := range []int32{-1, 1, 2} {
+		if got := nl.SendTimeout(node, 3, msg); got != errors.ItemNotFound {
+			t.Errorf("Network.SendTimeout(%v) = %v, want %v", node, got, errors.ItemNotFound)
+		}
+	}
+}
+
+func TestNetwork_Metrics(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.networkSize = 2
+	nl.QueueMap = []*messages.MessageQueue{messages.NewMessageQueue(), messages.NewMessageQueue()}
+	nl.CreateLink(0, 1, false, 1)
+	msg := []byte{65, 1, 0, 0, 0}
+	nl.SendBytes(0, 1, msg)
+	nl.SendBytes(1, 0, msg)
+	nl.SendTimeout(0, 2, messages.NewMessageByArgs(messages.NewMessageArg([]byte("A"))))
+	nl.SetErrorRate(1)
+	nl.SendBytes(0, 1, msg)
+	for _, tt := range []struct {
+		node int32
+		want int64
+	}{{0, 2}, {1, 0}, {2, 0}} {
+		if got := nl.Metrics().Sends(tt.node); got != tt.want {
+			t.Errorf("Collector.Sends(%v) = %v, want %v", tt.node, got, tt.want)
+		}
+	}
+	want := []metrics.Link{{From: 0, To: 1, Messages: 1, Bytes: int64(len(msg))}}
+	if got := nl.Metrics().Metrics().Links; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collector.Metrics().Links = %+v, want %+v", got, want)
+	}
+}
+
+func TestNetwork_FIFO(t *testing.T) {
+	tests := []struct {
+		name string
+		fifo bool
+		want []int64
+	}{
+		{"FIFO", true, []int64{5, 5}},
+		{"Reordering", false, []int64{5, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := NewVirtual()
+			defer nl.Stop()
+			nl.FIFO = tt.fifo
+			nl.networkSize = 2
+			nl.QueueMap = []*messages.MessageQueue{messages.NewMessageQueue(), messages.NewMessageQueue()}
+			msg := messages.NewMessageByArgs(messages.NewMessageArg([]byte("A")))
+			nl.CreateLink(0, 1, false, 5)
+			nl.SendMessage(0, 1, msg)
+			nl.CreateLink(0, 1, false, 1)
+			nl.SendMessage(0, 1, msg)
+			got := []int64{}
+			for nl.QueueMap[1].Size() > 0 {
+				got = append(got, nl.QueueMap[1].Dequeue().DeliveryTime)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] > got[j] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("delivery times = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetwork_Tracer(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	rec := trace.NewRecorder()
+	nl.Tracer = rec
+	nl.networkSize = 2
+	nl.QueueMap = []*messages.MessageQueue{messages.NewMessageQueue(), nil}
+	nl.CreateLink(1, 0, false, 3)
+	msg := messages.NewMessageByArgs(messages.NewMessageArg([]byte("A")))
+
+	nl.SendMessage(1, 0, msg)
+	nl.SendMessage(0, 1, msg)
+	nl.SendMessage(1, 5, msg)
+	nl.SendMessage(2, 0, msg)
+	nl.SetErrorRate(1)
+	nl.SendMessage(1, 0, msg)
+
+	want := []trace.Event{
+		{Kind: trace.Send, From: 1, To: 0, Message: "A", Size: 3, Delivery: 3},
+		{Kind: trace.Drop, From: 0, To: 1, Message: "A", Size: 3, Reason: trace.ReasonNoProcess},
+		{Kind: trace.Drop, From: 1, To: 5, Message: "A", Size: 3, Reason: trace.ReasonNoProcess},
+		{Kind: trace.Drop, From: 2, To: 0, Message: "A", Size: 3, Reason: trace.ReasonNoLink},
+		{Kind: trace.Drop, From: 1, To: 0, Message: "A", Size: 3, Reason: trace.ReasonLoss},
+	}
+	if got := rec.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Network tracer got %v, want %v", got, want)
+	}
+}
+
+func TestNetwork_Clocks(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	rec := trace.NewRecorder()
+	nl.Tracer = rec
+	nl.networkSize = 2
+	nl.QueueMap = []*messages.MessageQueue{messages.NewMessageQueue(), messages.NewMessageQueue()}
+	nl.CreateLink(0, 1, true, 1)
+	nl.EnableClocks(clock.Vector)
+	msg := messages.NewMessageByArgs(messages.NewMessageArg([]byte("A")))
+
+	nl.SendMessage(-1, 0, msg)
+	nl.Deliver(nl.QueueMap[0].Dequeue())
+	nl.SendMessage(0, 1, msg)
+	m := nl.QueueMap[1].Dequeue()
+	if want := (&clock.Stamp{Lamport: 2, Vector: []int64{2}}); !reflect.DeepEqual(m.Stamp, want) {
+		t.Errorf("Network.SendMessage() stamp = %+v, want %+v", m.Stamp, want)
+	}
+	nl.Deliver(m)
+	if got := nl.Clock(1).Vector(); !reflect.DeepEqual(got, []int64{2, 1}) {
+		t.Errorf("Network.Deliver(): receiver clock = %v, want [2 1]", got)
+	}
+	if nl.Clock(-1) != nil {
+		t.Errorf("Network.Clock(-1) != nil")
+	}
+
+	want := []trace.Event{
+		{Kind: trace.Send, From: -1, To: 0, Message: "A", Size: 3},
+		{Kind: trace.Deliver, From: -1, To: 0, Message: "A", Size: 3, Lamport: 1, Vector: []int64{1}},
+		{Kind: trace.Send, From: 0, To: 1, Message: "A", Size: 3, Delivery: 1, Lamport: 2, Vector: []int64{2}},
+		{Kind: trace.Deliver, From: 0, To: 1, Message: "A", Size: 3, Delivery: 1, Lamport: 3, Vector: []int64{2, 1}},
+	}
+	got := rec.Events()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Network tracer got %v, want %v", got, want)
+	}
+	if err := trace.CheckCausality(got); err != nil {
+		t.Errorf("trace.CheckCausality() error = %v", err)
+	}
+}
+
+func TestNetwork_SetByzantine(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.networkSize = 2
+	nl.QueueMap = []*messages.MessageQueue{messages.NewMessageQueue(), messages.NewMessageQueue()}
+	nl.CreateLink(0, 1, true, 1)
+	silent, _ := byzantine.New(byzantine.Silent)
+	nl.SetByzantine(0, silent)
+	if got := nl.Byzantine(0); got != silent {
+		t.Errorf("Network.Byzantine() = %v, want %v", got, silent)
+	}
+	msg := messages.NewMessageByArgs(messages.NewMessageArg([]byte("A")), messages.NewMessageArg(int32(1)))
+	nl.SendMessage(0, 1, msg)
+	nl.SendMessage(0, 0, msg)
+	nl.SendMessage(1, 0, msg)
+	for _, tt := range []struct {
+		node int32
+		want int64
+	}{{0, 1}, {1, 1}} {
+		if got := nl.Metrics().Sends(tt.node); got != tt.want {
+			t.Errorf("Collector.Sends(%v) = %v, want %v", tt.node, got, tt.want)
+		}
+	}
+	nl.SetByzantine(0, nil)
+	if got := nl.Byzantine(0); got != nil {
+		t.Errorf("Network.Byzantine() = %v, want nil", got)
+	}
+}
+
+func TestNetwork_Router(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.networkSize = 5
+	for i := 0; i < 5; i++ {
+		nl.QueueMap = append(nl.QueueMap, messages.NewMessageQueue())
+	}
+	nl.CreateLink(0, 1, true, 1)
+	nl.CreateLink(1, 2, true, 2)
+	nl.CreateLink(2, 3, true, 3)
+	nl.CreateLink(0, 3, true, 7)
+	recorder := trace.NewRecorder()
+	nl.Tracer = recorder
+	msg := messages.NewMessageByArgs(messages.NewMessageArg([]byte("A")))
+
+	if got := nl.SendMessage(0, 2, msg); got != errors.ItemNotFound {
+		t.Errorf("Network.SendMessage() without router = %v, want %v", got, errors.ItemNotFound)
+	}
+	nl.Router = Static{Network: nl}
+	if got := nl.SendMessage(0, 4, msg); got != errors.ItemNotFound {
+		t.Errorf("Network.SendMessage() to unreachable process = %v, want %v", got, errors.ItemNotFound)
+	}
+	if got := nl.SendMessage(0, 2, msg); got != errors.OK {
+		t.Fatalf("Network.SendMessage() = %v, want %v", got, errors.OK)
+	}
+	for _, hop := range []int32{1, 2} {
+		m := nl.QueueMap[hop].Dequeue()
+		if m == nil {
+			t.Fatalf("no message at hop %v", hop)
+		}
+		if m.From != 0 || m.To != 2 || int32(m.Hops) != hop {
+			t.Errorf("message at hop %v: from %v to %v, %v hops", hop, m.From, m.To, m.Hops)
+		}
+		nl.Tick = m.DeliveryTime
+		if hop == 2 {
+			break
+		}
+		if got := nl.Forward(hop, m); got != errors.OK {
+			t.Fatalf("Network.Forward() = %v, want %v", got, errors.OK)
+		}
+	}
+	if nl.Tick != 3 {
+		t.Errorf("routed message delivered at %v, want 3", nl.Tick)
+	}
+	if got := nl.Metrics().Sends(0); got != 1 {
+		t.Errorf("Collector.Sends(0) = %v, want 1", got)
+	}
+	want := []struct {
+		kind     trace.Kind
+		from, to int32
+		reason   string
+	}{
+		{trace.Drop, 0, 2, trace.ReasonNoLink},
+		{trace.Drop, 0, 4, trace.ReasonNoRoute},
+		{trace.Send, 0, 2, ""},
+		{trace.Forward, 1, 2, ""},
+	}
+	events := recorder.Events()
+	if len(events) != len(want) {
+		t.Fatalf("Network events = %v, want %v", events, want)
+	}
+	for i, e := range events {
+		if e.Kind != want[i].kind || e.From != want[i].from || e.To != want[i].to || e.Reason != want[i].reason {
+			t.Errorf("event %v = %v, want %v", i, e, want[i])
+		}
+	}
+}
+
+func TestNetwork_RouterClocks(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.networkSize = 3
+	for i := 0; i < 3; i++ {
+		nl.QueueMap = append(nl.QueueMap, messages.NewMessageQueue())
+	}
+	nl.CreateLink(0, 1, true, 1)
+	nl.CreateLink(1, 2, true, 1)
+	nl.Router = Static{Network: nl}
+	nl.EnableClocks(clock.Lamport)
+	recorder := trace.NewRecorder()
+	nl.Tracer = recorder
+
+	nl.SendMessage(0, 2, messages.NewMessageByArgs(messages.NewMessageArg([]byte("A"))))
+	nl.SendMessage(0, 1, messages.NewMessageByArgs(messages.NewMessageArg([]byte("B"))))
+	m := nl.QueueMap[1].Dequeue()
+	nl.SetErrorRate(1)
+	if got := nl.Forward(1, m); got != errors.TimeOut {
+		t.Fatalf("Network.Forward() = %v, want %v", got, errors.TimeOut)
+	}
+	events := recorder.Events()
+	if drop := events[len(events)-1]; drop.Kind != trace.Drop || drop.Lamport != 0 {
+		t.Errorf("dropped routed message recorded as %v, want a drop without a timestamp", drop)
+	}
+	if err := trace.CheckCausality(events); err != nil {
+		t.Errorf("trace.CheckCausality() error = %v", err)
+	}
+}
+
+func TestNetwork_RemoveLink(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.CreateLink(0, 1, true, 1)
+	nl.CreateLink(1, 2, true, 1)
+	nl.Tick = 5
+	nl.RemoveLink(1, 2, false)
+	if got := nl.LinksChanged(); got != 5 {
+		t.Errorf("Network.LinksChanged() = %v, want 5", got)
+	}
+	nl.Tick = 7
+	nl.RemoveLink(0, 2, true)
+	if got := nl.LinksChanged(); got != 5 {
+		t.Errorf("Network.LinksChanged() after removing no link = %v, want 5", got)
+	}
+	nl.RemoveLink(0, 1, true)
+	links := []struct {
+		from, to, want int32
+	}{
+		{0, 1, -1}, {1, 0, -1}, {1, 2, -1}, {2, 1, 1},
+	}
+	for _, l := range links {
+		if got := nl.GetLink(l.from, l.to); got != l.want {
+			t.Errorf("Network.GetLink(%v, %v) = %v, want %v", l.from, l.to, got, l.want)
+		}
+	}
+	if got := nl.LinksChanged(); got != 7 {
+		t.Errorf("Network.LinksChanged() = %v, want 7", got)
+	}
+}
+
+type table map[int32]int32
+
+func (t table) NextHop(to int32) (int32, bool) {
+	next, ok := t[to]
+	return next, ok
+}
+
+func TestTables_NextHop(t *testing.T) {
+	tables := Tables(func(node int32) Table {
+		if node == 0 {
+			return table{2: 1}
+		}
+		return nil
+	})
+	r, err := NewRouter("tables", nil, tables)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+	if next, ok := r.NextHop(0, 2); !ok || next != 1 {
+		t.Errorf("Tables.NextHop(0, 2) = %v, %v, want 1, true", next, ok)
+	}
+	if _, ok := r.NextHop(0, 3); ok {
+		t.Errorf("Tables.NextHop(0, 3) found a route")
+	}
+	if _, ok := r.NextHop(1, 2); ok {
+		t.Errorf("Tables.NextHop(1, 2) found a route without a table")
+	}
+	if _, err := NewRouter("flooding", nil, tables); err == nil {
+		t.Errorf("NewRouter() error = nil for an unknown router")
+	}
+}
+
+func TestNetwork_SetCapacity(t *testing.T) {
+	nl := NewVirtual()
+	defer nl.Stop()
+	nl.networkSize = 3
+	for i := 0; i < 3; i++ {
+		nl.QueueMap = append(nl.QueueMap, messages.NewMessageQueue())
+	}
+	nl.CreateLink(0, 1, true, 2)
+	nl.SetCapacity(0, 1, false, Capacity{Bandwidth: 4, Buffer: 2})
+	nl.SetCapacity(0, 2, false, Capacity{Bandwidth: 1})
+	if got := nl.GetCapacity(0, 2); got != (Capacity{}) {
+		t.Errorf("Network.GetCapacity() of a nonexistent link = %v, want none", got)
+	}
+	if got := nl.GetCapacity(1, 0); got != (Capacity{}) {
+		t.Errorf("Network.GetCapacity() of the reverse link = %v, want none", got)
+	}
+	recorder := trace.NewRecorder()
+	nl.Tracer = recorder
+	body := make([]byte, 10)
+	sends := []struct {
+		tick int64
+		want errors.ErrorCode
+	}{
+		{0, errors.OK}, {0, errors.OK}, {0, errors.SizeTooBig}, {3, errors.OK},
+	}
+	for _, s := range sends {
+		nl.Tick = s.tick
+		if got := nl.SendBytes(0, 1, body); got != s.want {
+			t.Errorf("Network.SendBytes() at %v = %v, want %v", s.tick, got, s.want)
+		}
+	}
+	nl.SendBytes(1, 0, body)
+	want := []struct {
+		kind     trace.Kind
+		delivery int64
+		reason   string
+	}{
+		{trace.Send, 5, ""},
+		{trace.Send, 8, ""},
+		{trace.Drop, 0, trace.ReasonOverflow},
+		{trace.Send, 11, ""},
+		{trace.Send, 5, ""},
+	}
+	events := recorder.Events()
+	if len(events) != len(want) {
+		t.Fatalf("Network events = %v, want %v", events, want)
+	}
+	for i, e := range events {
+		if e.Kind != want[i].kind || e.Delivery != want[i].delivery || e.Reason != want[i].reason {
+			t.Errorf("event %v = %v, want %v", i, e, want[i])
+		}
+	}
+	nl.SetCapacity(0, 1, true, Capacity{})
+	if got := nl.GetCapacity(0, 1); got != (Capacity{}) {
+		t.Errorf("Network.GetCapacity() after removing the limits = %v, want none", got)
+	}
+}
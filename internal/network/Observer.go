@@ -0,0 +1,41 @@
+package network
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+// Observer is notified of the choices of a run which are not determined by the random number generator:
+// the order of messages handled by processes and the ticks of timer messages.
+type Observer interface {
+	// Handle is called before the process of the node handles the message: delivers or forwards it.
+	Handle(tick int64, node int32, m *messages.Message)
+	// Timer is called before the timer message with the number is sent to all the processes.
+	Timer(tick int64, current int32)
+}
+
+// Observe sets the observer of the network, nil removes it. It should be called before the network usage.
+func (nl *Network) Observe(o Observer) {
+	nl.observer = o
+}
+
+// Handling notifies the observer, if any, that the process of the node starts handling the message
+// and returns the function to call when it is handled. While observed, messages are handled one at a time,
+// so that random numbers drawn by processes running in real time are drawn in the order of handling.
+func (nl *Network) Handling(node int32, m *messages.Message) func() {
+	if nl.observer == nil {
+		return func() {}
+	}
+	nl.observeMutex.Lock()
+	nl.observer.Handle(nl.Tick, node, m)
+	return nl.observeMutex.Unlock
+}
+
+// SendTimer sends the timer message with the number to all the processes.
+func (nl *Network) SendTimer(current int32) {
+	if nl.observer != nil {
+		nl.observeMutex.Lock()
+		nl.observer.Timer(nl.Tick, current)
+		nl.observeMutex.Unlock()
+	}
+	nl.SendMessage(-1, -1, TimerMessage(current))
+}
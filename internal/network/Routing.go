@@ -0,0 +1,62 @@
+package network
+
+import (
+	"fmt"
+)
+
+// Router chooses the next hops of messages to processes, which have no links from their senders.
+type Router interface {
+	// NextHop returns the neighbour of the process the message to the receiver is passed to,
+	// the second result is false if there is no route.
+	NextHop(node int32, to int32) (int32, bool)
+}
+
+// Static routes messages along the shortest paths by link latencies, which are computed on every hop,
+// so routes follow link changes at once.
+type Static struct {
+	Network *Network
+}
+
+// NextHop implements Router.
+func (s Static) NextHop(node int32, to int32) (int32, bool) {
+	path, _, ok := s.Network.networkMap.ShortestPath(node, to)
+	if !ok || len(path) < 2 {
+		return 0, false
+	}
+	return path[1], true
+}
+
+// Table is a routing table of a process, routing protocols keep them in the process contexts.
+type Table interface {
+	// NextHop returns the neighbour the message to the receiver is passed to,
+	// the second result is false if there is no route.
+	NextHop(to int32) (int32, bool)
+}
+
+// Tables routes messages by the routing tables of the processes given by the function, nil if there is none.
+type Tables func(node int32) Table
+
+// NextHop implements Router.
+func (t Tables) NextHop(node int32, to int32) (int32, bool) {
+	table := t(node)
+	if table == nil {
+		return 0, false
+	}
+	return table.NextHop(to)
+}
+
+// Routers returns the names of the routers known by NewRouter.
+func Routers() []string {
+	return []string{"static", "tables"}
+}
+
+// NewRouter creates the router of the network by its name, the tables are used by the "tables" router.
+func NewRouter(name string, nl *Network, tables Tables) (Router, error) {
+	switch name {
+	case "static":
+		return Static{Network: nl}, nil
+	case "tables":
+		return tables, nil
+	}
+	return nil, fmt.Errorf("unknown router %v", name)
+}
@@ -0,0 +1,100 @@
+package process
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ctx returns the process context by its key.
+// It panics if the process has no such context or it is not of type T.
+func Ctx[T any](p *Process, key string) T {
+	c, ok := LookupCtx[T](p, key)
+	if !ok {
+		panic(fmt.Sprintf("process %v: context %v is %T, not %T", p.Node, key, p.Context[key], c))
+	}
+	return c
+}
+
+// LookupCtx returns the process context by its key.
+// The second result is false if the process has no such context or it is not of type T.
+func LookupCtx[T any](p *Process, key string) (T, bool) {
+	c, ok := p.Context[key].(T)
+	return c, ok
+}
+
+// SetContextFields sets the fields of the process context from their string representation, e.g. X=7.
+// Field names are case-insensitive. The context must be a structure or a pointer to it.
+func (p *Process) SetContextFields(key string, fields map[string]string) error {
+	c, ok := p.Context[key]
+	if !ok {
+		return fmt.Errorf("process %v has no context %v", p.Node, key)
+	}
+	v := reflect.ValueOf(c)
+	s := v
+	switch {
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct:
+		s = v.Elem()
+	case v.Kind() == reflect.Struct:
+		s = reflect.New(v.Type()).Elem()
+		s.Set(v)
+	default:
+		return fmt.Errorf("context %v of type %T has no fields", key, c)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := s.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if !f.IsValid() || !f.CanSet() {
+			return fmt.Errorf("context %v has no field %v", key, name)
+		}
+		if err := setField(f, fields[name]); err != nil {
+			return fmt.Errorf("context %v: field %v: %v", key, name, err)
+		}
+	}
+
+	if v.Kind() == reflect.Struct {
+		p.Context[key] = s.Interface()
+	}
+	return nil
+}
+
+func setField(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(x)
+	default:
+		return fmt.Errorf("unsupported type %v", f.Type())
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/trmigor/distr-model/user/context"
+)
+
+type testContext struct {
+	X     int
+	Name  string
+	Rate  float64
+	On    bool
+	Count uint8
+	hide  int
+}
+
+func TestCtx(t *testing.T) {
+	p := NewPassive(1)
+	p.Context["Test"] = &testContext{X: 1}
+	Ctx[*testContext](p, "Test").X = 2
+	if got := p.Context["Test"].(*testContext).X; got != 2 {
+		t.Errorf("Ctx() changed X to %v, want 2", got)
+	}
+	if _, ok := LookupCtx[*testContext](p, "None"); ok {
+		t.Errorf("LookupCtx() found an absent context")
+	}
+	if _, ok := LookupCtx[testContext](p, "Test"); ok {
+		t.Errorf("LookupCtx() found a context of another type")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Ctx() did not panic on a context of another type")
+		}
+	}()
+	Ctx[int](p, "Test")
+}
+
+func TestProcess_Context(t *testing.T) {
+	context.Contexts["Test"] = func(node int32) context.Context { return &testContext{X: int(node)} }
+	defer delete(context.Contexts, "Test")
+	p, q := NewPassive(1), NewPassive(2)
+	if p.Context["Test"] == q.Context["Test"] {
+		t.Errorf("processes share the Test context")
+	}
+	if got := Ctx[*testContext](q, "Test").X; got != 2 {
+		t.Errorf("Ctx().X = %v, want 2", got)
+	}
+}
+
+func TestProcess_SetContextFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		context interface{}
+		fields  map[string]string
+		want    testContext
+		wantErr bool
+	}{
+		{"Pointer", &testContext{}, map[string]string{"X": "7", "name": "a", "RATE": "0.5", "on": "true", "Count": "3"},
+			testContext{X: 7, Name: "a", Rate: 0.5, On: true, Count: 3}, false},
+		{"Value", testContext{Name: "b"}, map[string]string{"X": "-1"}, testContext{X: -1, Name: "b"}, false},
+		{"NoField", &testContext{}, map[string]string{"Y": "1"}, testContext{}, true},
+		{"Unexported", &testContext{}, map[string]string{"hide": "1"}, testContext{}, true},
+		{"BadValue", &testContext{}, map[string]string{"X": "a"}, testContext{}, true},
+		{"Overflow", &testContext{}, map[string]string{"Count": "256"}, testContext{}, true},
+		{"NotStruct", 1, map[string]string{"X": "1"}, testContext{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPassive(1)
+			p.Context["Test"] = tt.context
+			err := p.SetContextFields("Test", tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process.SetContextFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, ok := p.Context["Test"].(testContext)
+			if !ok {
+				got = *p.Context["Test"].(*testContext)
+			}
+			if got != tt.want {
+				t.Errorf("Process.SetContextFields() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if err := NewPassive(1).SetContextFields("None", nil); err == nil {
+		t.Errorf("Process.SetContextFields() succeeded for an absent context")
+	}
+}
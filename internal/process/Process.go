@@ -1,6 +1,7 @@
 package process
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/trmigor/distr-model/internal/messages"
@@ -12,6 +13,10 @@ import (
 // WorkFunction represents a process working function.
 type WorkFunction func(context *Process, m *messages.Message) bool
 
+// Middleware wraps the handling of messages by the working functions: it gets every message
+// before them and calls handle to pass the message on. It returns whether the message has been accepted.
+type Middleware func(context *Process, m *messages.Message, handle func() bool) bool
+
 // Process models a real distributed process.
 type Process struct {
 	MessagesQueue *messages.MessageQueue
@@ -20,29 +25,44 @@ type Process struct {
 	Context       map[string]context.Context
 	workerThread  chan bool
 	stopFlag      bool
+	passive       bool
 	workers       []WorkFunction
+	hooks         []WorkFunction
+	middleware    []Middleware
+	unhandled     int64
 }
 
 // New returns a valid Process instance.
 func New(node int32) *Process {
+	res := NewPassive(node)
+	res.passive = false
+	go workerThreadExecutor(res)
+	return res
+}
+
+// NewPassive returns a valid Process instance without the worker goroutine.
+// Its messages should be passed to Handle by the process user.
+func NewPassive(node int32) *Process {
 	res := &Process{
 		MessagesQueue: messages.NewMessageQueue(),
 		Node:          node,
 		Context:       make(map[string]context.Context),
 		workerThread:  make(chan bool),
+		passive:       true,
 		workers:       make([]WorkFunction, 0),
 	}
-	for key, value := range context.Contexts {
-		res.Context[key] = value
+	for key, create := range context.Contexts {
+		res.Context[key] = create(node)
 	}
-	go workerThreadExecutor(res)
 	return res
 }
 
 // Stop terminates the worker goroutine.
 func (p *Process) Stop() {
 	p.stopFlag = true
-	<-p.workerThread
+	if !p.passive {
+		<-p.workerThread
+	}
 }
 
 // Neibs returns all the neighbours of the process in its network.
@@ -50,11 +70,40 @@ func (p *Process) Neibs() *set.Set {
 	return p.Network.Neibs(p.Node)
 }
 
+// Neighbours returns a sorted list of all the neighbours of the process in its network.
+func (p *Process) Neighbours() []int32 {
+	return p.Network.Neighbours(p.Node)
+}
+
+// Others returns a sorted list of neighbours of the process except itself.
+func (p *Process) Others() []int32 {
+	res := make([]int32, 0)
+	for _, v := range p.Neighbours() {
+		if v != p.Node {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 // RegisterWorkFunction registers a working function for the process.
 func (p *Process) RegisterWorkFunction(prefix []byte, wf WorkFunction) {
 	p.workers = append(p.workers, wf)
 }
 
+// AddHook registers a function called for every message before the working functions.
+// If the hook accepts the message, the working functions do not get it.
+// Hooks implement services common to all the algorithms, such as snapshots.
+func (p *Process) AddHook(hook WorkFunction) {
+	p.hooks = append(p.hooks, hook)
+}
+
+// Use wraps the working functions into a middleware. Middleware added later is called first.
+// Middleware implements services observing the algorithms, such as termination detection.
+func (p *Process) Use(mw Middleware) {
+	p.middleware = append(p.middleware, mw)
+}
+
 // IsMyMessage checks whether a message is for the process.
 func (p *Process) IsMyMessage(prefix []byte, message []byte) bool {
 	if len(message) > 0 && message[0] == '*' {
@@ -71,15 +120,55 @@ func (p *Process) IsMyMessage(prefix []byte, message []byte) bool {
 	return message[len(prefix)] == '_'
 }
 
+// Handle passes the message to the process working functions until one of them accepts it.
+// Routed messages to other processes are forwarded by the network instead.
+// It returns false if no working function has accepted the message, such messages are counted.
+func (p *Process) Handle(m *messages.Message) bool {
+	defer p.Network.Busy()()
+	defer p.Network.Handling(p.Node, m)()
+	if m.To >= 0 && m.To != p.Node {
+		p.Network.Forward(p.Node, m)
+		return true
+	}
+	p.Network.Deliver(m)
+	for _, hook := range p.hooks {
+		m.Ptr = 0
+		if hook(p, m) {
+			return true
+		}
+	}
+	handle := func() bool {
+		m.Ptr = 0
+		for _, worker := range p.workers {
+			if worker(p, m) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, mw := range p.middleware {
+		mw, next := mw, handle
+		handle = func() bool {
+			m.Ptr = 0
+			return mw(p, m, next)
+		}
+	}
+	if !handle() {
+		atomic.AddInt64(&p.unhandled, 1)
+		return false
+	}
+	return true
+}
+
+// Unhandled returns the number of delivered messages no working function has accepted.
+func (p *Process) Unhandled() int64 {
+	return atomic.LoadInt64(&p.unhandled)
+}
+
 func workerThreadExecutor(dp *Process) {
 	for !dp.stopFlag {
 		if dp.MessagesQueue.Size() > 0 && dp.Network.Tick >= dp.MessagesQueue.Peek().DeliveryTime {
-			m := dp.MessagesQueue.Dequeue()
-			for _, worker := range dp.workers {
-				if worker(dp, m) {
-					break
-				}
-			}
+			dp.Handle(dp.MessagesQueue.Dequeue())
 		}
 		time.Sleep(time.Millisecond)
 	}
@@ -95,3 +184,27 @@ func (p *Process) NetworkLayer() **network.Network {
 func (p *Process) WorkerMessagesQueue() *messages.MessageQueue {
 	return p.MessagesQueue
 }
+
+// LamportTime returns the Lamport time of the process or 0 if logical clocks are disabled in its network.
+func (p *Process) LamportTime() int64 {
+	if c := p.Network.Clock(p.Node); c != nil {
+		return c.Time()
+	}
+	return 0
+}
+
+// VectorClock returns a copy of the vector clock of the process or nil if vector clocks are disabled in its network.
+func (p *Process) VectorClock() []int64 {
+	if c := p.Network.Clock(p.Node); c != nil {
+		return c.Vector()
+	}
+	return nil
+}
+
+// MatrixClock returns a copy of the matrix clock of the process or nil if matrix clocks are disabled in its network.
+func (p *Process) MatrixClock() [][]int64 {
+	if c := p.Network.Clock(p.Node); c != nil {
+		return c.Matrix()
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/trmigor/distr-model/internal/clock"
 	"github.com/trmigor/distr-model/internal/messages"
 	"github.com/trmigor/distr-model/internal/network"
 	"github.com/trmigor/distr-model/pkg/set"
@@ -113,3 +114,167 @@ func Test_workerThreadExecutor(t *testing.T) {
 		time.Sleep(500 * time.Millisecond)
 	})
 }
+
+func TestProcess_Handle(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers []WorkFunction
+		want    bool
+		calls   int
+	}{
+		{"None", nil, false, 0},
+		{"Declined", []WorkFunction{
+			func(context *Process, m *messages.Message) bool { return false },
+		}, false, 1},
+		{"Accepted", []WorkFunction{
+			func(context *Process, m *messages.Message) bool { return false },
+			func(context *Process, m *messages.Message) bool { return true },
+			func(context *Process, m *messages.Message) bool { return true },
+		}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := network.NewVirtual()
+			defer nl.Stop()
+			p := NewPassive(0)
+			defer p.Stop()
+			nl.RegisterProcess(0, p)
+			calls := 0
+			for _, w := range tt.workers {
+				w := w
+				p.RegisterWorkFunction(nil, func(context *Process, m *messages.Message) bool {
+					calls++
+					return w(context, m)
+				})
+			}
+			if got := p.Handle(messages.NewMessage(-1, 0, nil)); got != tt.want {
+				t.Errorf("Process.Handle() = %v, want %v", got, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("Process.Handle(): %v calls, want %v", calls, tt.calls)
+			}
+			unhandled := int64(0)
+			if !tt.want {
+				unhandled = 1
+			}
+			if got := p.Unhandled(); got != unhandled {
+				t.Errorf("Process.Unhandled() = %v, want %v", got, unhandled)
+			}
+		})
+	}
+}
+
+func TestProcess_AddHook(t *testing.T) {
+	nl := network.NewVirtual()
+	defer nl.Stop()
+	p := NewPassive(0)
+	nl.RegisterProcess(0, p)
+	calls := 0
+	p.RegisterWorkFunction(nil, func(context *Process, m *messages.Message) bool {
+		calls++
+		return true
+	})
+	p.AddHook(func(context *Process, m *messages.Message) bool {
+		return string(m.GetString()) == "HOOK"
+	})
+	p.Handle(messages.NewMessageByArgs(messages.NewMessageArg([]byte("HOOK"))))
+	if calls != 0 {
+		t.Errorf("Process.Handle() passed the accepted message to the working functions")
+	}
+	p.Handle(messages.NewMessageByArgs(messages.NewMessageArg([]byte("WORK"))))
+	if calls != 1 {
+		t.Errorf("Process.Handle() did not pass the declined message to the working functions")
+	}
+}
+
+func TestProcess_Use(t *testing.T) {
+	nl := network.NewVirtual()
+	defer nl.Stop()
+	p := NewPassive(0)
+	nl.RegisterProcess(0, p)
+	var calls []string
+	p.RegisterWorkFunction(nil, func(context *Process, m *messages.Message) bool {
+		calls = append(calls, "work "+string(m.GetString()))
+		return true
+	})
+	for _, name := range []string{"inner", "outer"} {
+		name := name
+		p.Use(func(context *Process, m *messages.Message, handle func() bool) bool {
+			calls = append(calls, name+" "+string(m.GetString()))
+			if string(m.Type()) == "SKIP" {
+				return false
+			}
+			return handle()
+		})
+	}
+	tests := []struct {
+		msg   string
+		want  bool
+		calls []string
+	}{
+		{"WORK", true, []string{"outer WORK", "inner WORK", "work WORK"}},
+		{"SKIP", false, []string{"outer SKIP"}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		if got := p.Handle(messages.NewMessageByArgs(messages.NewMessageArg([]byte(tt.msg)))); got != tt.want {
+			t.Errorf("Process.Handle(%v) = %v, want %v", tt.msg, got, tt.want)
+		}
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("Process.Handle(%v) calls = %v, want %v", tt.msg, calls, tt.calls)
+		}
+	}
+}
+
+func TestProcess_Neighbours(t *testing.T) {
+	nl := network.NewVirtual()
+	defer nl.Stop()
+	p := NewPassive(0)
+	defer p.Stop()
+	nl.RegisterProcess(0, p)
+	nl.CreateLink(0, 2, false, 1)
+	nl.CreateLink(0, 1, false, 1)
+	if got, want := p.Neighbours(), []int32{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Process.Neighbours() = %v, want %v", got, want)
+	}
+}
+
+func TestProcess_Others(t *testing.T) {
+	nl := network.NewVirtual()
+	defer nl.Stop()
+	p := NewPassive(1)
+	defer p.Stop()
+	nl.RegisterProcess(1, p)
+	nl.CreateLink(1, 2, false, 1)
+	nl.CreateLink(1, 1, false, 1)
+	nl.CreateLink(1, 0, false, 1)
+	if got, want := p.Others(), []int32{0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Process.Others() = %v, want %v", got, want)
+	}
+}
+
+func TestProcess_Clocks(t *testing.T) {
+	nl := network.NewVirtual()
+	defer nl.Stop()
+	ps := []*Process{NewPassive(0), NewPassive(1)}
+	for i, p := range ps {
+		nl.RegisterProcess(int32(i), p)
+		p.RegisterWorkFunction(nil, func(context *Process, m *messages.Message) bool { return true })
+	}
+	nl.CreateLink(0, 1, true, 1)
+	if ps[0].LamportTime() != 0 || ps[0].VectorClock() != nil || ps[0].MatrixClock() != nil {
+		t.Errorf("Process clocks are not empty without logical clocks")
+	}
+	nl.EnableClocks(clock.Matrix)
+	nl.SendMessage(0, 1, messages.NewMessageByArgs(messages.NewMessageArg([]byte("A"))))
+	ps[1].Handle(ps[1].MessagesQueue.Dequeue())
+	if got := ps[1].LamportTime(); got != 2 {
+		t.Errorf("Process.LamportTime() = %v, want 2", got)
+	}
+	if got := ps[1].VectorClock(); !reflect.DeepEqual(got, []int64{1, 1}) {
+		t.Errorf("Process.VectorClock() = %v, want [1 1]", got)
+	}
+	if got := ps[1].MatrixClock(); !reflect.DeepEqual(got, [][]int64{{1}, {1, 1}}) {
+		t.Errorf("Process.MatrixClock() = %v, want [[1] [1 1]]", got)
+	}
+}
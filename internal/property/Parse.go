@@ -0,0 +1,266 @@
+package property
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+// Parse parses a predicate over the contexts of the processes written as clauses joined by "and":
+//
+//	all cond | some cond | none cond | atmost n cond | atleast n cond | equal ref
+//
+// A condition is "ref", which holds if the value is true or non-zero, or "ref op value" with op
+// one of = != < <= > >=. A reference Key.Member is a field or a method without arguments of the context
+// with the key, names are case-insensitive. Words are separated by spaces, e.g. "atmost 1 LCR.IsLeader"
+// or "equal LCR.Leader and none LCR.Leader = -1". Processes without the context are not taken into account,
+// conditions on members the context does not have never hold.
+func Parse(s string) (Predicate, error) {
+	words := strings.Fields(s)
+	res := make([]Predicate, 0)
+	for len(words) > 0 {
+		end := len(words)
+		for i, w := range words {
+			if w == "and" {
+				end = i
+				break
+			}
+		}
+		p, err := parseClause(words[:end])
+		if err != nil {
+			return nil, fmt.Errorf("predicate %q: %v", s, err)
+		}
+		res = append(res, p)
+		if end == len(words) {
+			break
+		}
+		words = words[end+1:]
+		if len(words) == 0 {
+			return nil, fmt.Errorf("predicate %q: no clause after and", s)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("empty predicate")
+	}
+	return func(ps []*process.Process) bool {
+		for _, p := range res {
+			if !p(ps) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseClause(words []string) (Predicate, error) {
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty clause")
+	}
+	switch q, rest := words[0], words[1:]; q {
+	case "all", "some", "none":
+		c, err := parseCond(rest)
+		if err != nil {
+			return nil, err
+		}
+		return func(ps []*process.Process) bool {
+			n, total := c.count(ps)
+			return q == "all" && n == total || q == "some" && n > 0 || q == "none" && n == 0
+		}, nil
+	case "atmost", "atleast":
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("%v without a number", q)
+		}
+		k, err := strconv.Atoi(rest[0])
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", q, err)
+		}
+		c, err := parseCond(rest[1:])
+		if err != nil {
+			return nil, err
+		}
+		return func(ps []*process.Process) bool {
+			n, _ := c.count(ps)
+			return q == "atmost" && n <= k || q == "atleast" && n >= k
+		}, nil
+	case "equal":
+		if len(rest) != 1 {
+			return nil, fmt.Errorf("equal takes a single reference")
+		}
+		r, err := parseRef(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		return r.equal, nil
+	default:
+		return nil, fmt.Errorf("unknown quantifier %v", q)
+	}
+}
+
+// ref is a reference to a member of a context.
+type ref struct {
+	key    string
+	member string
+}
+
+func parseRef(s string) (ref, error) {
+	i := strings.IndexByte(s, '.')
+	if i <= 0 || i == len(s)-1 {
+		return ref{}, fmt.Errorf("%q is not Context.Member", s)
+	}
+	return ref{key: s[:i], member: s[i+1:]}, nil
+}
+
+// value returns the value of the member of the process context. The second result is false
+// if the process has no such context, the value is invalid if the context has no such member.
+func (r ref) value(p *process.Process) (reflect.Value, bool) {
+	c, ok := p.Context[r.key]
+	if !ok || c == nil {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(c)
+	s := v
+	for s.Kind() == reflect.Ptr && !s.IsNil() {
+		s = s.Elem()
+	}
+	if s.Kind() == reflect.Struct {
+		f := s.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, r.member) })
+		if f.IsValid() && f.CanInterface() {
+			return f, true
+		}
+	}
+	for i := 0; i < v.NumMethod(); i++ {
+		m := v.Method(i)
+		if strings.EqualFold(v.Type().Method(i).Name, r.member) && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+			return m.Call(nil)[0], true
+		}
+	}
+	return reflect.Value{}, true
+}
+
+// equal reports whether all the processes with the context have the same value of the member.
+func (r ref) equal(ps []*process.Process) bool {
+	var first reflect.Value
+	for _, p := range ps {
+		v, ok := r.value(p)
+		switch {
+		case !ok:
+		case !v.IsValid():
+			return false
+		case !first.IsValid():
+			first = v
+		case !reflect.DeepEqual(first.Interface(), v.Interface()):
+			return false
+		}
+	}
+	return true
+}
+
+// cond is a condition on a member of a context.
+type cond struct {
+	ref   ref
+	op    string
+	value string
+}
+
+func parseCond(words []string) (cond, error) {
+	if len(words) != 1 && len(words) != 3 {
+		return cond{}, fmt.Errorf("condition %q is not ref or ref op value", strings.Join(words, " "))
+	}
+	r, err := parseRef(words[0])
+	if err != nil {
+		return cond{}, err
+	}
+	c := cond{ref: r}
+	if len(words) == 3 {
+		c.op, c.value = words[1], words[2]
+		switch c.op {
+		case "=", "!=", "<", "<=", ">", ">=":
+		default:
+			return cond{}, fmt.Errorf("unknown operator %v", c.op)
+		}
+	}
+	return c, nil
+}
+
+// count returns the number of the processes satisfying the condition and the number of the processes
+// with the context.
+func (c cond) count(ps []*process.Process) (int, int) {
+	n, total := 0, 0
+	for _, p := range ps {
+		v, ok := c.ref.value(p)
+		if !ok {
+			continue
+		}
+		total++
+		if c.holds(v) {
+			n++
+		}
+	}
+	return n, total
+}
+
+func (c cond) holds(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if c.op == "" {
+		if v.Kind() == reflect.Bool {
+			return v.Bool()
+		}
+		return !v.IsZero()
+	}
+	d, ok := compare(v, c.value)
+	if !ok || c.op != "=" && c.op != "!=" && !ordered(v.Kind()) {
+		return false
+	}
+	switch c.op {
+	case "=":
+		return d == 0
+	case "!=":
+		return d != 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case ">":
+		return d > 0
+	}
+	return d >= 0
+}
+
+// ordered reports whether the values of the kind are ordered.
+func ordered(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64 || k == reflect.String
+}
+
+// compare compares the value with the literal parsed according to the value type, values of other types
+// than scalars are compared by their formatting. The second result is false if the literal is not of the type.
+func compare(v reflect.Value, literal string) (int, bool) {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(literal)
+		if err != nil || b != v.Bool() {
+			return 1, err == nil
+		}
+		return 0, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(literal, 0, 64)
+		return cmp.Compare(v.Int(), x), err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(literal, 0, 64)
+		return cmp.Compare(v.Uint(), x), err == nil
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(literal, 64)
+		return cmp.Compare(v.Float(), x), err == nil
+	case reflect.String:
+		return strings.Compare(v.String(), literal), true
+	}
+	if fmt.Sprint(v.Interface()) == literal {
+		return 0, true
+	}
+	return 1, true
+}
@@ -0,0 +1,224 @@
+// Package property checks global properties of model runs: predicates over the contexts of all the processes.
+// Invariants (safety properties) must hold in every state of a run, eventual properties (liveness ones)
+// must hold in some state not later than their deadlines. A violation comes with a counterexample:
+// the events leading to it and the contexts of the processes at that moment.
+package property
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Predicate is a global predicate over the processes.
+type Predicate func(ps []*process.Process) bool
+
+// Kind is a kind of properties.
+type Kind int
+
+const (
+	// Invariant marks properties which must hold in every state.
+	Invariant Kind = iota
+	// Eventually marks properties which must hold in some state not later than the deadline.
+	Eventually
+)
+
+func (k Kind) String() string {
+	if k == Eventually {
+		return "eventually"
+	}
+	return "invariant"
+}
+
+// Property is a named predicate checked during a run.
+type Property struct {
+	Name string
+	Kind Kind
+	// Deadline is the tick by which an eventual property must hold, 0 means the end of the run.
+	Deadline  int64
+	Predicate Predicate
+}
+
+// Result is the outcome of checking a property.
+type Result struct {
+	Name string
+	Kind Kind
+	// OK is false if the property has been violated.
+	OK bool
+	// Tick is the tick of the violation or the first tick an eventual property has held at, -1 if none.
+	Tick int64
+	// Events are the events up to the violation.
+	Events []trace.Event
+	// States are the contexts of the processes at the violation.
+	States []string
+}
+
+func (r Result) String() string {
+	switch {
+	case r.OK && r.Tick >= 0:
+		return fmt.Sprintf("eventually '%v' held at tick %v", r.Name, r.Tick)
+	case r.OK:
+		return fmt.Sprintf("%v '%v' holds", r.Kind, r.Name)
+	case r.Kind == Eventually:
+		return fmt.Sprintf("eventually '%v' unmet by tick %v", r.Name, r.Tick)
+	}
+	return fmt.Sprintf("invariant '%v' violated at tick %v", r.Name, r.Tick)
+}
+
+// WriteCounterexample writes the violation, the events leading to it and the states of the processes.
+func (r Result) WriteCounterexample(out io.Writer) error {
+	if _, err := fmt.Fprintf(out, "%v\nevents:\n", r); err != nil {
+		return err
+	}
+	for _, e := range r.Events {
+		if _, err := fmt.Fprintf(out, "%v\n", e); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(out, "states:\n%v\n", strings.Join(r.States, "\n"))
+	return err
+}
+
+// Checker evaluates properties in the states of a run and records its events for counterexamples.
+type Checker struct {
+	mutex   sync.Mutex
+	events  []trace.Event
+	checked []*checked
+}
+
+// checked is a property with the outcome of its checks so far.
+type checked struct {
+	property Property
+	result   Result
+	done     bool
+}
+
+// NewChecker creates a checker without properties.
+func NewChecker() *Checker {
+	return &Checker{
+		events:  make([]trace.Event, 0),
+		checked: make([]*checked, 0),
+	}
+}
+
+// Add starts checking the property.
+func (c *Checker) Add(p Property) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.checked = append(c.checked, &checked{
+		property: p,
+		result:   Result{Name: p.Name, Kind: p.Kind, OK: true, Tick: -1},
+	})
+}
+
+// Record implements trace.Tracer.
+func (c *Checker) Record(e trace.Event) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.events = append(c.events, e)
+}
+
+// Check evaluates the properties not decided yet in the current state of the processes at the tick.
+// Eventual properties which have not held by their deadlines are violated.
+func (c *Checker) Check(tick int64, ps []*process.Process) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ps = present(ps)
+	for _, p := range c.checked {
+		switch {
+		case p.done:
+		case p.property.Kind == Invariant:
+			if !p.property.Predicate(ps) {
+				c.violate(p, tick, ps)
+			}
+		case p.property.Deadline > 0 && tick > p.property.Deadline:
+			c.violate(p, p.property.Deadline, ps)
+		case p.property.Predicate(ps):
+			p.result.Tick, p.done = tick, true
+		}
+	}
+}
+
+// Finish ends the run at the tick: eventual properties which have never held are violated.
+func (c *Checker) Finish(tick int64, ps []*process.Process) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ps = present(ps)
+	for _, p := range c.checked {
+		if !p.done && p.property.Kind == Eventually {
+			end := tick
+			if p.property.Deadline > 0 && p.property.Deadline < end {
+				end = p.property.Deadline
+			}
+			c.violate(p, end, ps)
+		}
+	}
+}
+
+// violate records the violation of the property at the tick with its counterexample.
+func (c *Checker) violate(p *checked, tick int64, ps []*process.Process) {
+	p.result.OK, p.result.Tick, p.done = false, tick, true
+	p.result.Events = make([]trace.Event, 0)
+	for _, e := range c.events {
+		if e.Tick <= tick {
+			p.result.Events = append(p.result.Events, e)
+		}
+	}
+	p.result.States = States(ps)
+}
+
+// Results returns the outcomes of the properties in the order of their addition.
+func (c *Checker) Results() []Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := make([]Result, 0, len(c.checked))
+	for _, p := range c.checked {
+		res = append(res, p.result)
+	}
+	return res
+}
+
+// Violations returns the outcomes of the violated properties.
+func (c *Checker) Violations() []Result {
+	res := make([]Result, 0)
+	for _, r := range c.Results() {
+		if !r.OK {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// States formats the contexts of the processes, one line per process with the contexts sorted by keys.
+func States(ps []*process.Process) []string {
+	res := make([]string, 0, len(ps))
+	for _, p := range present(ps) {
+		keys := make([]string, 0, len(p.Context))
+		for key := range p.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		s := fmt.Sprintf("process %v:", p.Node)
+		for _, key := range keys {
+			s += fmt.Sprintf(" %v=%+v", key, p.Context[key])
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// present returns the processes without the absent nodes.
+func present(ps []*process.Process) []*process.Process {
+	res := make([]*process.Process, 0, len(ps))
+	for _, p := range ps {
+		if p != nil {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,152 @@
+package property
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+type state struct {
+	N      int32
+	Name   string
+	On     bool
+	Rate   float64
+	Items  []int
+	hidden int
+}
+
+func (s *state) Even() bool {
+	return s.N%2 == 0
+}
+
+// processes creates processes with the states, nil states leave the processes without the context.
+func processes(states ...*state) []*process.Process {
+	res := make([]*process.Process, 0, len(states))
+	for i, s := range states {
+		p := process.NewPassive(int32(i))
+		if s != nil {
+			p.Context["S"] = s
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+func TestParse(t *testing.T) {
+	ps := processes(
+		&state{N: 1, Name: "a", On: true, Rate: 0.5, Items: []int{1}},
+		&state{N: 2, Name: "b", Rate: 0.5, Items: []int{1}},
+		nil,
+		&state{N: 4, Name: "b", On: true, Rate: 1.5, Items: []int{1}},
+	)
+	tests := []struct {
+		name      string
+		predicate string
+		want      bool
+		wantErr   bool
+	}{
+		{"All", "all S.N > 0", true, false},
+		{"All false", "all S.On", false, false},
+		{"Some", "some S.Name = a", true, false},
+		{"None", "none S.N >= 5", true, false},
+		{"None false", "none S.n <= 1", false, false},
+		{"At most", "atmost 2 S.On", true, false},
+		{"At most false", "atmost 1 S.On", false, false},
+		{"At least", "atleast 2 S.Even", true, false},
+		{"Method", "all S.even = true", false, false},
+		{"Float", "atleast 2 S.Rate < 1", true, false},
+		{"String order", "all S.Name < c", true, false},
+		{"Bool order", "some S.On > false", false, false},
+		{"Equal", "equal S.Items", true, false},
+		{"Equal false", "equal S.Name", false, false},
+		{"Formatted", "all S.Items = [1]", true, false},
+		{"And", "some S.On and equal S.Items and all S.N != 3", true, false},
+		{"And false", "some S.On and equal S.Name", false, false},
+		{"Missing context", "all T.N = 1", true, false},
+		{"Missing member", "some S.M", false, false},
+		{"Unexported member", "some S.hidden = 0", false, false},
+		{"Literal of another type", "some S.N = x", false, false},
+		{"Empty", "", false, true},
+		{"Unknown quantifier", "every S.N", false, true},
+		{"No reference", "all N", false, true},
+		{"Unknown operator", "all S.N == 1", false, true},
+		{"Incomplete condition", "all S.N >", false, true},
+		{"No number", "atmost S.N", false, true},
+		{"Trailing and", "all S.N and", false, true},
+		{"Equal with operator", "equal S.N = 1", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.predicate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p(ps) != tt.want {
+				t.Errorf("Parse()() = %v, want %v", !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker(t *testing.T) {
+	s := []*state{{N: 0}, {N: 0}}
+	ps := processes(s...)
+	positive, _ := Parse("all S.N > 0")
+	small, _ := Parse("all S.N < 3")
+	c := NewChecker()
+	c.Add(Property{Name: "small", Kind: Invariant, Predicate: small})
+	c.Add(Property{Name: "positive", Kind: Eventually, Predicate: positive})
+	c.Add(Property{Name: "late", Kind: Eventually, Deadline: 5, Predicate: positive})
+	c.Add(Property{Name: "never", Kind: Eventually, Deadline: 20, Predicate: func([]*process.Process) bool { return false }})
+
+	c.Check(0, ps)
+	c.Record(trace.Event{Tick: 1, Kind: trace.Deliver, From: 0, To: 1})
+	s[0].N, s[1].N = 1, 1
+	c.Record(trace.Event{Tick: 7, Kind: trace.Deliver, From: 1, To: 0})
+	c.Check(7, ps)
+	c.Record(trace.Event{Tick: 9, Kind: trace.Deliver, From: 1, To: 0})
+	s[1].N = 3
+	c.Check(9, ps)
+	s[1].N = 4
+	c.Check(10, ps)
+	c.Finish(12, ps)
+
+	want := []struct {
+		ok     bool
+		tick   int64
+		events int
+	}{
+		{false, 9, 3},
+		{true, 7, 0},
+		{false, 5, 1},
+		{false, 12, 3},
+	}
+	results := c.Results()
+	if len(results) != len(want) {
+		t.Fatalf("Checker.Results() = %v, want %v results", results, len(want))
+	}
+	for i, r := range results {
+		if r.OK != want[i].ok || r.Tick != want[i].tick || len(r.Events) != want[i].events {
+			t.Errorf("Checker.Results()[%v] = %v with %v events, want %+v", i, r, len(r.Events), want[i])
+		}
+	}
+	if v := c.Violations(); len(v) != 3 || v[0].Name != "small" {
+		t.Errorf("Checker.Violations() = %v, want small, late and never", v)
+	}
+	if got := results[0].States; len(got) != 2 || !strings.Contains(got[1], "N:3") {
+		t.Errorf("Checker.Results()[0].States = %v, want the state at the violation", got)
+	}
+
+	var b bytes.Buffer
+	if err := results[2].WriteCounterexample(&b); err != nil {
+		t.Fatalf("Result.WriteCounterexample() error = %v", err)
+	}
+	for _, s := range []string{"eventually 'late' unmet by tick 5", "deliver 0 -> 1", "process 1: Common=&{} S=&{N:1"} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("Result.WriteCounterexample() = %q, want %q in it", b.String(), s)
+		}
+	}
+}
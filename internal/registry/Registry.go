@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Algorithm is a work function registered together with the contexts it uses.
+type Algorithm struct {
+	// Name is used to assign the work function to processes, e.g. by setprocesses directive.
+	Name string
+	// Description is a short human-readable summary of the algorithm.
+	Description string
+	// Work is the work function.
+	Work process.WorkFunction
+	// Contexts creates the contexts used by the work function, by context names.
+	Contexts map[string]context.Factory
+	// FIFO reports whether the algorithm requires FIFO links.
+	FIFO bool
+}
+
+var (
+	mutex      sync.RWMutex
+	algorithms = make(map[string]Algorithm)
+)
+
+// Register makes an algorithm available by its name.
+// It is intended to be called from init functions of algorithm packages
+// and panics if the name is empty or already registered.
+func Register(a Algorithm) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if a.Name == "" || a.Work == nil {
+		panic("registry: algorithm without name or work function")
+	}
+	if _, ok := algorithms[a.Name]; ok {
+		panic(fmt.Sprintf("registry: algorithm %v is registered twice", a.Name))
+	}
+	algorithms[a.Name] = a
+}
+
+// Lookup returns the algorithm registered by the name.
+func Lookup(name string) (Algorithm, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	a, ok := algorithms[name]
+	return a, ok
+}
+
+// Names returns the sorted names of all registered algorithms.
+func Names() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	res := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// All returns all registered algorithms sorted by names.
+func All() []Algorithm {
+	names := Names()
+	res := make([]Algorithm, 0, len(names))
+	for _, name := range names {
+		a, _ := Lookup(name)
+		res = append(res, a)
+	}
+	return res
+}
+
+// Context returns the factory of the context by its name.
+// Contexts common to all processes are looked up first, then the contexts of registered algorithms.
+func Context(name string) (context.Factory, bool) {
+	if f, ok := context.Contexts[name]; ok {
+		return f, true
+	}
+	for _, a := range All() {
+		if f, ok := a.Contexts[name]; ok {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// ContextNames returns the sorted names of all known contexts.
+func ContextNames() []string {
+	names := make(map[string]bool)
+	for name := range context.Contexts {
+		names[name] = true
+	}
+	for _, a := range All() {
+		for name := range a.Contexts {
+			names[name] = true
+		}
+	}
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+func work(context *process.Process, m *messages.Message) bool {
+	return true
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+		wantPanic bool
+	}{
+		{"Valid", Algorithm{Name: "TEST_A", Work: work}, false},
+		{"WithContext", Algorithm{Name: "TEST_B", Work: work, Contexts: map[string]context.Factory{
+			"B": func(int32) context.Context { return 1 },
+		}}, false},
+		{"Duplicate", Algorithm{Name: "TEST_A", Work: work}, true},
+		{"NoName", Algorithm{Work: work}, true},
+		{"NoWork", Algorithm{Name: "TEST_C"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("Register() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			Register(tt.algorithm)
+			if a, ok := Lookup(tt.algorithm.Name); !ok || a.Name != tt.algorithm.Name {
+				t.Errorf("Lookup() = %v, %v", a, ok)
+			}
+		})
+	}
+
+	if _, ok := Lookup("TEST_C"); ok {
+		t.Errorf("Lookup() found an invalid algorithm")
+	}
+	if got, want := Names(), []string{"TEST_A", "TEST_B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if got := All(); len(got) != 2 || got[0].Name != "TEST_A" || got[1].Name != "TEST_B" {
+		t.Errorf("All() = %v", got)
+	}
+}
+
+func TestContext(t *testing.T) {
+	Register(Algorithm{Name: "TEST_CONTEXT", Work: work, Contexts: map[string]context.Factory{
+		"TestContext": func(node int32) context.Context { return node },
+	}})
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Common", true},
+		{"TestContext", true},
+		{"None", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := Context(tt.name); got != tt.want {
+				t.Errorf("Context() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if f, _ := Context("TestContext"); f(3) != int32(3) {
+		t.Errorf("Context() returned a wrong factory")
+	}
+	names := ContextNames()
+	found := 0
+	for _, name := range names {
+		if name == "Common" || name == "TestContext" {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("ContextNames() = %v", names)
+	}
+}
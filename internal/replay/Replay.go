@@ -0,0 +1,255 @@
+// Package replay records the nondeterministic choices of model runs and replays them: random numbers drawn
+// from the network generator (losses, Byzantine rewrites, random choices of algorithms), the order
+// and ticks of handled messages and the ticks of timer messages. A replay performs exactly the same run
+// in virtual time, even if it has been recorded in real time or the work functions log more.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+// Log is a recorded run.
+type Log struct {
+	// Draws are the values drawn from the network random source in order.
+	Draws []int64 `json:"draws"`
+	// Deliveries are the handled messages in order.
+	Deliveries []Delivery `json:"deliveries"`
+	// Timers are the timer messages in order.
+	Timers []Timer `json:"timers"`
+}
+
+// Delivery is a message handled by the process of the node at the tick.
+type Delivery struct {
+	Tick int64  `json:"tick"`
+	Node int32  `json:"node"`
+	From int32  `json:"from"`
+	To   int32  `json:"to"`
+	Body []byte `json:"body"`
+	// Message is the message in text, it is not used by replays.
+	Message string `json:"message,omitempty"`
+}
+
+func (d Delivery) String() string {
+	res := fmt.Sprintf("[%v] %v -> %v: %v", d.Tick, d.From, d.To, d.Message)
+	if d.Node != d.To {
+		res += fmt.Sprintf(" (at %v)", d.Node)
+	}
+	return res
+}
+
+// matches reports whether the message is the delivered one.
+func (d Delivery) matches(m *messages.Message) bool {
+	return m.From == d.From && m.To == d.To && string(m.Body) == string(d.Body)
+}
+
+// Timer is a timer message with the number sent at the tick.
+type Timer struct {
+	Tick    int64 `json:"tick"`
+	Current int32 `json:"current"`
+}
+
+// Read reads a log written by Write.
+func Read(in io.Reader) (*Log, error) {
+	l := &Log{}
+	if err := json.NewDecoder(in).Decode(l); err != nil {
+		return nil, fmt.Errorf("replay log: %v", err)
+	}
+	return l, nil
+}
+
+// Open reads the log from the file.
+func Open(name string) (*Log, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Write writes the log in JSON.
+func (l *Log) Write(out io.Writer) error {
+	return json.NewEncoder(out).Encode(l)
+}
+
+// Recorder records a run. It is a random source drawing from the source it wraps
+// and an observer of the network.
+type Recorder struct {
+	mutex  sync.Mutex
+	source rand.Source
+	log    Log
+}
+
+// NewRecorder creates a recorder drawing from the source.
+func NewRecorder(source rand.Source) *Recorder {
+	return &Recorder{
+		source: source,
+		log:    Log{Draws: make([]int64, 0), Deliveries: make([]Delivery, 0), Timers: make([]Timer, 0)},
+	}
+}
+
+// Int63 implements rand.Source.
+func (r *Recorder) Int63() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	v := r.source.Int63()
+	r.log.Draws = append(r.log.Draws, v)
+	return v
+}
+
+// Seed implements rand.Source.
+func (r *Recorder) Seed(seed int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.source.Seed(seed)
+}
+
+// Handle implements network.Observer.
+func (r *Recorder) Handle(tick int64, node int32, m *messages.Message) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.log.Deliveries = append(r.log.Deliveries, Delivery{
+		Tick:    tick,
+		Node:    node,
+		From:    m.From,
+		To:      m.To,
+		Body:    append([]byte{}, m.Body...),
+		Message: m.String(),
+	})
+}
+
+// Timer implements network.Observer.
+func (r *Recorder) Timer(tick int64, current int32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.log.Timers = append(r.log.Timers, Timer{Tick: tick, Current: current})
+}
+
+// Log returns the run recorded so far.
+func (r *Recorder) Log() *Log {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return &Log{
+		Draws:      append([]int64{}, r.log.Draws...),
+		Deliveries: append([]Delivery{}, r.log.Deliveries...),
+		Timers:     append([]Timer{}, r.log.Timers...),
+	}
+}
+
+// Player replays a log. It is a random source returning the recorded values, the deliveries and the timers
+// are taken by the world performing the replay. Values drawn beyond the recorded ones come from a fixed seed.
+type Player struct {
+	mutex      sync.Mutex
+	log        *Log
+	draws      int
+	extra      int
+	fallback   rand.Source
+	deliveries int
+	timers     int
+}
+
+// NewPlayer creates a player of the log.
+func NewPlayer(l *Log) *Player {
+	return &Player{log: l, fallback: rand.NewSource(1)}
+}
+
+// Int63 implements rand.Source.
+func (p *Player) Int63() int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.draws < len(p.log.Draws) {
+		p.draws++
+		return p.log.Draws[p.draws-1]
+	}
+	p.extra++
+	return p.fallback.Int63()
+}
+
+// Seed implements rand.Source, the recorded values do not depend on seeds.
+func (p *Player) Seed(int64) {}
+
+// Delivery returns the next recorded delivery, false if all of them have been performed.
+func (p *Player) Delivery() (Delivery, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.deliveries == len(p.log.Deliveries) {
+		return Delivery{}, false
+	}
+	return p.log.Deliveries[p.deliveries], true
+}
+
+// Find returns the index of the first of the messages which is the next recorded delivery, -1 if none is.
+func (p *Player) Find(ms []*messages.Message) int {
+	d, ok := p.Delivery()
+	if !ok {
+		return -1
+	}
+	for i, m := range ms {
+		if d.matches(m) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Delivered moves to the next recorded delivery.
+func (p *Player) Delivered() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.deliveries < len(p.log.Deliveries) {
+		p.deliveries++
+	}
+}
+
+// Timer returns the next recorded timer and moves to the following one if the timer is due at the tick.
+// The second result is false if the next timer is not due yet or all of them have been sent.
+func (p *Player) Timer(tick int64) (Timer, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.timers == len(p.log.Timers) || p.log.Timers[p.timers].Tick > tick {
+		return Timer{}, false
+	}
+	p.timers++
+	return p.log.Timers[p.timers-1], true
+}
+
+// Next returns the tick of the next recorded delivery or timer, false if there are none left.
+func (p *Player) Next() (int64, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var res int64
+	ok := false
+	if p.deliveries < len(p.log.Deliveries) {
+		res, ok = p.log.Deliveries[p.deliveries].Tick, true
+	}
+	if p.timers < len(p.log.Timers) && (!ok || p.log.Timers[p.timers].Tick < res) {
+		res, ok = p.log.Timers[p.timers].Tick, true
+	}
+	return res, ok
+}
+
+// Err reports how the replay has diverged from the recorded run: recorded deliveries, timers and draws
+// left over or values drawn beyond the recorded ones. It returns nil if the replay is exact.
+func (p *Player) Err() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	switch {
+	case p.deliveries < len(p.log.Deliveries):
+		return fmt.Errorf("recorded delivery %v of %v has not been performed: %v",
+			p.deliveries+1, len(p.log.Deliveries), p.log.Deliveries[p.deliveries])
+	case p.timers < len(p.log.Timers):
+		return fmt.Errorf("%v recorded timers have not been sent", len(p.log.Timers)-p.timers)
+	case p.draws < len(p.log.Draws):
+		return fmt.Errorf("%v recorded random values have not been drawn", len(p.log.Draws)-p.draws)
+	case p.extra > 0:
+		return fmt.Errorf("%v random values have been drawn beyond the recorded ones", p.extra)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package replay
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+func message(s string) *messages.Message {
+	return messages.NewMessage(0, 2, messages.NewMessageByArgs(messages.NewMessageArg([]byte(s))).Body)
+}
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(rand.NewSource(1))
+	rng := rand.New(r)
+	want := []float64{rng.Float64(), rng.Float64()}
+	r.Handle(3, 1, message("PING"))
+	r.Timer(5, 0)
+	l := r.Log()
+	if len(l.Draws) != 2 || len(l.Deliveries) != 1 || len(l.Timers) != 1 {
+		t.Fatalf("Recorder.Log() = %+v", l)
+	}
+	if got := l.Deliveries[0].String(); got != "[3] 0 -> 2: PING (at 1)" {
+		t.Errorf("Delivery.String() = %v", got)
+	}
+
+	var b bytes.Buffer
+	if err := l.Write(&b); err != nil {
+		t.Fatalf("Log.Write() error = %v", err)
+	}
+	read, err := Read(&b)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(read, l) {
+		t.Errorf("Read() = %+v, want %+v", read, l)
+	}
+	if _, err := Read(bytes.NewBufferString("draws")); err == nil {
+		t.Errorf("Read() of a malformed log error = nil")
+	}
+
+	p := NewPlayer(read)
+	rng = rand.New(p)
+	rng.Seed(42)
+	if got := []float64{rng.Float64(), rng.Float64()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Player draws %v, want %v", got, want)
+	}
+	if tick, ok := p.Next(); !ok || tick != 3 {
+		t.Errorf("Player.Next() = %v, %v, want 3, true", tick, ok)
+	}
+	if p.Find([]*messages.Message{message("PONG"), message("PING")}) != 1 {
+		t.Errorf("Player.Find() does not find the recorded message")
+	}
+	if p.Err() == nil {
+		t.Errorf("Player.Err() = nil with deliveries left")
+	}
+	p.Delivered()
+	if _, ok := p.Delivery(); ok {
+		t.Errorf("Player.Delivery() after the last one is ok")
+	}
+	if _, ok := p.Timer(4); ok {
+		t.Errorf("Player.Timer() before its tick is ok")
+	}
+	if timer, ok := p.Timer(5); !ok || timer != (Timer{Tick: 5}) {
+		t.Errorf("Player.Timer() = %v, %v, want the recorded timer", timer, ok)
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("Player.Err() = %v, want nil", err)
+	}
+	rng.Int63()
+	if p.Err() == nil {
+		t.Errorf("Player.Err() = nil with values drawn beyond the recorded ones")
+	}
+}
@@ -0,0 +1,160 @@
+// Package routing checks routing tables built by routing protocols by their traces.
+//
+// Routing protocols report every change of the routing table of a process to the network tracer as route events,
+// so the checker keeps the tables of all the processes and compares them with the shortest paths computed
+// centrally from the links of the network. Tables are identified by processes only, so a run should use
+// a single routing protocol.
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/pkg/graph"
+)
+
+// Route is an entry of a routing table: the next hop to the receiver and the cost of the path.
+type Route struct {
+	Next int32
+	Cost int64
+}
+
+// Mismatch is a route which differs from the shortest paths or a missing one.
+type Mismatch struct {
+	Protocol string
+	Node     int32
+	To       int32
+	Route    *Route
+	Want     int64
+	Reason   string
+}
+
+func (m Mismatch) String() string {
+	if m.Route == nil {
+		return fmt.Sprintf("%v: process %v has no route to %v: %v", m.Protocol, m.Node, m.To, m.Reason)
+	}
+	return fmt.Sprintf("%v: route of process %v to %v via %v with cost %v: %v",
+		m.Protocol, m.Node, m.To, m.Route.Next, m.Route.Cost, m.Reason)
+}
+
+// Checker is a tracer keeping the routing tables of the processes by the route events of a run.
+type Checker struct {
+	mutex    sync.Mutex
+	protocol string
+	tables   map[int32]map[int32]Route
+	changes  int
+	last     int64
+}
+
+// NewChecker creates a checker without routes.
+func NewChecker() *Checker {
+	return &Checker{tables: make(map[int32]map[int32]Route)}
+}
+
+// Record implements trace.Tracer.
+func (c *Checker) Record(e trace.Event) {
+	if e.Kind != trace.Route {
+		return
+	}
+	var name string
+	var to, next int32
+	var cost int64
+	if _, err := fmt.Sscanf(e.Message, "%s %d %d %d", &name, &to, &next, &cost); err != nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.protocol = name
+	c.changes++
+	c.last = e.Tick
+	if c.tables[e.From] == nil {
+		c.tables[e.From] = make(map[int32]Route)
+	}
+	if cost < 0 {
+		delete(c.tables[e.From], to)
+		return
+	}
+	c.tables[e.From][to] = Route{next, cost}
+}
+
+// Changes returns the number of changes of the routing tables.
+func (c *Checker) Changes() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.changes
+}
+
+// LastChange returns the tick the routing tables have changed at for the last time.
+func (c *Checker) LastChange() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.last
+}
+
+// Table returns a copy of the routing table of the process.
+func (c *Checker) Table(node int32) map[int32]Route {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := make(map[int32]Route, len(c.tables[node]))
+	for to, r := range c.tables[node] {
+		res[to] = r
+	}
+	return res
+}
+
+// Mismatches compares the routing tables of the processes of the nodes with the shortest paths between them
+// over the links: every route must have the cost of the shortest path and go through a neighbour on one
+// of the shortest paths, every reachable process must have a route.
+func (c *Checker) Mismatches(links graph.Graph, nodes []int32) []Mismatch {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := make([]Mismatch, 0)
+	if c.changes == 0 {
+		return res
+	}
+	dists := make(map[int32]map[int32]int64)
+	dist := func(from int32) map[int32]int64 {
+		if _, ok := dists[from]; !ok {
+			dists[from], _ = links.ShortestPaths(from)
+		}
+		return dists[from]
+	}
+	// onPath reports whether the link to the next hop starts a shortest path from the process to the receiver.
+	onPath := func(node int32, next int32, to int32, want int64) bool {
+		d, ok := dist(next)[to]
+		return ok && int64(links[node][next])+d == want
+	}
+	sorted := append([]int32(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, node := range sorted {
+		for _, to := range sorted {
+			if to == node {
+				continue
+			}
+			want, reachable := dist(node)[to]
+			r, ok := c.tables[node][to]
+			m := Mismatch{Protocol: c.protocol, Node: node, To: to, Want: want}
+			if ok {
+				m.Route = &r
+			}
+			switch {
+			case !ok && reachable:
+				m.Reason = fmt.Sprintf("the shortest path costs %v", want)
+			case ok && !reachable:
+				m.Reason = "the process is unreachable"
+			case ok && r.Cost != want:
+				m.Reason = fmt.Sprintf("the shortest path costs %v", want)
+			case ok && !links.HasEdge(node, r.Next):
+				m.Reason = "the next hop is not a neighbour"
+			case ok && !onPath(node, r.Next, to, want):
+				m.Reason = "the next hop is not on a shortest path"
+			default:
+				continue
+			}
+			res = append(res, m)
+		}
+	}
+	return res
+}
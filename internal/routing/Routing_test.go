@@ -0,0 +1,69 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/trace"
+	"github.com/trmigor/distr-model/pkg/graph"
+)
+
+func TestChecker_Mismatches(t *testing.T) {
+	links := graph.New()
+	links.AddEdge(0, 1, 1)
+	links.AddEdge(1, 0, 1)
+	links.AddEdge(1, 2, 1)
+	links.AddEdge(2, 1, 1)
+	links.AddEdge(0, 2, 3)
+	links.AddEdge(2, 0, 3)
+	nodes := []int32{0, 1, 2, 3}
+	routes := []struct {
+		tick    int64
+		node    int32
+		message string
+	}{
+		{1, 0, "DV 1 1 1"},
+		{1, 0, "DV 2 2 3"},
+		{2, 0, "DV 2 1 2"},
+		{1, 1, "DV 0 0 1"},
+		{1, 1, "DV 2 2 1"},
+		{1, 1, "DV 3 2 2"},
+		{3, 2, "DV 1 1 1"},
+		{3, 2, "DV 0 0 2"},
+		{4, 2, "DV 3 3 1"},
+		{5, 2, "DV 3 -1 -1"},
+		{5, 2, "unparsable"},
+	}
+	c := NewChecker()
+	if got := c.Mismatches(links, nodes); len(got) != 0 {
+		t.Errorf("Checker.Mismatches() without routes = %v, want none", got)
+	}
+	for _, r := range routes {
+		c.Record(trace.Event{Tick: r.tick, Kind: trace.Route, From: r.node, To: r.node, Message: r.message})
+	}
+	c.Record(trace.Event{Tick: 9, Kind: trace.Send, From: 0, To: 1, Message: "DV 1 1 1"})
+	if got := c.Changes(); got != 10 {
+		t.Errorf("Checker.Changes() = %v, want 10", got)
+	}
+	if got := c.LastChange(); got != 5 {
+		t.Errorf("Checker.LastChange() = %v, want 5", got)
+	}
+	if got := c.Table(0); len(got) != 2 || got[2] != (Route{1, 2}) {
+		t.Errorf("Checker.Table(0) = %v", got)
+	}
+	want := []struct {
+		node, to int32
+		reason   string
+	}{
+		{1, 3, "the process is unreachable"},
+		{2, 0, "the next hop is not on a shortest path"},
+	}
+	got := c.Mismatches(links, nodes)
+	if len(got) != len(want) {
+		t.Fatalf("Checker.Mismatches() = %v, want %v", got, want)
+	}
+	for i, m := range got {
+		if m.Node != want[i].node || m.To != want[i].to || m.Reason != want[i].reason {
+			t.Errorf("Checker.Mismatches()[%v] = %v, want %v", i, m, want[i])
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package sample runs the sample configurations of the algorithms in their tests.
+package sample
+
+import (
+	"os"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+// Seed is the seed of the network random number generator the samples are run with.
+const Seed = 1
+
+// Main runs the tests of a package logging only the errors.
+func Main(m *testing.M) {
+	logging.SetLevel(logging.Error)
+	os.Exit(m.Run())
+}
+
+// Run launches the config in a new world and runs it until no messages are pending, the test fails
+// if maxTicks expire first unless the protocol is periodic and never stops sending messages by itself.
+// setup, if not nil, is called before the config is parsed, so that the checkers it installs see the whole run.
+// The world is stopped when the test finishes.
+func Run(t *testing.T, config string, maxTicks int64, periodic bool, setup func(w *world.World)) *world.World {
+	t.Helper()
+	w := world.NewWithOptions(world.Options{Seed: Seed, MaxTicks: maxTicks})
+	t.Cleanup(w.Stop)
+	if setup != nil {
+		setup(w)
+	}
+	if !w.ParseConfig([]byte(config)) {
+		t.Fatalf("World.ParseConfig(%v) = false", config)
+	}
+	if !w.Run() && !periodic {
+		t.Fatalf("World.Run() = false")
+	}
+	return w
+}
@@ -0,0 +1,28 @@
+package sample
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/world"
+)
+
+func TestRun(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.data")
+	data := "processes 0 1\nlink from 0 to 1 latency 3\nsend from 0 to 1 PING\n"
+	if err := ioutil.WriteFile(config, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setup := false
+	w := Run(t, config, 100, false, func(w *world.World) { setup = w.Network.Tick == 0 })
+	if !setup {
+		t.Errorf("Run() has not called setup before the run")
+	}
+	if got := w.Pending(); got != 0 {
+		t.Errorf("World.Pending() = %v, want 0", got)
+	}
+	if got := w.Network.Tick; got != 3 {
+		t.Errorf("World.Network.Tick = %v, want 3", got)
+	}
+}
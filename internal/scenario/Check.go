@@ -0,0 +1,243 @@
+package scenario
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/trmigor/distr-model/pkg/graph"
+)
+
+// Report is a result of a scenario dry run.
+type Report struct {
+	Processes   []int32
+	Links       int
+	Problems    []string
+	Unreachable []int32
+	Diameter    int
+	Connected   bool
+}
+
+// OK reports whether no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) problem(format string, a ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, a...))
+}
+
+// String formats the report for humans.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "processes: %v, links: %v\n", len(r.Processes), r.Links)
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "problem: %v\n", p)
+	}
+	if len(r.Unreachable) > 0 {
+		fmt.Fprintf(&b, "unreachable nodes: %v\n", r.Unreachable)
+	}
+	if r.Connected {
+		fmt.Fprintf(&b, "diameter: %v\n", r.Diameter)
+	} else {
+		fmt.Fprintf(&b, "diameter: infinite (%v between connected nodes)\n", r.Diameter)
+	}
+	return b.String()
+}
+
+// Topology builds the links graph at the start of the run the same way the network does.
+// Links from or to all the processes also involve absent nodes lower than the greatest one.
+func (s *Scenario) Topology() graph.Graph {
+	g := graph.New()
+	for i := range s.Links {
+		s.link(g, &s.Links[i], true)
+	}
+	return g
+}
+
+// link adds the edges of the scenario link to the graph or removes them from it.
+func (s *Scenario) link(g graph.Graph, l *Link, add bool) {
+	var size int32
+	for _, r := range s.Processes {
+		if r.To+1 > size {
+			size = r.To + 1
+		}
+	}
+
+	edge := func(from int32, to int32) {
+		switch {
+		case from == to:
+		case add:
+			g.AddEdge(from, to, l.Cost())
+		default:
+			delete(g[from], to)
+		}
+	}
+	pair := func(from int32, to int32) {
+		edge(from, to)
+		if l.IsBidirected() {
+			edge(to, from)
+		}
+	}
+	for from := int32(0); from < size; from++ {
+		if !l.From.All && l.From.Node != from {
+			continue
+		}
+		for to := int32(0); to < size; to++ {
+			if l.To.All || l.To.Node == to {
+				pair(from, to)
+			}
+		}
+	}
+	if !l.From.All && !l.To.All && (l.From.Node >= size || l.To.Node >= size) {
+		pair(l.From.Node, l.To.Node)
+	}
+}
+
+// Check parses the scenario without running it and reports problems,
+// which would arise during the run.
+// Functions and contexts are the names of registered work functions and contexts.
+// Unreachable nodes are the processes which can not be reached from the initiators:
+// receivers of messages sent by the model and processes sending messages themselves
+// (or the first process, if no messages are sent).
+func Check(s *Scenario, functions []string, contexts []string) *Report {
+	r := &Report{}
+
+	exists := make(map[int32]bool)
+	for _, p := range s.Processes {
+		for i := p.From; i <= p.To; i++ {
+			if exists[i] {
+				r.problem("process %v is created twice", i)
+				continue
+			}
+			exists[i] = true
+			r.Processes = append(r.Processes, i)
+		}
+	}
+	sort.Slice(r.Processes, func(i, j int) bool { return r.Processes[i] < r.Processes[j] })
+
+	g := s.Topology()
+	for from := range g {
+		r.Links += len(g[from])
+	}
+
+	for i, l := range s.Links {
+		if !l.From.All && !exists[l.From.Node] {
+			r.problem("link %v: from nonexistent process %v", i, l.From.Node)
+		}
+		if !l.To.All && !exists[l.To.Node] {
+			r.problem("link %v: to nonexistent process %v", i, l.To.Node)
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, f := range functions {
+		known[f] = true
+	}
+	for _, a := range s.Assignments {
+		if !known[a.Function] {
+			r.problem("setprocesses %v %v %v: undefined work function %v", a.From, a.To, a.Function, a.Function)
+		}
+		for i := a.From; i <= a.To; i++ {
+			if !exists[i] {
+				r.problem("setprocesses %v %v %v: nonexistent process %v", a.From, a.To, a.Function, i)
+			}
+		}
+	}
+
+	knownContexts := make(map[string]bool)
+	for _, c := range contexts {
+		knownContexts[c] = true
+	}
+	for _, c := range s.Contexts {
+		if !knownContexts[c.Name] {
+			r.problem("context %v %v %v: undefined context %v", c.From, c.To, c.Name, c.Name)
+		}
+		for i := c.From; i <= c.To; i++ {
+			if !exists[i] {
+				r.problem("context %v %v %v: nonexistent process %v", c.From, c.To, c.Name, i)
+			}
+		}
+	}
+
+	if s.Faults != nil {
+		for _, b := range s.Faults.Byzantine {
+			for i := b.From; i <= b.To; i++ {
+				if !exists[i] {
+					r.problem("byzantine %v %v strategy %v: nonexistent process %v", b.From, b.To, b.Strategy, i)
+				}
+			}
+		}
+	}
+
+	initiators := make([]int32, 0)
+	// Routed messages only need a path to the receiver, not a link.
+	routed := s.Routing != "" && s.Routing != "none"
+	send := func(m Send, links graph.Graph) {
+		name := fmt.Sprintf("send from %v to %v %v", m.From, m.To, m.Type)
+		if m.From >= 0 {
+			if !exists[m.From] {
+				r.problem("%v: nonexistent sender", name)
+				return
+			}
+			initiators = append(initiators, m.From)
+			if len(links[m.From]) == 0 && m.From != m.To {
+				r.problem("%v: sender is disconnected", name)
+				return
+			}
+		}
+		switch {
+		case m.To < 0:
+			if m.From < 0 {
+				initiators = append(initiators, r.Processes...)
+			}
+		case !exists[m.To]:
+			r.problem("%v: nonexistent receiver", name)
+		case m.From >= 0 && routed && m.From != m.To:
+			if _, ok := links.Hops(m.From)[m.To]; !ok {
+				r.problem("%v: no route between sender and receiver", name)
+			}
+		case m.From >= 0 && !links.HasEdge(m.From, m.To) && m.From != m.To:
+			r.problem("%v: no link between sender and receiver", name)
+		case m.From < 0:
+			initiators = append(initiators, m.To)
+		}
+	}
+	// Messages are checked against the links in effect when they are sent,
+	// processes are reachable over the links existing at any time of the run.
+	current, all := s.Topology(), s.Topology()
+	for _, m := range s.Messages {
+		send(m, current)
+	}
+	for _, st := range s.Schedule {
+		switch {
+		case st.Send != nil:
+			send(*st.Send, current)
+		case st.Link != nil:
+			s.link(current, st.Link, true)
+			s.link(all, st.Link, true)
+		case st.Unlink != nil:
+			s.link(current, st.Unlink, false)
+		case st.Snapshot != nil && !exists[*st.Snapshot]:
+			r.problem("snapshot %v: nonexistent process", *st.Snapshot)
+		}
+	}
+	if len(initiators) == 0 && len(r.Processes) > 0 {
+		initiators = append(initiators, r.Processes[0])
+	}
+
+	reached := make(map[int32]bool)
+	for _, v := range initiators {
+		for u := range all.Hops(v) {
+			reached[u] = true
+		}
+	}
+	for _, v := range r.Processes {
+		if !reached[v] {
+			r.Unreachable = append(r.Unreachable, v)
+		}
+	}
+
+	r.Diameter, r.Connected = g.Diameter(r.Processes)
+	return r
+}
@@ -0,0 +1,74 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		wantProblems    int
+		wantUnreachable []int32
+		wantDiameter    int
+		wantConnected   bool
+	}{
+		{"Valid", "processes 0 3\nlink from 0 to 1\nlink from 1 to 2\nlink from 2 to 3\nsetprocesses 0 3 SETX\nsend from -1 to 0 SETX_INIT 1\n", 0, nil, 3, true},
+		{"AllToAll", "processes 0 3\nlink from all to all\n", 0, nil, 1, true},
+		{"Duplicate", "processes 0 3\nprocesses 2 4\nlink from all to all\n", 2, nil, 1, true},
+		{"UndefinedFunction", "processes 0 1\nlink from 0 to 1\nsetprocesses 0 1 SETY\n", 1, nil, 1, true},
+		{"NonexistentAssignment", "processes 0 1\nlink from 0 to 1\nsetprocesses 0 2 SETX\n", 1, nil, 1, true},
+		{"NonexistentLink", "processes 0 1\nlink from 0 to 1\nlink from 1 to 5\n", 1, nil, 1, true},
+		{"DisconnectedSender", "processes 0 2\nlink from 0 to 1\nsend from 2 to 0 A\n", 1, []int32{0, 1}, 1, false},
+		{"SelfSend", "processes 0 2\nlink from 0 to 1\nsend from 2 to 2 A\n", 0, []int32{0, 1}, 1, false},
+		{"ScheduledLink", "processes 0 2\nlink from 0 to 1\nsend from -1 to 0 A\nwait 1\nlink from 2 to 0\nsend from 2 to 0 B\n", 0, nil, 1, false},
+		{"RemovedLink", "processes 0 1\nlink from 0 to 1\nunlink from 0 to 1\nwait 1\nsend from 0 to 1 A\n", 1, nil, 1, true},
+		{"NoLink", "processes 0 2\nlink from 0 to 1\nlink from 1 to 2\nsend from 0 to 2 A\n", 1, nil, 2, true},
+		{"Routed", "processes 0 2\nlink from 0 to 1\nlink from 1 to 2\nrouting static\nsend from 0 to 2 A\n", 0, nil, 2, true},
+		{"NoRoute", "processes 0 3\nlink from 0 to 1\nlink from 2 to 3\nrouting static\nsend from 0 to 3 A\n", 1, []int32{2, 3}, 1, false},
+		{"NonexistentReceiver", "processes 0 1\nlink from 0 to 1\nsend from -1 to 3 A\n", 1, nil, 1, true},
+		{"Context", "processes 0 1\nlink from 0 to 1\ncontext 0 1 SetX X=1\n", 0, nil, 1, true},
+		{"UndefinedContext", "processes 0 1\nlink from 0 to 1\ncontext 0 SetY X=1\n", 1, nil, 1, true},
+		{"NonexistentContext", "processes 0 1\nlink from 0 to 1\ncontext 1 2 SetX\n", 1, nil, 1, true},
+		{"NonexistentSnapshot", "processes 0 1\nlink from 0 to 1\nsnapshot 2\n", 1, nil, 1, true},
+		{"NonexistentByzantine", "processes 0 1\nlink from 0 to 1\nbyzantine 1 2 strategy silent\n", 1, nil, 1, true},
+		{"Unreachable", "processes 0 3\nbidirected 0\nlink from 0 to 1\nlink from 2 to 3\nsend from -1 to 0 A\n", 0, []int32{2, 3}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := FromConfig([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := Check(s, []string{"SETX"}, []string{"SetX"})
+			if len(r.Problems) != tt.wantProblems {
+				t.Errorf("Check() problems = %v, want %v", r.Problems, tt.wantProblems)
+			}
+			if !reflect.DeepEqual(r.Unreachable, tt.wantUnreachable) {
+				t.Errorf("Check() unreachable = %v, want %v", r.Unreachable, tt.wantUnreachable)
+			}
+			if r.Diameter != tt.wantDiameter || r.Connected != tt.wantConnected {
+				t.Errorf("Check() diameter = %v, %v, want %v, %v", r.Diameter, r.Connected, tt.wantDiameter, tt.wantConnected)
+			}
+			if r.OK() != (tt.wantProblems == 0) {
+				t.Errorf("Report.OK() = %v", r.OK())
+			}
+		})
+	}
+}
+
+func TestScenario_Topology(t *testing.T) {
+	s, err := FromConfig([]byte("processes 1 3\nbidirected 0\nlink from 1 to all latency 4\nlink from 3 to 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := s.Topology()
+	want := map[int32]map[int32]int32{
+		1: {0: 4, 2: 4, 3: 4},
+		3: {1: 1},
+	}
+	if !reflect.DeepEqual(map[int32]map[int32]int32(g), want) {
+		t.Errorf("Scenario.Topology() = %v, want %v", g, want)
+	}
+}
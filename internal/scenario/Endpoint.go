@@ -0,0 +1,88 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// allKeyword denotes all processes in link endpoints.
+const allKeyword = "all"
+
+// Endpoint is a link end: either a single process node or all the processes.
+// It is encoded as a node number or as the "all" string.
+type Endpoint struct {
+	Node int32
+	All  bool
+}
+
+// Node returns an endpoint of a single process.
+func Node(node int32) Endpoint {
+	return Endpoint{Node: node}
+}
+
+// All returns an endpoint of all the processes.
+func All() Endpoint {
+	return Endpoint{All: true}
+}
+
+// String returns the endpoint as it is written in config.data.
+func (e Endpoint) String() string {
+	if e.All {
+		return allKeyword
+	}
+	return fmt.Sprint(e.Node)
+}
+
+func (e *Endpoint) set(v interface{}) error {
+	switch n := v.(type) {
+	case string:
+		if n != allKeyword {
+			return fmt.Errorf("invalid endpoint %q", n)
+		}
+		*e = All()
+	case int:
+		*e = Node(int32(n))
+	case float64:
+		if n != float64(int32(n)) {
+			return fmt.Errorf("invalid endpoint %v", n)
+		}
+		*e = Node(int32(n))
+	default:
+		return fmt.Errorf("invalid endpoint %v", v)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e Endpoint) MarshalJSON() ([]byte, error) {
+	if e.All {
+		return json.Marshal(allKeyword)
+	}
+	return json.Marshal(e.Node)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *Endpoint) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return e.set(v)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (e Endpoint) MarshalYAML() (interface{}, error) {
+	if e.All {
+		return allKeyword, nil
+	}
+	return e.Node, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *Endpoint) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return e.set(v)
+}
@@ -0,0 +1,290 @@
+package scenario
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Open reads a scenario from a file of any supported format:
+// JSON or YAML by the extension, or config.data otherwise.
+func Open(name string) (*Scenario, error) {
+	if _, ok := FormatOf(name); ok {
+		return Load(name)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return FromConfig(data)
+}
+
+// FromConfig converts the legacy line-based config.data syntax into a scenario.
+// Sends and links preceding the first wait, timer or snapshot become initial messages and links,
+// the rest of them, waits, timers, snapshots and unlinks form the schedule in their original order.
+// Error rates set before any send or wait become the fault setting, later ones are steps of the schedule.
+func FromConfig(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	bidirected := 1
+	var bandwidth int64
+	var buffer int
+	limited := false
+	scheduled := false
+
+	dataLines := strings.Split(string(data), "\n")
+	for i := 0; i < len(dataLines); i++ {
+		line := strings.TrimRight(dataLines[i], "\r")
+		if len(line) == 0 || line[0] == ';' {
+			continue
+		}
+
+		var startprocess, endprocess, from, to, arg int32
+		var latency int32 = DefaultLatency
+		var timer, timeout int
+		var errorRate float64
+		var id, msg string
+
+		link := func(from Endpoint, to Endpoint) {
+			b, l := bidirected != 0, latency
+			res := Link{From: from, To: to, Latency: &l, Bidirected: &b}
+			if limited {
+				bw, buf := bandwidth, buffer
+				res.Bandwidth, res.Buffer = &bw, &buf
+			}
+			if scheduled {
+				s.Schedule = append(s.Schedule, Step{Link: &res})
+				return
+			}
+			s.Links = append(s.Links, res)
+		}
+		byzantine := func(b Byzantine) {
+			if s.Faults == nil {
+				s.Faults = &Faults{}
+			}
+			s.Faults.Byzantine = append(s.Faults.Byzantine, b)
+		}
+		send := func(m Send) {
+			if scheduled {
+				s.Schedule = append(s.Schedule, Step{Send: &m})
+			} else {
+				s.Messages = append(s.Messages, m)
+			}
+		}
+
+		if read, err := fmt.Sscanf(line, "processes %d %d", &startprocess, &endprocess); err == nil && read == 2 {
+			s.Processes = append(s.Processes, Range{startprocess, endprocess})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "bidirected %d", &bidirected); err == nil && read == 1 {
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "bandwidth %d", &bandwidth); err == nil && read == 1 {
+			limited = true
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "buffer %d", &buffer); err == nil && read == 1 {
+			limited = true
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "errorRate %f", &errorRate); err == nil && read == 1 {
+			if scheduled || len(s.Messages) > 0 {
+				s.Schedule = append(s.Schedule, Step{ErrorRate: &errorRate})
+				scheduled = true
+				continue
+			}
+			if s.Faults == nil {
+				s.Faults = &Faults{}
+			}
+			s.Faults.ErrorRate = errorRate
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "byzantine %d %d strategy %s", &startprocess, &endprocess, &msg); err == nil && read == 3 {
+			byzantine(Byzantine{startprocess, endprocess, msg})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "byzantine %d strategy %s", &startprocess, &msg); err == nil && read == 2 {
+			byzantine(Byzantine{startprocess, startprocess, msg})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from all to all latency %d", &latency); (err == nil && read == 1) || line == "link from all to all" {
+			link(All(), All())
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "setprocesses %d %d %s", &startprocess, &endprocess, &id); err == nil && read == 3 {
+			s.Assignments = append(s.Assignments, Assignment{startprocess, endprocess, id})
+			continue
+		}
+
+		if strings.HasPrefix(line, "context ") {
+			c, err := ParseContext(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			s.Contexts = append(s.Contexts, *c)
+			continue
+		}
+
+		if strings.HasPrefix(line, "invariant ") || strings.HasPrefix(line, "eventually ") {
+			p, err := ParseProperty(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			s.Properties = append(s.Properties, *p)
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "send from %d to %d %s %d", &from, &to, &msg, &arg); read == 4 && err == nil {
+			a := arg
+			send(Send{From: from, To: to, Type: msg, Arg: &a})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "send from %d to %d %s", &from, &to, &msg); read == 3 && err == nil {
+			send(Send{From: from, To: to, Type: msg})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "wait %d", &timeout); read == 1 && err == nil {
+			scheduled = true
+			s.Schedule = append(s.Schedule, Step{Wait: &timeout})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "termination %s", &msg); read == 1 && err == nil {
+			s.Termination = msg
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "unlink from %d to %d", &from, &to); read == 2 && err == nil {
+			b := bidirected != 0
+			s.Schedule = append(s.Schedule, Step{Unlink: &Link{From: Node(from), To: Node(to), Bidirected: &b}})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "routing %s", &msg); read == 1 && err == nil {
+			s.Routing = msg
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "snapshot %d", &from); read == 1 && err == nil {
+			scheduled = true
+			node := from
+			s.Schedule = append(s.Schedule, Step{Snapshot: &node})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "launch timer %d", &timer); read == 1 && err == nil {
+			scheduled = true
+			s.Schedule = append(s.Schedule, Step{Timer: timer})
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from %d to %d latency %d", &from, &to, &latency); err == nil && read == 3 {
+			link(Node(from), Node(to))
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from %d to %d", &from, &to); err == nil && read == 2 {
+			link(Node(from), Node(to))
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from %d to all latency %d", &from, &latency); err == nil && read == 2 {
+			link(Node(from), All())
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from %d to all", &from); err == nil && read == 1 {
+			link(Node(from), All())
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from all to %d latency %d", &to, &latency); err == nil && read == 2 {
+			link(All(), Node(to))
+			continue
+		}
+
+		if read, err := fmt.Sscanf(line, "link from all to %d", &to); err == nil && read == 1 {
+			link(All(), Node(to))
+			continue
+		}
+
+		return nil, fmt.Errorf("line %d: unknown directive: %v", i+1, line)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseContext parses the "context" directive, which initialises a context of one process or a range of them:
+//
+//	context node name [field=value ...]
+//	context from to name [field=value ...]
+func ParseContext(line string) (*Context, error) {
+	words := strings.Fields(line)
+	if len(words) < 3 || words[0] != "context" {
+		return nil, fmt.Errorf("invalid context directive: %v", line)
+	}
+	from, err := strconv.ParseInt(words[1], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context directive: %v", line)
+	}
+	c := &Context{From: int32(from), To: int32(from), Name: words[2]}
+	rest := words[3:]
+	if to, err := strconv.ParseInt(words[2], 10, 32); err == nil {
+		if len(words) < 4 {
+			return nil, fmt.Errorf("invalid context directive: %v", line)
+		}
+		c.To, c.Name, rest = int32(to), words[3], words[4:]
+	}
+	for _, w := range rest {
+		eq := strings.IndexByte(w, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid context field %v", w)
+		}
+		if c.Fields == nil {
+			c.Fields = make(map[string]interface{})
+		}
+		c.Fields[w[:eq]] = w[eq+1:]
+	}
+	return c, nil
+}
+
+// ParseProperty parses the "invariant" and "eventually" directives, which check a global property:
+//
+//	invariant predicate
+//	eventually predicate [by tick]
+func ParseProperty(line string) (*Property, error) {
+	words := strings.Fields(line)
+	if len(words) < 2 || words[0] != "invariant" && words[0] != "eventually" {
+		return nil, fmt.Errorf("invalid property directive: %v", line)
+	}
+	p := &Property{}
+	if words[0] == "invariant" {
+		p.Invariant = strings.Join(words[1:], " ")
+	} else {
+		if n := len(words); n > 3 && words[n-2] == "by" {
+			by, err := strconv.ParseInt(words[n-1], 10, 64)
+			if err != nil || by <= 0 {
+				return nil, fmt.Errorf("invalid deadline: %v", line)
+			}
+			p.By, words = by, words[:n-2]
+		}
+		p.Eventually = strings.Join(words[1:], " ")
+	}
+	if _, err := p.Property(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,232 @@
+package scenario
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestFromConfig(t *testing.T) {
+	yes, no := true, false
+	one, two := int32(1), int32(2)
+	arg, node := int32(5), int32(1)
+	hundred, zero, four := int64(100), int64(0), 4
+	none, wait1, wait2, lossy := 0, 1, 2, 1.0
+	tests := []struct {
+		name    string
+		data    string
+		want    *Scenario
+		wantErr bool
+	}{
+		{
+			"Links",
+			"processes 0 2\nbidirected 0\nlink from 0 to 1 latency 2\nbidirected 1\nlink from all to all\n",
+			&Scenario{
+				Processes: []Range{{0, 2}},
+				Links: []Link{
+					{From: Node(0), To: Node(1), Latency: &two, Bidirected: &no},
+					{From: All(), To: All(), Latency: &one, Bidirected: &yes},
+				},
+			},
+			false,
+		},
+		{
+			"Schedule",
+			"; comment\nprocesses 0 1\nerrorRate 0.5\nsetprocesses 0 1 SETX\nsend from -1 to 0 SETX_INIT 5\nlaunch timer 3\nwait 2\nsend from -1 to 1 SETX_INIT\n",
+			&Scenario{
+				Processes:   []Range{{0, 1}},
+				Faults:      &Faults{ErrorRate: 0.5},
+				Assignments: []Assignment{{0, 1, "SETX"}},
+				Messages:    []Send{{From: -1, To: 0, Type: "SETX_INIT", Arg: &arg}},
+				Schedule:    []Step{{Timer: 3}, {Wait: &wait2}, {Send: &Send{From: -1, To: 1, Type: "SETX_INIT"}}},
+			},
+			false,
+		},
+		{
+			"Contexts",
+			"processes 0 3\ncontext 3 SetX X=7\ncontext 0 2 Common\n",
+			&Scenario{
+				Processes: []Range{{0, 3}},
+				Contexts: []Context{
+					{From: 3, To: 3, Name: "SetX", Fields: map[string]interface{}{"X": "7"}},
+					{From: 0, To: 2, Name: "Common"},
+				},
+			},
+			false,
+		},
+		{
+			"Snapshot",
+			"processes 0 1\nsnapshot 1\nwait 2\n",
+			&Scenario{
+				Processes: []Range{{0, 1}},
+				Schedule:  []Step{{Snapshot: &node}, {Wait: &wait2}},
+			},
+			false,
+		},
+		{
+			"Capacity",
+			"processes 0 2\nbandwidth 100\nbuffer 4\nlink from 0 to all latency 2\nbandwidth 0\nlink from 1 to 2\n",
+			&Scenario{
+				Processes: []Range{{0, 2}},
+				Links: []Link{
+					{From: Node(0), To: All(), Latency: &two, Bidirected: &yes, Bandwidth: &hundred, Buffer: &four},
+					{From: Node(1), To: Node(2), Latency: &one, Bidirected: &yes, Bandwidth: &zero, Buffer: &four},
+				},
+			},
+			false,
+		},
+		{
+			"Unlink",
+			"processes 0 2\nwait 1\nlink from 0 to 1 latency 2\nbidirected 0\nunlink from 1 to 2\n",
+			&Scenario{
+				Processes: []Range{{0, 2}},
+				Schedule: []Step{
+					{Wait: &wait1},
+					{Link: &Link{From: Node(0), To: Node(1), Latency: &two, Bidirected: &yes}},
+					{Unlink: &Link{From: Node(1), To: Node(2), Bidirected: &no}},
+				},
+			},
+			false,
+		},
+		{
+			"ZeroWait",
+			"processes 0 1\nsend from -1 to 0 SETX_INIT\nwait 0\nsend from -1 to 1 SETX_INIT\n",
+			&Scenario{
+				Processes: []Range{{0, 1}},
+				Messages:  []Send{{From: -1, To: 0, Type: "SETX_INIT"}},
+				Schedule:  []Step{{Wait: &none}, {Send: &Send{From: -1, To: 1, Type: "SETX_INIT"}}},
+			},
+			false,
+		},
+		{
+			"LateErrorRate",
+			"processes 0 1\nerrorRate 0\nwait 2\nerrorRate 1\nsend from -1 to 0 SETX_INIT\n",
+			&Scenario{
+				Processes: []Range{{0, 1}},
+				Faults:    &Faults{},
+				Schedule:  []Step{{Wait: &wait2}, {ErrorRate: &lossy}, {Send: &Send{From: -1, To: 0, Type: "SETX_INIT"}}},
+			},
+			false,
+		},
+		{
+			"Termination",
+			"processes 0 1\ntermination DS\n",
+			&Scenario{Processes: []Range{{0, 1}}, Termination: "DS"},
+			false,
+		},
+		{
+			"Routing",
+			"processes 0 1\nrouting static\n",
+			&Scenario{Processes: []Range{{0, 1}}, Routing: "static"},
+			false,
+		},
+		{
+			"Byzantine",
+			"processes 0 3\nbyzantine 1 2 strategy random\nerrorRate 0.5\nbyzantine 3 strategy silent\n",
+			&Scenario{
+				Processes: []Range{{0, 3}},
+				Faults:    &Faults{ErrorRate: 0.5, Byzantine: []Byzantine{{1, 2, "random"}, {3, 3, "silent"}}},
+			},
+			false,
+		},
+		{
+			"Properties",
+			"processes 0 1\ninvariant atmost 1 LCR.IsLeader\neventually equal LCR.Leader by 30\n",
+			&Scenario{
+				Processes:  []Range{{0, 1}},
+				Properties: []Property{{Invariant: "atmost 1 LCR.IsLeader"}, {Eventually: "equal LCR.Leader", By: 30}},
+			},
+			false,
+		},
+		{"InvalidProperty", "processes 0 1\ninvariant LCR.IsLeader\n", nil, true},
+		{"UnknownStrategy", "processes 0 1\nbyzantine 1 strategy lying\n", nil, true},
+		{"InvalidContext", "processes 0 1\ncontext 0 SetX X\n", nil, true},
+		{"Unknown", "processes 0 1\nLorem ipsum\n", nil, true},
+		{"NoProcesses", "bidirected 1\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromConfig([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromConfig_Sample(t *testing.T) {
+	data, err := ioutil.ReadFile("../../configs/config.data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FromConfig(data)
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	want, err := Load("../../configs/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseProperty(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    *Property
+		wantErr bool
+	}{
+		{"Invariant", "invariant  atmost 1 LCR.IsLeader", &Property{Invariant: "atmost 1 LCR.IsLeader"}, false},
+		{"Eventually", "eventually all A.Done", &Property{Eventually: "all A.Done"}, false},
+		{"Deadline", "eventually all A.N > 2 by 15", &Property{Eventually: "all A.N > 2", By: 15}, false},
+		{"BadDeadline", "eventually all A.Done by 0", nil, true},
+		{"InvariantDeadline", "invariant all A.Done by 3", nil, true},
+		{"NoPredicate", "eventually", nil, true},
+		{"BadPredicate", "invariant A.Done", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProperty(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProperty() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProperty() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    *Context
+		wantErr bool
+	}{
+		{"Node", "context 3 SetX X=7", &Context{From: 3, To: 3, Name: "SetX", Fields: map[string]interface{}{"X": "7"}}, false},
+		{"Range", "context 0 2 SetX X=1 Y=a=b", &Context{From: 0, To: 2, Name: "SetX", Fields: map[string]interface{}{"X": "1", "Y": "a=b"}}, false},
+		{"NoFields", "context 1 Common", &Context{From: 1, To: 1, Name: "Common"}, false},
+		{"NoName", "context 1", nil, true},
+		{"RangeNoName", "context 1 2", nil, true},
+		{"BadNode", "context a SetX", nil, true},
+		{"BadField", "context 1 SetX =1", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseContext(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseContext() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
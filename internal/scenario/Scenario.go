@@ -0,0 +1,386 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/trmigor/distr-model/internal/byzantine"
+	"github.com/trmigor/distr-model/internal/network"
+	"github.com/trmigor/distr-model/internal/property"
+	"github.com/trmigor/distr-model/internal/termination"
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario is a structured description of a model run.
+// It is an alternative to the line-based config.data directives with the same semantics.
+type Scenario struct {
+	Processes   []Range      `json:"processes" yaml:"processes"`
+	Faults      *Faults      `json:"faults,omitempty" yaml:"faults,omitempty"`
+	Links       []Link       `json:"links,omitempty" yaml:"links,omitempty"`
+	Assignments []Assignment `json:"assignments,omitempty" yaml:"assignments,omitempty"`
+	Contexts    []Context    `json:"contexts,omitempty" yaml:"contexts,omitempty"`
+	Properties  []Property   `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Messages    []Send       `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Schedule    []Step       `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	Termination string       `json:"termination,omitempty" yaml:"termination,omitempty"`
+	Routing     string       `json:"routing,omitempty" yaml:"routing,omitempty"`
+}
+
+// Range is an inclusive range of process nodes.
+type Range struct {
+	From int32 `json:"from" yaml:"from"`
+	To   int32 `json:"to" yaml:"to"`
+}
+
+// Faults describes fault settings of the network.
+type Faults struct {
+	ErrorRate float64     `json:"errorRate,omitempty" yaml:"errorRate,omitempty"`
+	Byzantine []Byzantine `json:"byzantine,omitempty" yaml:"byzantine,omitempty"`
+}
+
+// Byzantine makes a range of processes Byzantine with the adversary strategy, as the "byzantine" directive does.
+type Byzantine struct {
+	From     int32  `json:"from" yaml:"from"`
+	To       int32  `json:"to" yaml:"to"`
+	Strategy string `json:"strategy" yaml:"strategy"`
+}
+
+// Link describes a connection between processes, as the "link" directive does.
+// Bandwidth and Buffer limit the link, as the "bandwidth" and "buffer" directives do, zeros mean no limits.
+type Link struct {
+	From       Endpoint `json:"from" yaml:"from"`
+	To         Endpoint `json:"to" yaml:"to"`
+	Latency    *int32   `json:"latency,omitempty" yaml:"latency,omitempty"`
+	Bidirected *bool    `json:"bidirected,omitempty" yaml:"bidirected,omitempty"`
+	Bandwidth  *int64   `json:"bandwidth,omitempty" yaml:"bandwidth,omitempty"`
+	Buffer     *int     `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+}
+
+// Assignment assigns a work function to a range of processes, as the "setprocesses" directive does.
+type Assignment struct {
+	From     int32  `json:"from" yaml:"from"`
+	To       int32  `json:"to" yaml:"to"`
+	Function string `json:"function" yaml:"function"`
+}
+
+// Context initialises a context of a range of processes, as the "context" directive does.
+// Fields are set by their names, their values must be scalars.
+type Context struct {
+	From   int32                  `json:"from" yaml:"from"`
+	To     int32                  `json:"to" yaml:"to"`
+	Name   string                 `json:"name" yaml:"name"`
+	Fields map[string]interface{} `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// Property is a global property checked during the run, as the "invariant" and "eventually" directives do.
+// Exactly one of Invariant and Eventually must be set to a predicate (see property.Parse),
+// By is the deadline of the eventual one, 0 means the end of the run.
+type Property struct {
+	Invariant  string `json:"invariant,omitempty" yaml:"invariant,omitempty"`
+	Eventually string `json:"eventually,omitempty" yaml:"eventually,omitempty"`
+	By         int64  `json:"by,omitempty" yaml:"by,omitempty"`
+}
+
+// Send describes a message sent by the model, as the "send" directive does.
+type Send struct {
+	From int32  `json:"from" yaml:"from"`
+	To   int32  `json:"to" yaml:"to"`
+	Type string `json:"type" yaml:"type"`
+	Arg  *int32 `json:"arg,omitempty" yaml:"arg,omitempty"`
+}
+
+// Step is a single schedule entry. Exactly one of its fields must be set.
+// Wait is the number of ticks to wait, 0 handles the messages due at the current tick in virtual time.
+// Link creates or changes a link during the run, Unlink removes one, as the "unlink" directive does.
+// Snapshot is the node starting a global snapshot, as the "snapshot" directive does.
+// ErrorRate changes the error rate of the network, as the "errorRate" directive after sends or waits does.
+type Step struct {
+	Send      *Send    `json:"send,omitempty" yaml:"send,omitempty"`
+	Wait      *int     `json:"wait,omitempty" yaml:"wait,omitempty"`
+	Timer     int      `json:"timer,omitempty" yaml:"timer,omitempty"`
+	Link      *Link    `json:"link,omitempty" yaml:"link,omitempty"`
+	Unlink    *Link    `json:"unlink,omitempty" yaml:"unlink,omitempty"`
+	Snapshot  *int32   `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	ErrorRate *float64 `json:"errorRate,omitempty" yaml:"errorRate,omitempty"`
+}
+
+// DefaultLatency is a latency of links which do not specify it.
+const DefaultLatency = 1
+
+// IsBidirected reports whether the link is bidirected. Links are bidirected by default.
+func (l *Link) IsBidirected() bool {
+	return l.Bidirected == nil || *l.Bidirected
+}
+
+// Cost returns the link latency, taking the default into account.
+func (l *Link) Cost() int32 {
+	if l.Latency == nil {
+		return DefaultLatency
+	}
+	return *l.Latency
+}
+
+// Capacity returns the bandwidth and the buffer size of the link,
+// the last result is false if neither of them is set.
+func (l *Link) Capacity() (int64, int, bool) {
+	var bandwidth int64
+	var buffer int
+	if l.Bandwidth != nil {
+		bandwidth = *l.Bandwidth
+	}
+	if l.Buffer != nil {
+		buffer = *l.Buffer
+	}
+	return bandwidth, buffer, l.Bandwidth != nil || l.Buffer != nil
+}
+
+func (l *Link) validate() error {
+	if l.Cost() < 0 {
+		return fmt.Errorf("negative latency %d", l.Cost())
+	}
+	if bandwidth, buffer, _ := l.Capacity(); bandwidth < 0 || buffer < 0 {
+		return fmt.Errorf("negative capacity %d, %d", bandwidth, buffer)
+	}
+	return nil
+}
+
+// Property parses the predicate of the property, which is also its name.
+func (p *Property) Property() (property.Property, error) {
+	if (p.Invariant == "") == (p.Eventually == "") {
+		return property.Property{}, fmt.Errorf("exactly one of invariant and eventually must be set")
+	}
+	if p.By < 0 || p.By > 0 && p.Invariant != "" {
+		return property.Property{}, fmt.Errorf("invalid deadline %d", p.By)
+	}
+	res := property.Property{Name: p.Invariant, Kind: property.Invariant}
+	if p.Eventually != "" {
+		res = property.Property{Name: p.Eventually, Kind: property.Eventually, Deadline: p.By}
+	}
+	predicate, err := property.Parse(res.Name)
+	if err != nil {
+		return property.Property{}, err
+	}
+	res.Predicate = predicate
+	return res, nil
+}
+
+// Values returns the string representation of the context fields.
+func (c *Context) Values() map[string]string {
+	res := make(map[string]string, len(c.Fields))
+	for name, value := range c.Fields {
+		res[name] = fmt.Sprint(value)
+	}
+	return res
+}
+
+// Validate checks the scenario against the constraints of the scenario schema.
+func (s *Scenario) Validate() error {
+	if len(s.Processes) == 0 {
+		return fmt.Errorf("no processes defined")
+	}
+	for i, r := range s.Processes {
+		if r.From < 0 || r.To < r.From {
+			return fmt.Errorf("processes[%d]: invalid range %d..%d", i, r.From, r.To)
+		}
+	}
+	if s.Termination != "" && !known(termination.Algorithms(), s.Termination) {
+		return fmt.Errorf("termination: unknown algorithm %v", s.Termination)
+	}
+	if s.Routing != "" && s.Routing != "none" && !known(network.Routers(), s.Routing) {
+		return fmt.Errorf("routing: unknown router %v", s.Routing)
+	}
+	if s.Faults != nil && (s.Faults.ErrorRate < 0 || s.Faults.ErrorRate > 1) {
+		return fmt.Errorf("faults: errorRate %v is out of [0, 1]", s.Faults.ErrorRate)
+	}
+	if s.Faults != nil {
+		for i, b := range s.Faults.Byzantine {
+			if b.From < 0 || b.To < b.From {
+				return fmt.Errorf("faults: byzantine[%d]: invalid range %d..%d", i, b.From, b.To)
+			}
+			if !known(byzantine.Strategies(), b.Strategy) {
+				return fmt.Errorf("faults: byzantine[%d]: unknown strategy %v", i, b.Strategy)
+			}
+		}
+	}
+	for i, l := range s.Links {
+		if (!l.From.All && l.From.Node < 0) || (!l.To.All && l.To.Node < 0) {
+			return fmt.Errorf("links[%d]: negative node", i)
+		}
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("links[%d]: %v", i, err)
+		}
+	}
+	for i, a := range s.Assignments {
+		if a.From < 0 || a.To < a.From {
+			return fmt.Errorf("assignments[%d]: invalid range %d..%d", i, a.From, a.To)
+		}
+		if a.Function == "" {
+			return fmt.Errorf("assignments[%d]: empty function name", i)
+		}
+	}
+	for i, c := range s.Contexts {
+		if c.From < 0 || c.To < c.From {
+			return fmt.Errorf("contexts[%d]: invalid range %d..%d", i, c.From, c.To)
+		}
+		if c.Name == "" {
+			return fmt.Errorf("contexts[%d]: empty context name", i)
+		}
+		for name, value := range c.Fields {
+			switch value.(type) {
+			case string, bool, int, int64, uint64, float64:
+			default:
+				return fmt.Errorf("contexts[%d]: field %v is not a scalar", i, name)
+			}
+		}
+	}
+	for i := range s.Properties {
+		if _, err := s.Properties[i].Property(); err != nil {
+			return fmt.Errorf("properties[%d]: %v", i, err)
+		}
+	}
+	for i, m := range s.Messages {
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("messages[%d]: %v", i, err)
+		}
+	}
+	for i, st := range s.Schedule {
+		if err := st.validate(); err != nil {
+			return fmt.Errorf("schedule[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (m *Send) validate() error {
+	if m.Type == "" {
+		return fmt.Errorf("empty message type")
+	}
+	if strings.ContainsAny(m.Type, " \t\n") {
+		return fmt.Errorf("message type %q contains spaces", m.Type)
+	}
+	return nil
+}
+
+func (st *Step) validate() error {
+	set := 0
+	if st.Send != nil {
+		set++
+		if err := st.Send.validate(); err != nil {
+			return err
+		}
+	}
+	if st.Wait != nil {
+		set++
+		if *st.Wait < 0 {
+			return fmt.Errorf("negative duration")
+		}
+	}
+	if st.Timer != 0 {
+		set++
+	}
+	if st.Link != nil {
+		set++
+		if err := st.Link.validate(); err != nil {
+			return err
+		}
+	}
+	if st.Unlink != nil {
+		set++
+	}
+	if st.Snapshot != nil {
+		set++
+		if *st.Snapshot < 0 {
+			return fmt.Errorf("negative snapshot node")
+		}
+	}
+	if st.ErrorRate != nil {
+		set++
+		if *st.ErrorRate < 0 || *st.ErrorRate > 1 {
+			return fmt.Errorf("errorRate %v is out of [0, 1]", *st.ErrorRate)
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of send, wait, timer, link, unlink, snapshot and errorRate must be set")
+	}
+	if st.Timer < 0 {
+		return fmt.Errorf("negative duration")
+	}
+	return nil
+}
+
+// Format is a serialisation format of a scenario.
+type Format int
+
+const (
+	// JSON marks scenarios in JSON.
+	JSON Format = iota
+	// YAML marks scenarios in YAML.
+	YAML
+)
+
+// FormatOf guesses the format by the file name extension.
+// The second result is false if the extension is not known.
+func FormatOf(name string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return JSON, true
+	case ".yaml", ".yml":
+		return YAML, true
+	}
+	return JSON, false
+}
+
+// Parse decodes and validates a scenario.
+func Parse(data []byte, format Format) (*Scenario, error) {
+	s := &Scenario{}
+	var err error
+	if format == YAML {
+		err = yaml.UnmarshalStrict(data, s)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reads a scenario from a JSON or YAML file.
+func Load(name string) (*Scenario, error) {
+	format, ok := FormatOf(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown scenario format of '%v'", name)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, format)
+}
+
+// Marshal encodes a scenario.
+func (s *Scenario) Marshal(format Format) ([]byte, error) {
+	if format == YAML {
+		return yaml.Marshal(s)
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// known reports whether the name is in the list.
+func known(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package scenario
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestEndpoint_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Endpoint
+		wantErr bool
+	}{
+		{"Node", "3", Node(3), false},
+		{"All", `"all"`, All(), false},
+		{"Fraction", "1.5", Endpoint{}, true},
+		{"Word", `"some"`, Endpoint{}, true},
+		{"Bool", "true", Endpoint{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Endpoint
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("json.Unmarshal() = %v, want %v", got, tt.want)
+			}
+
+			got = Endpoint{}
+			err = yaml.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("yaml.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("yaml.Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  Format
+		wantErr bool
+	}{
+		{"YAML", "processes:\n- from: 0\n  to: 1\nlinks:\n- from: 0\n  to: all\n", YAML, false},
+		{"JSON", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"wait": 1}]}`, JSON, false},
+		{"UnknownFieldYAML", "processes:\n- from: 0\n  to: 1\nlink: []\n", YAML, true},
+		{"UnknownFieldJSON", `{"processes": [{"from": 0, "to": 1}], "link": []}`, JSON, true},
+		{"NoProcesses", `{}`, JSON, true},
+		{"BadRange", `{"processes": [{"from": 1, "to": 0}]}`, JSON, true},
+		{"ErrorRate", `{"processes": [{"from": 0, "to": 1}], "faults": {"errorRate": 2}}`, JSON, true},
+		{"NegativeLatency", `{"processes": [{"from": 0, "to": 1}], "links": [{"from": 0, "to": 1, "latency": -1}]}`, JSON, true},
+		{"EmptyFunction", `{"processes": [{"from": 0, "to": 1}], "assignments": [{"from": 0, "to": 1, "function": ""}]}`, JSON, true},
+		{"Context", "processes:\n- {from: 0, to: 1}\ncontexts:\n- {from: 0, to: 1, name: SetX, fields: {X: 7, On: true}}\n", YAML, false},
+		{"EmptyContext", `{"processes": [{"from": 0, "to": 1}], "contexts": [{"from": 0, "to": 1, "name": ""}]}`, JSON, true},
+		{"NonScalarField", `{"processes": [{"from": 0, "to": 1}], "contexts": [{"from": 0, "to": 1, "name": "A", "fields": {"X": [1]}}]}`, JSON, true},
+		{"SpacedType", `{"processes": [{"from": 0, "to": 1}], "messages": [{"from": 0, "to": 1, "type": "A B"}]}`, JSON, true},
+		{"EmptyStep", `{"processes": [{"from": 0, "to": 1}], "schedule": [{}]}`, JSON, true},
+		{"DoubleStep", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"wait": 1, "timer": 1}]}`, JSON, true},
+		{"ZeroWait", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"wait": 0}]}`, JSON, false},
+		{"NegativeWait", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"wait": -1}]}`, JSON, true},
+		{"StepErrorRate", "processes:\n- {from: 0, to: 1}\nschedule:\n- wait: 2\n- errorRate: 0.5\n", YAML, false},
+		{"StepErrorRateOutOfRange", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"errorRate": 1.5}]}`, JSON, true},
+		{"Snapshot", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"snapshot": 0}, {"wait": 1}]}`, JSON, false},
+		{"Capacity", `{"processes": [{"from": 0, "to": 1}], "links": [{"from": 0, "to": 1, "bandwidth": 10, "buffer": 2}]}`, JSON, false},
+		{"NegativeBandwidth", `{"processes": [{"from": 0, "to": 1}], "links": [{"from": 0, "to": 1, "bandwidth": -1}]}`, JSON, true},
+		{"NegativeBuffer", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"link": {"from": 0, "to": 1, "buffer": -1}}]}`, JSON, true},
+		{"Unlink", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"link": {"from": 0, "to": 1}}, {"unlink": {"from": 0, "to": "all"}}]}`, JSON, false},
+		{"NegativeStepLatency", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"link": {"from": 0, "to": 1, "latency": -1}}]}`, JSON, true},
+		{"NegativeSnapshot", `{"processes": [{"from": 0, "to": 1}], "schedule": [{"snapshot": -1}]}`, JSON, true},
+		{"Termination", "processes:\n- {from: 0, to: 1}\ntermination: SAFRA\n", YAML, false},
+		{"UnknownTermination", `{"processes": [{"from": 0, "to": 1}], "termination": "X"}`, JSON, true},
+		{"Routing", "processes:\n- {from: 0, to: 1}\nrouting: static\n", YAML, false},
+		{"UnknownRouting", `{"processes": [{"from": 0, "to": 1}], "routing": "X"}`, JSON, true},
+		{"Byzantine", "processes:\n- {from: 0, to: 3}\nfaults:\n  byzantine:\n  - {from: 2, to: 3, strategy: equivocate}\n", YAML, false},
+		{"UnknownStrategy", `{"processes": [{"from": 0, "to": 1}], "faults": {"byzantine": [{"from": 0, "to": 0, "strategy": "X"}]}}`, JSON, true},
+		{"Properties", "processes:\n- {from: 0, to: 1}\nproperties:\n- invariant: atmost 1 LCR.IsLeader\n- {eventually: equal LCR.Leader, by: 10}\n", YAML, false},
+		{"BadPredicate", `{"processes": [{"from": 0, "to": 1}], "properties": [{"invariant": "most LCR.Leader"}]}`, JSON, true},
+		{"DoubleProperty", `{"processes": [{"from": 0, "to": 1}], "properties": [{"invariant": "all A.B", "eventually": "all A.B"}]}`, JSON, true},
+		{"InvariantDeadline", `{"processes": [{"from": 0, "to": 1}], "properties": [{"invariant": "all A.B", "by": 5}]}`, JSON, true},
+		{"ByzantineRange", `{"processes": [{"from": 0, "to": 1}], "faults": {"byzantine": [{"from": 1, "to": 0, "strategy": "silent"}]}}`, JSON, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data), tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{"YAML", "../../test/data/scenario/Valid.yaml", false},
+		{"JSON", "../../test/data/scenario/Valid.json", false},
+		{"Config", "../../configs/config.yaml", false},
+		{"Invalid", "../../test/data/scenario/Invalid.yaml", true},
+		{"UnknownExtension", "../../test/data/config/Link.data", true},
+		{"InvalidFile", "../../test/data/scenario/InvalidFile.yaml", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.file); (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScenario_Marshal(t *testing.T) {
+	s, err := Load("../../test/data/scenario/Valid.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []Format{JSON, YAML} {
+		data, err := s.Marshal(format)
+		if err != nil {
+			t.Fatalf("Scenario.Marshal() error = %v", err)
+		}
+		got, err := Parse(data, format)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, s) {
+			t.Errorf("Parse(Scenario.Marshal()) = %+v, want %+v", got, s)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("Schema is not a valid JSON: %v", err)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	fields := reflect.TypeOf(Scenario{})
+	for i := 0; i < fields.NumField(); i++ {
+		name := strings.Split(fields.Field(i).Tag.Get("json"), ",")[0]
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Schema does not describe field %v", name)
+		}
+	}
+}
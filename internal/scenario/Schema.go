@@ -0,0 +1,11 @@
+package scenario
+
+import (
+	// embed is needed for the schema embedding.
+	_ "embed"
+)
+
+// Schema is a JSON Schema describing scenario files.
+//
+//go:embed scenario.schema.json
+var Schema []byte
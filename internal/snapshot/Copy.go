@@ -0,0 +1,82 @@
+package snapshot
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Copy returns a deep copy of the value, so that the recorded state is not changed by the process later.
+// Unexported fields of structures are copied deeply as well, values shared by pointers stay shared in the copy.
+func Copy(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(value), make(map[copied]reflect.Value)).Interface()
+}
+
+// copied identifies a value already copied by a pointer to it.
+type copied struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func deepCopy(v reflect.Value, seen map[copied]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := copied{v.Pointer(), v.Type()}
+		if res, ok := seen[key]; ok {
+			return res
+		}
+		res := reflect.New(v.Type().Elem())
+		seen[key] = res
+		res.Elem().Set(deepCopy(v.Elem(), seen))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(deepCopy(v.Elem(), seen))
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < res.NumField(); i++ {
+			f := res.Field(i)
+			if !f.CanSet() {
+				// Unexported fields are accessed through their addresses in the copy.
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+			}
+			f.Set(deepCopy(f, seen))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			res.SetMapIndex(deepCopy(it.Key(), seen), deepCopy(it.Value(), seen))
+		}
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return res
+	}
+	return v
+}
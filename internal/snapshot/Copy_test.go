@@ -0,0 +1,51 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+)
+
+type state struct {
+	X      int
+	Values []int
+	Seen   map[int32]bool
+	Next   *state
+	Any    interface{}
+	hidden []int
+	edges  map[int32]*edge
+}
+
+type edge struct {
+	weight int32
+	state  string
+}
+
+func TestCopy(t *testing.T) {
+	s := &state{X: 1, Values: []int{1, 2}, Seen: map[int32]bool{3: true}, Next: &state{X: 2}, Any: []int{5}, hidden: []int{7},
+		edges: map[int32]*edge{1: {weight: 3, state: "basic"}}}
+	got := Copy(s).(*state)
+	if !reflect.DeepEqual(got, s) {
+		t.Fatalf("Copy() = %+v, want %+v", got, s)
+	}
+	s.X = 5
+	s.Values[0] = 5
+	s.Seen[4] = true
+	s.Next.X = 5
+	s.Any.([]int)[0] = 6
+	s.hidden[0] = 8
+	s.edges[1].state = "branch"
+	s.edges[2] = &edge{weight: 4}
+	want := &state{X: 1, Values: []int{1, 2}, Seen: map[int32]bool{3: true}, Next: &state{X: 2}, Any: []int{5}, hidden: []int{7},
+		edges: map[int32]*edge{1: {weight: 3, state: "basic"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Copy() = %+v is changed with the original, want %+v", got, want)
+	}
+	cycle := &state{X: 1}
+	cycle.Next = cycle
+	if got := Copy(cycle).(*state); got == cycle || got.Next != got {
+		t.Errorf("Copy() of a cycle = %p with next %p, want a new cycle", got, got.Next)
+	}
+	if Copy(nil) != nil || Copy(3) != 3 {
+		t.Errorf("Copy() of scalars differs")
+	}
+}
@@ -0,0 +1,185 @@
+package snapshot
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/user/context"
+)
+
+// Message types of the snapshot algorithm.
+const (
+	// Initiate starts a new snapshot at the receiving process.
+	Initiate = "*SNAPSHOT"
+	// Marker separates messages sent before and after the snapshot in a channel.
+	Marker = "*SNAPSHOT_MARKER"
+)
+
+// Local is a state of one process recorded by a snapshot.
+// Channels are the messages in transit by the incoming channels, keyed by the senders.
+type Local struct {
+	Node     int32                      `json:"node"`
+	Tick     int64                      `json:"tick"`
+	Context  map[string]context.Context `json:"context"`
+	Channels map[int32][]string         `json:"channels"`
+}
+
+// Global is a consistent global state assembled from the local states of the processes.
+// It is complete when all the processes have recorded their states and the states of their incoming channels.
+type Global struct {
+	ID        int32            `json:"id"`
+	Initiator int32            `json:"initiator"`
+	Tick      int64            `json:"tick"`
+	Processes map[int32]*Local `json:"processes"`
+	Complete  bool             `json:"complete"`
+}
+
+// progress is a snapshot being recorded by a process.
+type progress struct {
+	local *Local
+	open  map[int32]bool
+}
+
+// Collector runs the Chandy–Lamport algorithm on the attached processes and assembles global states.
+// The algorithm requires reliable FIFO channels.
+type Collector struct {
+	mutex     sync.Mutex
+	snapshots []*Global
+	progress  map[int32]map[int32]*progress
+	attached  int
+}
+
+// NewCollector creates a collector without processes.
+func NewCollector() *Collector {
+	return &Collector{
+		snapshots: make([]*Global, 0),
+		progress:  make(map[int32]map[int32]*progress),
+	}
+}
+
+// InitiateMessage returns a message starting a snapshot.
+func InitiateMessage() *messages.Message {
+	return messages.NewMessageByArgs(messages.NewMessageArg([]byte(Initiate)))
+}
+
+func markerMessage(id int32) *messages.Message {
+	return messages.NewMessageByArgs(messages.NewMessageArg([]byte(Marker)), messages.NewMessageArg(id))
+}
+
+// Attach makes the process take part in snapshots.
+func (c *Collector) Attach(p *process.Process) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.attached++
+	c.progress[p.Node] = make(map[int32]*progress)
+	p.AddHook(c.hook)
+}
+
+// Snapshots returns the snapshots started so far in order of initiation.
+func (c *Collector) Snapshots() []*Global {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]*Global{}, c.snapshots...)
+}
+
+// hook handles the snapshot messages and records the messages in transit.
+func (c *Collector) hook(p *process.Process, m *messages.Message) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	switch string(m.GetString()) {
+	case Initiate:
+		g := &Global{
+			ID:        int32(len(c.snapshots)),
+			Initiator: p.Node,
+			Tick:      p.Network.Tick,
+			Processes: make(map[int32]*Local),
+		}
+		c.snapshots = append(c.snapshots, g)
+		c.record(p, g.ID, -1)
+		return true
+	case Marker:
+		id := m.GetInt32()
+		if _, ok := c.progress[p.Node][id]; !ok {
+			c.record(p, id, m.From)
+		} else {
+			c.close(p, id, m.From)
+		}
+		return true
+	}
+	if m.From >= 0 {
+		for _, pr := range c.progress[p.Node] {
+			if pr.open[m.From] {
+				pr.local.Channels[m.From] = append(pr.local.Channels[m.From], m.String())
+			}
+		}
+	}
+	return false
+}
+
+// record saves the state of the process and sends markers by all the outgoing channels.
+// The channel the first marker has come from is empty.
+func (c *Collector) record(p *process.Process, id int32, from int32) {
+	pr := &progress{
+		local: &Local{
+			Node:     p.Node,
+			Tick:     p.Network.Tick,
+			Context:  make(map[string]context.Context, len(p.Context)),
+			Channels: make(map[int32][]string),
+		},
+		open: make(map[int32]bool),
+	}
+	for key, value := range p.Context {
+		pr.local.Context[key] = Copy(value)
+	}
+	for u := range p.Network.QueueMap {
+		if v := int32(u); v != p.Node && p.Network.QueueMap[u] != nil && p.Network.GetLink(v, p.Node) >= 0 {
+			pr.local.Channels[v] = []string{}
+			if v != from {
+				pr.open[v] = true
+			}
+		}
+	}
+	c.progress[p.Node][id] = pr
+	for _, v := range p.Neighbours() {
+		if v != p.Node {
+			p.Network.SendMessage(p.Node, v, markerMessage(id))
+		}
+	}
+	if len(pr.open) == 0 {
+		c.finish(p.Node, id)
+	}
+}
+
+// close stops recording the channel after the marker has come by it.
+func (c *Collector) close(p *process.Process, id int32, from int32) {
+	pr := c.progress[p.Node][id]
+	if !pr.open[from] {
+		return
+	}
+	delete(pr.open, from)
+	if len(pr.open) == 0 {
+		c.finish(p.Node, id)
+	}
+}
+
+// finish adds the local state to the global one.
+func (c *Collector) finish(node int32, id int32) {
+	if int(id) >= len(c.snapshots) {
+		return
+	}
+	g := c.snapshots[id]
+	g.Processes[node] = c.progress[node][id].local
+	g.Complete = len(g.Processes) == c.attached
+}
+
+// Nodes returns the sorted nodes of the processes recorded by the snapshot.
+func (g *Global) Nodes() []int32 {
+	res := make([]int32, 0, len(g.Processes))
+	for node := range g.Processes {
+		res = append(res, node)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/network"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+type counter struct {
+	N int
+}
+
+// deliver handles all the messages in order of delivery.
+func deliver(nl *network.Network, ps []*process.Process) {
+	for {
+		var next *process.Process
+		for _, p := range ps {
+			if p.MessagesQueue.Size() > 0 && (next == nil || p.MessagesQueue.Peek().DeliveryTime < next.MessagesQueue.Peek().DeliveryTime) {
+				next = p
+			}
+		}
+		if next == nil {
+			return
+		}
+		nl.Tick = next.MessagesQueue.Peek().DeliveryTime
+		next.Handle(next.MessagesQueue.Dequeue())
+	}
+}
+
+func TestCollector(t *testing.T) {
+	nl := network.NewVirtual()
+	defer nl.Stop()
+	nl.FIFO = true
+	c := NewCollector()
+	ps := make([]*process.Process, 3)
+	for i := range ps {
+		ps[i] = process.NewPassive(int32(i))
+		nl.RegisterProcess(int32(i), ps[i])
+		ps[i].Context["Counter"] = &counter{}
+		ps[i].RegisterWorkFunction(nil, func(p *process.Process, m *messages.Message) bool {
+			p.Context["Counter"].(*counter).N++
+			return true
+		})
+		c.Attach(ps[i])
+	}
+	nl.CreateLink(0, 1, true, 5)
+	nl.CreateLink(1, 2, false, 1)
+
+	nl.SendMessage(0, 1, messages.NewMessageByArgs(messages.NewMessageArg([]byte("A"))))
+	nl.SendMessage(-1, 1, InitiateMessage())
+	deliver(nl, ps)
+
+	got := c.Snapshots()
+	if len(got) != 1 {
+		t.Fatalf("Collector.Snapshots() = %v, want one snapshot", got)
+	}
+	g := got[0]
+	if !g.Complete || g.Initiator != 1 || !reflect.DeepEqual(g.Nodes(), []int32{0, 1, 2}) {
+		t.Fatalf("Collector.Snapshots() = %+v", g)
+	}
+	channels := map[int32]map[int32][]string{
+		0: {1: {}},
+		1: {0: {"A"}},
+		2: {1: {}},
+	}
+	for node, want := range channels {
+		if l := g.Processes[node]; !reflect.DeepEqual(l.Channels, want) {
+			t.Errorf("process %v channels = %v, want %v", node, l.Channels, want)
+		}
+	}
+	if n := g.Processes[1].Context["Counter"].(*counter).N; n != 0 {
+		t.Errorf("process 1 recorded counter %v, want 0", n)
+	}
+	if n := ps[1].Context["Counter"].(*counter).N; n != 1 {
+		t.Errorf("process 1 counter = %v, want 1: snapshot messages reached the working functions", n)
+	}
+}
@@ -0,0 +1,60 @@
+package sweep
+
+import (
+	"math"
+)
+
+// Summary summarises a sample: its size, mean, sample standard deviation and the half-width
+// of the 95% confidence interval of the mean by Student's t-distribution.
+// The deviation and the interval are 0 for samples of less than two values.
+type Summary struct {
+	N      int
+	Mean   float64
+	StdDev float64
+	CI95   float64
+}
+
+// Summarize computes the summary of the sample.
+func Summarize(sample []float64) Summary {
+	res := Summary{N: len(sample)}
+	if res.N == 0 {
+		return res
+	}
+	for _, v := range sample {
+		res.Mean += v
+	}
+	res.Mean /= float64(res.N)
+	if res.N < 2 {
+		return res
+	}
+	var squares float64
+	for _, v := range sample {
+		squares += (v - res.Mean) * (v - res.Mean)
+	}
+	res.StdDev = math.Sqrt(squares / float64(res.N-1))
+	res.CI95 = t975(res.N-1) * res.StdDev / math.Sqrt(float64(res.N))
+	return res
+}
+
+// quantiles are the 0.975 quantiles of Student's t-distribution with 1 to 30 degrees of freedom.
+var quantiles = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// t975 returns the 0.975 quantile of Student's t-distribution with the degrees of freedom,
+// rounded up to the nearest tabulated one for more than 30 of them.
+func t975(df int) float64 {
+	switch {
+	case df <= len(quantiles):
+		return quantiles[df-1]
+	case df < 40:
+		return 2.042
+	case df < 60:
+		return 2.021
+	case df < 120:
+		return 2.000
+	}
+	return 1.980
+}
@@ -0,0 +1,306 @@
+// Package sweep runs scenarios across grids of parameters and aggregates the metrics of the runs.
+//
+// Parameters are substituted into scenario templates: every ${name} in the scenario text is replaced
+// by the value of the parameter, e.g. "errorRate ${loss}" or "processes 0 ${n}". Every point of the grid
+// is run with several seeds in isolated worlds in parallel, the metrics of the runs of every point
+// are summarised by their means, standard deviations and 95% confidence intervals.
+package sweep
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/metrics"
+)
+
+// Parameter is a named list of values.
+type Parameter struct {
+	Name   string
+	Values []float64
+}
+
+// ParseParameter parses a parameter given as "name=from..to step s", "name=from..to" (step 1)
+// or "name=v1,v2,...", e.g. "errorRate=0..0.5 step 0.05".
+func ParseParameter(s string) (Parameter, error) {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return Parameter{}, fmt.Errorf("parameter %q is not name=values", s)
+	}
+	p := Parameter{Name: strings.TrimSpace(s[:i])}
+	values := strings.TrimSpace(s[i+1:])
+	if strings.Contains(values, "..") {
+		bounds, step := values, "1"
+		if i := strings.Index(values, " step "); i >= 0 {
+			bounds, step = values[:i], values[i+len(" step "):]
+		}
+		ends := strings.Split(bounds, "..")
+		if len(ends) != 2 {
+			return Parameter{}, fmt.Errorf("parameter %v: range %q is not from..to [step s]", p.Name, values)
+		}
+		from, err := parseFloat(ends[0])
+		if err != nil {
+			return Parameter{}, fmt.Errorf("parameter %v: %v", p.Name, err)
+		}
+		to, err := parseFloat(ends[1])
+		if err != nil {
+			return Parameter{}, fmt.Errorf("parameter %v: %v", p.Name, err)
+		}
+		s, err := parseFloat(step)
+		if err != nil {
+			return Parameter{}, fmt.Errorf("parameter %v: %v", p.Name, err)
+		}
+		if s <= 0 || to < from {
+			return Parameter{}, fmt.Errorf("parameter %v: empty range %q", p.Name, values)
+		}
+		for k := 0; k <= int(math.Floor((to-from)/s+1e-9)); k++ {
+			p.Values = append(p.Values, round(from+float64(k)*s))
+		}
+		return p, nil
+	}
+	for _, v := range strings.Split(values, ",") {
+		f, err := parseFloat(v)
+		if err != nil {
+			return Parameter{}, fmt.Errorf("parameter %v: %v", p.Name, err)
+		}
+		p.Values = append(p.Values, f)
+	}
+	return p, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+// round removes the floating point errors accumulated by the steps of ranges.
+func round(v float64) float64 {
+	return math.Round(v*1e9) / 1e9
+}
+
+// Value is the value of a parameter at a point of a grid.
+type Value struct {
+	Name  string
+	Value float64
+}
+
+// Point is a point of a grid: a value of every parameter.
+type Point []Value
+
+func (p Point) String() string {
+	res := make([]string, 0, len(p))
+	for _, v := range p {
+		res = append(res, v.Name+"="+format(v.Value))
+	}
+	return strings.Join(res, " ")
+}
+
+// Get returns the value of the parameter at the point, the second result is false if there is no such parameter.
+func (p Point) Get(name string) (float64, bool) {
+	for _, v := range p {
+		if v.Name == name {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// format formats the value as short as possible, integers without the fraction.
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Grid returns all the combinations of the values of the parameters, the last parameter varying fastest.
+// Without parameters the grid is a single empty point.
+func Grid(params []Parameter) []Point {
+	res := []Point{{}}
+	for _, p := range params {
+		next := make([]Point, 0, len(res)*len(p.Values))
+		for _, point := range res {
+			for _, v := range p.Values {
+				next = append(next, append(append(Point{}, point...), Value{p.Name, v}))
+			}
+		}
+		res = next
+	}
+	return res
+}
+
+// Expand substitutes the values of the point into the template and returns the names of the parameters
+// the template does not refer to.
+func Expand(template []byte, p Point) ([]byte, []string) {
+	res := string(template)
+	unused := make([]string, 0)
+	for _, v := range p {
+		placeholder := "${" + v.Name + "}"
+		if !strings.Contains(res, placeholder) {
+			unused = append(unused, v.Name)
+			continue
+		}
+		res = strings.ReplaceAll(res, placeholder, format(v.Value))
+	}
+	return []byte(res), unused
+}
+
+// placeholder matches the references to parameters.
+var placeholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// Missing returns the names of the parameters the expanded template still refers to, in order of appearance.
+func Missing(text []byte) []string {
+	res := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range placeholder.FindAllSubmatch(text, -1) {
+		if name := string(m[1]); !seen[name] {
+			seen[name] = true
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// Run is a single run of a sweep: the point of the grid, the seed and the metrics, nil if the run has failed.
+type Run struct {
+	Point   Point
+	Seed    int64
+	Metrics *metrics.Metrics
+}
+
+// Execute runs every point of the grid with every seed by the function, at most parallel runs at once,
+// and returns the runs ordered by points and seeds. The function must use an isolated world for every run.
+func Execute(grid []Point, seeds []int64, parallel int, run func(p Point, seed int64) *metrics.Metrics) []Run {
+	res := make([]Run, 0, len(grid)*len(seeds))
+	for _, p := range grid {
+		for _, seed := range seeds {
+			res = append(res, Run{Point: p, Seed: seed})
+		}
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res[j].Metrics = run(res[j].Point, res[j].Seed)
+			}
+		}()
+	}
+	for j := range res {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	return res
+}
+
+// Column is a metric of runs written by sweeps.
+type Column struct {
+	Name  string
+	Value func(m *metrics.Metrics) float64
+}
+
+// Columns are the metrics written by sweeps.
+var Columns = []Column{
+	{"ticks", func(m *metrics.Metrics) float64 { return float64(m.Ticks) }},
+	{"sent", func(m *metrics.Metrics) float64 { return float64(m.Sent) }},
+	{"bytes", func(m *metrics.Metrics) float64 { return float64(m.Bytes) }},
+	{"delivered", func(m *metrics.Metrics) float64 { return float64(m.Delivered) }},
+	{"dropped", func(m *metrics.Metrics) float64 { return float64(m.Drops()) }},
+	{"unhandled", func(m *metrics.Metrics) float64 { return float64(m.Unhandled) }},
+	{"lastActivity", func(m *metrics.Metrics) float64 { return float64(m.LastActivity) }},
+}
+
+// names returns the names of the parameters of the runs.
+func names(runs []Run) []string {
+	res := make([]string, 0)
+	if len(runs) > 0 {
+		for _, v := range runs[0].Point {
+			res = append(res, v.Name)
+		}
+	}
+	return res
+}
+
+// values returns the values of the point as CSV cells.
+func values(p Point) []string {
+	res := make([]string, 0, len(p))
+	for _, v := range p {
+		res = append(res, format(v.Value))
+	}
+	return res
+}
+
+// groups splits the runs into the runs of every point keeping their order.
+func groups(runs []Run) [][]Run {
+	res := make([][]Run, 0)
+	index := make(map[string]int)
+	for _, r := range runs {
+		key := r.Point.String()
+		i, ok := index[key]
+		if !ok {
+			i = len(res)
+			index[key] = i
+			res = append(res, nil)
+		}
+		res[i] = append(res[i], r)
+	}
+	return res
+}
+
+// Rows returns the runs as CSV rows with a header: the parameters, the seed, whether the run has succeeded
+// and the metrics.
+func Rows(runs []Run) [][]string {
+	header := append(names(runs), "seed", "ok")
+	for _, c := range Columns {
+		header = append(header, c.Name)
+	}
+	res := [][]string{header}
+	for _, r := range runs {
+		row := append(values(r.Point), strconv.FormatInt(r.Seed, 10), strconv.FormatBool(r.Metrics != nil))
+		for _, c := range Columns {
+			if r.Metrics == nil {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, format(c.Value(r.Metrics)))
+		}
+		res = append(res, row)
+	}
+	return res
+}
+
+// Summaries returns the summaries of the metrics of the succeeded runs of every point as CSV rows
+// with a header: the parameters, the numbers of runs and failed runs and the mean, the standard deviation
+// and the half-width of the 95% confidence interval of every metric.
+func Summaries(runs []Run) [][]string {
+	header := append(names(runs), "runs", "failed")
+	for _, c := range Columns {
+		header = append(header, c.Name+"_mean", c.Name+"_stddev", c.Name+"_ci95")
+	}
+	res := [][]string{header}
+	for _, g := range groups(runs) {
+		row := append(values(g[0].Point), strconv.Itoa(len(g)))
+		ok := make([]*metrics.Metrics, 0, len(g))
+		for _, r := range g {
+			if r.Metrics != nil {
+				ok = append(ok, r.Metrics)
+			}
+		}
+		row = append(row, strconv.Itoa(len(g)-len(ok)))
+		for _, c := range Columns {
+			sample := make([]float64, 0, len(ok))
+			for _, m := range ok {
+				sample = append(sample, c.Value(m))
+			}
+			s := Summarize(sample)
+			row = append(row, fmt.Sprintf("%.4f", s.Mean), fmt.Sprintf("%.4f", s.StdDev), fmt.Sprintf("%.4f", s.CI95))
+		}
+		res = append(res, row)
+	}
+	return res
+}
@@ -0,0 +1,157 @@
+package sweep
+
+import (
+	"math"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/metrics"
+)
+
+func TestParseParameter(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Parameter
+		wantErr bool
+	}{
+		{"Range", "errorRate=0..0.5 step 0.1", Parameter{"errorRate", []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5}}, false},
+		{"Default step", "n = 3..6", Parameter{"n", []float64{3, 4, 5, 6}}, false},
+		{"Inexact end", "n=1..2 step 0.4", Parameter{"n", []float64{1, 1.4, 1.8}}, false},
+		{"List", "fanout=1, 2,4", Parameter{"fanout", []float64{1, 2, 4}}, false},
+		{"Single", "n=5", Parameter{"n", []float64{5}}, false},
+		{"No name", "=1..2", Parameter{}, true},
+		{"No values", "errorRate", Parameter{}, true},
+		{"Empty range", "n=5..1", Parameter{}, true},
+		{"Zero step", "n=1..5 step 0", Parameter{}, true},
+		{"Bad range", "n=1..2..3", Parameter{}, true},
+		{"Bad value", "n=1,x", Parameter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseParameter(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseParameter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseParameter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	p, _ := ParseParameter("errorRate=0..0.5 step 0.05")
+	if len(p.Values) != 11 || p.Values[3] != 0.15 {
+		t.Errorf("ParseParameter() = %v, want 11 values with 0.15", p.Values)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	got := Grid([]Parameter{{"a", []float64{1, 2}}, {"b", []float64{0.5, 1.5}}})
+	want := []Point{
+		{{"a", 1}, {"b", 0.5}},
+		{{"a", 1}, {"b", 1.5}},
+		{{"a", 2}, {"b", 0.5}},
+		{{"a", 2}, {"b", 1.5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Grid() = %v, want %v", got, want)
+	}
+	if got := Grid(nil); len(got) != 1 || len(got[0]) != 0 {
+		t.Errorf("Grid(nil) = %v, want a single empty point", got)
+	}
+	if got := want[1].String(); got != "a=1 b=1.5" {
+		t.Errorf("Point.String() = %v, want a=1 b=1.5", got)
+	}
+	if v, ok := want[1].Get("b"); !ok || v != 1.5 {
+		t.Errorf("Point.Get() = %v, %v, want 1.5, true", v, ok)
+	}
+	if _, ok := want[1].Get("c"); ok {
+		t.Errorf("Point.Get() of a missing parameter is ok")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	p := Point{{"n", 9}, {"loss", 0.25}, {"errorRate", 0.1}}
+	got, unused := Expand([]byte("processes 0 ${n}\nerrorRate ${loss}\n; ${n} processes\n"), p)
+	if string(got) != "processes 0 9\nerrorRate 0.25\n; 9 processes\n" {
+		t.Errorf("Expand() = %q", got)
+	}
+	if !reflect.DeepEqual(unused, []string{"errorRate"}) {
+		t.Errorf("Expand() unused = %v, want [errorRate]", unused)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	got := Missing([]byte("processes 0 ${n}\nerrorRate ${loss}\n; ${n} processes, ${}\n"))
+	if want := []string{"n", "loss", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing() = %v, want %v", got, want)
+	}
+	if got := Missing([]byte("processes 0 9\n")); len(got) != 0 {
+		t.Errorf("Missing() = %v, want none", got)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	grid := Grid([]Parameter{{"n", []float64{1, 2, 3}}})
+	var calls int64
+	runs := Execute(grid, []int64{10, 20}, 4, func(p Point, seed int64) *metrics.Metrics {
+		atomic.AddInt64(&calls, 1)
+		n, _ := p.Get("n")
+		if n == 2 && seed == 20 {
+			return nil
+		}
+		return &metrics.Metrics{Ticks: int64(n)*100 + seed}
+	})
+	if calls != 6 || len(runs) != 6 {
+		t.Fatalf("Execute() made %v calls and %v runs, want 6", calls, len(runs))
+	}
+	for i, r := range runs {
+		n, _ := r.Point.Get("n")
+		if n != float64(i/2+1) || r.Seed != []int64{10, 20}[i%2] {
+			t.Errorf("Execute()[%v] = %v seed %v, out of order", i, r.Point, r.Seed)
+		}
+		if (r.Metrics == nil) != (i == 3) || r.Metrics != nil && r.Metrics.Ticks != int64(n)*100+r.Seed {
+			t.Errorf("Execute()[%v].Metrics = %+v", i, r.Metrics)
+		}
+	}
+
+	rows := Rows(runs)
+	if len(rows) != 7 || !reflect.DeepEqual(rows[0][:4], []string{"n", "seed", "ok", "ticks"}) {
+		t.Errorf("Rows() header = %v", rows[0])
+	}
+	if !reflect.DeepEqual(rows[4][:4], []string{"2", "20", "false", ""}) {
+		t.Errorf("Rows()[4] = %v", rows[4])
+	}
+	summaries := Summaries(runs)
+	if len(summaries) != 4 || !reflect.DeepEqual(summaries[0][:6], []string{"n", "runs", "failed", "ticks_mean", "ticks_stddev", "ticks_ci95"}) {
+		t.Errorf("Summaries() header = %v", summaries[0])
+	}
+	if !reflect.DeepEqual(summaries[1][:4], []string{"1", "2", "0", "115.0000"}) {
+		t.Errorf("Summaries()[1] = %v", summaries[1])
+	}
+	if !reflect.DeepEqual(summaries[2][:6], []string{"2", "2", "1", "210.0000", "0.0000", "0.0000"}) {
+		t.Errorf("Summaries()[2] = %v", summaries[2])
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample []float64
+		want   Summary
+	}{
+		{"Empty", nil, Summary{}},
+		{"Single", []float64{3}, Summary{N: 1, Mean: 3}},
+		{"Pair", []float64{1, 3}, Summary{N: 2, Mean: 2, StdDev: math.Sqrt2, CI95: 12.706}},
+		{"Sample", []float64{2, 4, 4, 4, 5, 5, 7, 9}, Summary{N: 8, Mean: 5, StdDev: 2.138090, CI95: 1.787772}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.sample)
+			if got.N != tt.want.N || math.Abs(got.Mean-tt.want.Mean) > 1e-6 ||
+				math.Abs(got.StdDev-tt.want.StdDev) > 1e-6 || math.Abs(got.CI95-tt.want.CI95) > 1e-6 {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package termination
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+// dsState is the state of a process in the Dijkstra–Scholten algorithm.
+// The process is engaged while it or its children have unacknowledged messages.
+// A process engaged by a message of the model is a root of its own tree.
+type dsState struct {
+	detector *Detector
+	engaged  bool
+	parent   int32
+	deficit  int64
+}
+
+// handle acknowledges every message of the computation: the first one of an engagement
+// when the process leaves the tree, the rest of them at once.
+func (s *dsState) handle(p *process.Process, m *messages.Message, handle func() bool) bool {
+	if isControl(m, "DS_ACK") {
+		s.deficit--
+		s.leave(p)
+		return true
+	}
+	external := m.From < 0
+	s.detector.activity(p.Network.Tick, external)
+	switch {
+	case !s.engaged && external:
+		s.engaged, s.parent = true, -1
+		s.detector.rootStarted()
+	case !s.engaged:
+		s.engaged, s.parent = true, m.From
+	case !external:
+		p.Network.SendMessage(p.Node, m.From, messages.NewMessageByType("DS_ACK"))
+	}
+	res, sent := basic(p, handle)
+	s.deficit += sent
+	s.leave(p)
+	return res
+}
+
+// leave disengages the process when all its messages are acknowledged.
+func (s *dsState) leave(p *process.Process) {
+	if !s.engaged || s.deficit > 0 {
+		return
+	}
+	s.engaged = false
+	if s.parent < 0 {
+		s.detector.rootFinished(p.Network.Tick)
+		return
+	}
+	p.Network.SendMessage(p.Node, s.parent, messages.NewMessageByType("DS_ACK"))
+}
+
+// rootStarted counts a new tree of the computation.
+func (d *Detector) rootStarted() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.roots++
+}
+
+// rootFinished detects termination when all the trees are finished.
+func (d *Detector) rootFinished(tick int64) {
+	d.mutex.Lock()
+	d.roots--
+	finished := d.roots == 0
+	d.mutex.Unlock()
+	if finished {
+		d.detect(tick)
+	}
+}
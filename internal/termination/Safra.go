@@ -0,0 +1,85 @@
+package termination
+
+import (
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+// safraState is the state of a process in Safra's algorithm.
+// Count is the number of messages of the computation sent minus the number of received ones,
+// a process turns black when it receives a message of the computation.
+type safraState struct {
+	detector *Detector
+	count    int64
+	black    bool
+	probing  bool
+}
+
+// next returns the process the token is passed to: the next one by node numbers.
+func next(p *process.Process, nodes []int32) int32 {
+	i := sort.Search(len(nodes), func(i int) bool { return nodes[i] > p.Node })
+	if i == len(nodes) {
+		return nodes[0]
+	}
+	return nodes[i]
+}
+
+// probe starts a new round of the token at the initiator.
+func (s *safraState) probe(p *process.Process) {
+	s.probing, s.black = true, false
+	p.Network.SendMessage(p.Node, next(p, p.Network.Nodes()), messages.NewMessageByType("SAFRA_TOKEN", 0, 0))
+}
+
+// handle counts messages of the computation and passes the token on, since processes are passive
+// between messages. The process with the least node is the initiator, it is woken up by the model
+// when a computation starts and probes until the token returns white with zero count.
+func (s *safraState) handle(p *process.Process, m *messages.Message, handle func() bool) bool {
+	nodes := p.Network.Nodes()
+	initiator := nodes[0] == p.Node
+	switch {
+	case isControl(m, "SAFRA_WAKE"):
+		if !s.probing {
+			s.probe(p)
+		}
+		return true
+	case isControl(m, "SAFRA_TOKEN"):
+		m.Ptr = 0
+		m.GetString()
+		count, black := int64(m.GetInt32())+s.count, m.GetInt32() == 1 || s.black
+		if !initiator {
+			s.black = false
+			color := int32(0)
+			if black {
+				color = 1
+			}
+			p.Network.SendMessage(p.Node, next(p, nodes), messages.NewMessageByType("SAFRA_TOKEN", int32(count), color))
+			return true
+		}
+		if !black && count == 0 {
+			s.probing = false
+			s.detector.detect(p.Network.Tick)
+			return true
+		}
+		s.probe(p)
+		return true
+	}
+	external := m.From < 0
+	s.detector.activity(p.Network.Tick, external)
+	if external {
+		if initiator {
+			if !s.probing {
+				defer s.probe(p)
+			}
+		} else {
+			p.Network.SendMessage(-1, nodes[0], messages.NewMessageByType("SAFRA_WAKE"))
+		}
+	} else {
+		s.count--
+		s.black = true
+	}
+	res, sent := basic(p, handle)
+	s.count += sent
+	return res
+}
@@ -0,0 +1,122 @@
+package termination
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/logging"
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+// Algorithms of termination detection.
+const (
+	// DijkstraScholten detects termination of diffusing computations by acknowledgements
+	// sent along the spanning trees of the computations. It requires bidirected links.
+	DijkstraScholten = "DS"
+	// Safra detects termination by a token counting messages in transit.
+	// It requires links from every process to the next one by node numbers and from the last one to the first one.
+	Safra = "SAFRA"
+)
+
+// Algorithms returns the names of all the termination detection algorithms.
+func Algorithms() []string {
+	return []string{DijkstraScholten, Safra}
+}
+
+// Report compares the detected termination with the true one.
+type Report struct {
+	// Detected reports whether termination has been detected.
+	Detected bool
+	// Detection is the tick of the last detection.
+	Detection int64
+	// Quiescence is the tick of the last message of the computation.
+	Quiescence int64
+	// Premature is set if the computation has gone on after a detection.
+	Premature bool
+}
+
+// Delay returns the number of ticks between the true termination and its detection.
+func (r Report) Delay() int64 {
+	return r.Detection - r.Quiescence
+}
+
+// Detector detects termination of the computation performed by the attached processes.
+// The computation is started by the messages of the model. Messages of the computation may be lost,
+// but the control messages of the detector must not.
+type Detector struct {
+	algorithm  string
+	mutex      sync.Mutex
+	roots      int
+	terminated bool
+	report     Report
+}
+
+// New creates a detector using the algorithm.
+func New(algorithm string) (*Detector, error) {
+	switch algorithm {
+	case DijkstraScholten, Safra:
+		return &Detector{algorithm: algorithm}, nil
+	}
+	return nil, fmt.Errorf("unknown termination detection algorithm %v", algorithm)
+}
+
+// Algorithm returns the name of the algorithm.
+func (d *Detector) Algorithm() string {
+	return d.algorithm
+}
+
+// Attach layers the detection algorithm over the working functions of the process.
+func (d *Detector) Attach(p *process.Process) {
+	switch d.algorithm {
+	case DijkstraScholten:
+		p.Use((&dsState{detector: d, parent: none}).handle)
+	case Safra:
+		p.Use((&safraState{detector: d}).handle)
+	}
+}
+
+// Report returns the comparison of the detected termination with the true one.
+func (d *Detector) Report() Report {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.report
+}
+
+// activity records a message of the computation handled at the tick.
+// Messages of the model start a new computation, any other message after a detection means it was premature.
+func (d *Detector) activity(tick int64, external bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if external {
+		d.terminated = false
+	} else if d.terminated {
+		d.report.Premature = true
+	}
+	d.report.Quiescence = tick
+}
+
+// detect records the termination detected at the tick.
+func (d *Detector) detect(tick int64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.terminated = true
+	d.report.Detected = true
+	d.report.Detection = tick
+	logging.Infof("termination detected by %v at tick %v", d.algorithm, tick)
+}
+
+// none marks the absent parent.
+const none = -2
+
+// isControl reports whether the message is a control message of the type.
+func isControl(m *messages.Message, t string) bool {
+	return string(m.Type()) == t
+}
+
+// basic handles a message of the computation, returning whether it is accepted and the number of messages sent.
+func basic(p *process.Process, handle func() bool) (bool, int64) {
+	before := p.Network.Metrics().Sends(p.Node)
+	res := handle()
+	return res, p.Network.Metrics().Sends(p.Node) - before
+}
@@ -0,0 +1,34 @@
+package termination
+
+import "testing"
+
+func TestNew(t *testing.T) {
+	for _, algorithm := range Algorithms() {
+		d, err := New(algorithm)
+		if err != nil || d.Algorithm() != algorithm {
+			t.Errorf("New(%v) = %v, %v", algorithm, d, err)
+		}
+	}
+	if _, err := New("UNKNOWN"); err == nil {
+		t.Errorf("New(UNKNOWN) error = nil")
+	}
+}
+
+func TestDetector_Report(t *testing.T) {
+	d, _ := New(Safra)
+	d.activity(3, true)
+	d.activity(7, false)
+	d.detect(10)
+	if got, want := d.Report(), (Report{Detected: true, Detection: 10, Quiescence: 7}); got != want || got.Delay() != 3 {
+		t.Errorf("Detector.Report() = %+v, want %+v", got, want)
+	}
+	d.activity(12, false)
+	if !d.Report().Premature {
+		t.Errorf("Detector.Report(): activity after detection is not premature")
+	}
+	d.activity(15, true)
+	d.detect(20)
+	if got := d.Report(); !got.Premature || got.Detection != 20 {
+		t.Errorf("Detector.Report() = %+v", got)
+	}
+}
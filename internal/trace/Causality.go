@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"fmt"
+
+	"github.com/trmigor/distr-model/internal/clock"
+)
+
+// HappensBefore reports whether the event a happens before the event b by their vector timestamps.
+func HappensBefore(a Event, b Event) bool {
+	return clock.HappensBefore(a.Vector, b.Vector)
+}
+
+// owner returns the process performing the event or -1 for the model.
+func (e Event) owner() int32 {
+	if e.Kind == Deliver {
+		return e.To
+	}
+	return e.From
+}
+
+// channel identifies messages, which can not be told apart in a trace.
+type channel struct {
+	from, to int32
+	message  string
+	delivery int64
+}
+
+// CheckCausality checks that the logical timestamps of the events agree with the happens-before relation:
+// the events of every process have growing timestamps and every delivery has a timestamp
+// greater than the one of the send. Events without timestamps are skipped.
+func CheckCausality(events []Event) error {
+	last := make(map[int32]Event)
+	sent := make(map[channel][]Event)
+	for _, e := range events {
+		if e.Lamport == 0 {
+			continue
+		}
+		p := e.owner()
+		if prev, ok := last[p]; ok {
+			if prev.Lamport >= e.Lamport {
+				return fmt.Errorf("process %v: Lamport time does not grow from '%v' to '%v'", p, prev, e)
+			}
+			if e.Vector != nil && !HappensBefore(prev, e) {
+				return fmt.Errorf("process %v: '%v' does not happen before '%v'", p, prev, e)
+			}
+		}
+		last[p] = e
+		ch := channel{e.From, e.To, e.Message, e.Delivery}
+		switch e.Kind {
+		case Send:
+			sent[ch] = append(sent[ch], e)
+		case Deliver:
+			if e.From < 0 || len(sent[ch]) == 0 {
+				continue
+			}
+			s := sent[ch][0]
+			sent[ch] = sent[ch][1:]
+			if s.Lamport >= e.Lamport {
+				return fmt.Errorf("Lamport time of '%v' is not greater than the one of its send '%v'", e, s)
+			}
+			if e.Vector != nil && !HappensBefore(s, e) {
+				return fmt.Errorf("send '%v' does not happen before its delivery '%v'", s, e)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package trace
+
+import "testing"
+
+func TestCheckCausality(t *testing.T) {
+	send := Event{Kind: Send, From: 0, To: 1, Message: "A", Delivery: 2, Lamport: 1, Vector: []int64{1}}
+	deliver := Event{Tick: 2, Kind: Deliver, From: 0, To: 1, Message: "A", Delivery: 2, Lamport: 2, Vector: []int64{1, 1}}
+	stale := deliver
+	stale.Vector = []int64{0, 1}
+	tests := []struct {
+		name    string
+		events  []Event
+		wantErr bool
+	}{
+		{"Valid", []Event{send, deliver}, false},
+		{"NoStamps", []Event{{Kind: Send, From: 0, To: 1}, {Kind: Deliver, From: 0, To: 1}}, false},
+		{"LamportBackwards", []Event{send, {Kind: Send, From: 0, To: 1, Lamport: 1, Vector: []int64{2}}}, true},
+		{"Stale", []Event{send, stale}, true},
+		{"LamportStale", []Event{send, {Kind: Deliver, From: 0, To: 1, Message: "A", Delivery: 2, Lamport: 1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckCausality(tt.events); (err != nil) != tt.wantErr {
+				t.Errorf("CheckCausality() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if !HappensBefore(send, deliver) || HappensBefore(deliver, send) {
+		t.Errorf("HappensBefore() does not order the send before the delivery")
+	}
+}
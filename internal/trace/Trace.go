@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"sync"
+)
+
+// Kind is a kind of trace events.
+type Kind string
+
+const (
+	// Send marks messages accepted by the network.
+	Send Kind = "send"
+	// Drop marks messages rejected or lost by the network.
+	Drop Kind = "drop"
+	// Deliver marks messages passed to the process working functions.
+	Deliver Kind = "deliver"
+	// Enter marks entries of processes into the critical section.
+	Enter Kind = "enter"
+	// Exit marks exits of processes from the critical section.
+	Exit Kind = "exit"
+	// Decide marks values decided by processes in consensus algorithms,
+	// the message is the name of the algorithm, the slot and the value.
+	Decide Kind = "decide"
+	// Broadcast marks messages broadcast by processes in broadcast algorithms,
+	// the message is the name of the algorithm, the origin, the sequence number and the value.
+	Broadcast Kind = "broadcast"
+	// Accept marks broadcast messages delivered to processes by broadcast algorithms,
+	// the message is the same as the one of the broadcast event.
+	Accept Kind = "accept"
+	// Forward marks routed messages passed by intermediate processes to the next hops,
+	// From is the forwarding process and To is the next hop.
+	Forward Kind = "forward"
+	// Route marks changes of routing tables of processes by routing protocols,
+	// the message is the name of the protocol, the receiver, the next hop and the cost, -1 for removed routes.
+	Route Kind = "route"
+)
+
+const (
+	// ReasonLoss marks messages lost according to the error rate.
+	ReasonLoss = "loss"
+	// ReasonNoLink marks messages sent over nonexistent links.
+	ReasonNoLink = "no link"
+	// ReasonNoProcess marks messages sent to nonexistent processes.
+	ReasonNoProcess = "no process"
+	// ReasonNoRoute marks routed messages without a route to their receivers.
+	ReasonNoRoute = "no route"
+	// ReasonOverflow marks messages dropped by links with full buffers.
+	ReasonOverflow = "overflow"
+	// ReasonByzantine marks messages rewritten or suppressed by the strategies of Byzantine processes.
+	ReasonByzantine = "byzantine"
+	// ReasonUser marks messages dropped by the user, e.g. in the debugger.
+	ReasonUser = "user"
+)
+
+// Event is a single event of a model run.
+// Lamport and Vector are the logical timestamps of the process performing the event, if clocks are enabled:
+// the sender for sends and drops, the receiver for deliveries.
+type Event struct {
+	Tick     int64   `json:"tick"`
+	Kind     Kind    `json:"kind"`
+	From     int32   `json:"from"`
+	To       int32   `json:"to"`
+	Message  string  `json:"message,omitempty"`
+	Size     int     `json:"size"`
+	Delivery int64   `json:"delivery,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+	Lamport  int64   `json:"lamport,omitempty"`
+	Vector   []int64 `json:"vector,omitempty"`
+}
+
+// Tracer receives the events of a model run.
+// Tracers must be safe for parallel usage.
+type Tracer interface {
+	Record(e Event)
+}
+
+// Recorder is a tracer keeping all the events in memory.
+type Recorder struct {
+	mutex  sync.Mutex
+	events []Event
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{events: make([]Event, 0)}
+}
+
+// Record implements Tracer.
+func (r *Recorder) Record(e Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, e)
+}
+
+// Events returns a copy of the recorded events.
+func (r *Recorder) Events() []Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Event{}, r.events...)
+}
+
+// Multi is a tracer passing events to several tracers.
+type Multi []Tracer
+
+// Record implements Tracer.
+func (m Multi) Record(e Event) {
+	for _, t := range m {
+		t.Record(e)
+	}
+}
+
+// Func is a function implementing Tracer.
+type Func func(e Event)
+
+// Record implements Tracer.
+func (f Func) Record(e Event) {
+	f(e)
+}
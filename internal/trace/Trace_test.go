@@ -0,0 +1,31 @@
+package trace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecorder_Events(t *testing.T) {
+	r := NewRecorder()
+	events := []Event{{Tick: 0, Kind: Send}, {Tick: 1, Kind: Deliver}}
+	for _, e := range events {
+		r.Record(e)
+	}
+	got := r.Events()
+	if !reflect.DeepEqual(got, events) {
+		t.Errorf("Recorder.Events() = %v, want %v", got, events)
+	}
+	got[0].Tick = 5
+	if r.Events()[0].Tick != 0 {
+		t.Errorf("Recorder.Events() returned internal slice")
+	}
+}
+
+func TestMulti_Record(t *testing.T) {
+	count := 0
+	f := Func(func(e Event) { count++ })
+	Multi{f, f, NewRecorder()}.Record(Event{})
+	if count != 2 {
+		t.Errorf("Multi.Record(): %v calls, want 2", count)
+	}
+}
@@ -0,0 +1,125 @@
+package trace
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Format is an output format of traces.
+type Format string
+
+const (
+	// Text is a human-readable format.
+	Text Format = "text"
+	// JSON is a format of JSON lines, one event per line.
+	JSON Format = "json"
+	// CSV is a format of comma-separated values with a header.
+	CSV Format = "csv"
+)
+
+// ParseFormat checks the format name.
+func ParseFormat(name string) (Format, bool) {
+	switch f := Format(name); f {
+	case Text, JSON, CSV:
+		return f, true
+	}
+	return Text, false
+}
+
+// Extension returns a file extension for the format.
+func (f Format) Extension() string {
+	if f == Text {
+		return ".txt"
+	}
+	return "." + string(f)
+}
+
+// Writer is a tracer writing events in the chosen format.
+type Writer struct {
+	mutex  sync.Mutex
+	out    io.Writer
+	format Format
+	csv    *csv.Writer
+	err    error
+}
+
+// NewWriter creates a tracer writing to out.
+func NewWriter(out io.Writer, format Format) *Writer {
+	w := &Writer{out: out, format: format}
+	if format == CSV {
+		w.csv = csv.NewWriter(out)
+		w.err = w.csv.Write([]string{"tick", "kind", "from", "to", "message", "size", "delivery", "reason", "lamport", "vector"})
+	}
+	return w
+}
+
+// Record implements Tracer.
+func (w *Writer) Record(e Event) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err != nil {
+		return
+	}
+	switch w.format {
+	case JSON:
+		var data []byte
+		data, w.err = json.Marshal(e)
+		if w.err == nil {
+			_, w.err = fmt.Fprintf(w.out, "%s\n", data)
+		}
+	case CSV:
+		w.err = w.csv.Write([]string{
+			strconv.FormatInt(e.Tick, 10), string(e.Kind),
+			strconv.Itoa(int(e.From)), strconv.Itoa(int(e.To)),
+			e.Message, strconv.Itoa(e.Size),
+			strconv.FormatInt(e.Delivery, 10), e.Reason,
+			strconv.FormatInt(e.Lamport, 10), vector(e.Vector),
+		})
+	default:
+		_, w.err = fmt.Fprintln(w.out, e)
+	}
+}
+
+// Flush writes any buffered data and returns the first error occurred.
+func (w *Writer) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.csv != nil && w.err == nil {
+		w.csv.Flush()
+		w.err = w.csv.Error()
+	}
+	return w.err
+}
+
+// String formats the event for humans.
+func (e Event) String() string {
+	res := fmt.Sprintf("[%v] %v %v -> %v: %v", e.Tick, e.Kind, e.From, e.To, e.Message)
+	if e.Kind == Send {
+		res += fmt.Sprintf(" (delivery at %v)", e.Delivery)
+	}
+	if e.Reason != "" {
+		res += fmt.Sprintf(" (%v)", e.Reason)
+	}
+	if e.Lamport > 0 {
+		res += fmt.Sprintf(" (clock %v", e.Lamport)
+		if e.Vector != nil {
+			res += fmt.Sprintf(" %v", e.Vector)
+		}
+		res += ")"
+	}
+	return res
+}
+
+// vector formats the vector timestamp as space-separated components.
+func vector(v []int64) string {
+	res := make([]string, len(v))
+	for i, x := range v {
+		res[i] = strconv.FormatInt(x, 10)
+	}
+	return strings.Join(res, " ")
+}
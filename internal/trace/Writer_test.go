@@ -0,0 +1,52 @@
+package trace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriter_Record(t *testing.T) {
+	e := Event{Tick: 1, Kind: Send, From: 0, To: 1, Message: "SETX_SET 5", Size: 15, Delivery: 2}
+	stamped := Event{Tick: 1, Kind: Deliver, From: 0, To: 1, Message: "A", Size: 3, Delivery: 1, Lamport: 3, Vector: []int64{1, 2}}
+	tests := []struct {
+		name   string
+		format Format
+		event  Event
+		want   string
+	}{
+		{"Text", Text, e, "[1] send 0 -> 1: SETX_SET 5 (delivery at 2)\n"},
+		{"JSON", JSON, e, `{"tick":1,"kind":"send","from":0,"to":1,"message":"SETX_SET 5","size":15,"delivery":2}` + "\n"},
+		{"CSV", CSV, e, "tick,kind,from,to,message,size,delivery,reason,lamport,vector\n1,send,0,1,SETX_SET 5,15,2,,0,\n"},
+		{"StampedText", Text, stamped, "[1] deliver 0 -> 1: A (clock 3 [1 2])\n"},
+		{"StampedJSON", JSON, stamped, `{"tick":1,"kind":"deliver","from":0,"to":1,"message":"A","size":3,"delivery":1,"lamport":3,"vector":[1,2]}` + "\n"},
+		{"StampedCSV", CSV, stamped, "tick,kind,from,to,message,size,delivery,reason,lamport,vector\n1,deliver,0,1,A,3,1,,3,1 2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewWriter(&b, tt.format)
+			w.Record(tt.event)
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Writer.Flush() error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Writer.Record() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"text", "json", "csv"} {
+		if f, ok := ParseFormat(name); !ok || string(f) != name {
+			t.Errorf("ParseFormat(%v) = %v, %v", name, f, ok)
+		}
+	}
+	if _, ok := ParseFormat("xml"); ok {
+		t.Errorf("ParseFormat(xml) succeeded")
+	}
+	if got := Text.Extension(); !strings.HasPrefix(got, ".") {
+		t.Errorf("Format.Extension() = %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+package world
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Decision is a value decided by a process in a slot of a replicated log.
+type Decision struct {
+	Tick  int64
+	Node  int32
+	Slot  int32
+	Value int32
+}
+
+// Conflict is a decision contradicting an earlier one: another value decided in the same slot.
+type Conflict struct {
+	Decision
+	Earlier Decision
+}
+
+// Agreement is a tracer checking agreement of consensus algorithms by the decide events of the processes:
+// all the values decided in a slot must be the same.
+type Agreement struct {
+	mutex     sync.Mutex
+	slots     map[int32]Decision
+	conflicts []Conflict
+	decisions int
+}
+
+// NewAgreement creates a checker without events.
+func NewAgreement() *Agreement {
+	return &Agreement{
+		slots:     make(map[int32]Decision),
+		conflicts: make([]Conflict, 0),
+	}
+}
+
+// Record implements trace.Tracer.
+func (a *Agreement) Record(e trace.Event) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	switch e.Kind {
+	case trace.Decide:
+		d := Decision{Tick: e.Tick, Node: e.From}
+		var name string
+		if _, err := fmt.Sscanf(e.Message, "%s %d %d", &name, &d.Slot, &d.Value); err != nil {
+			return
+		}
+		a.decisions++
+		earlier, ok := a.slots[d.Slot]
+		if !ok {
+			a.slots[d.Slot] = d
+		} else if earlier.Value != d.Value {
+			a.conflicts = append(a.conflicts, Conflict{Decision: d, Earlier: earlier})
+		}
+	}
+}
+
+// Conflicts returns the decisions contradicting the earlier ones.
+func (a *Agreement) Conflicts() []Conflict {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]Conflict{}, a.conflicts...)
+}
+
+// Decisions returns the number of decisions made by the processes.
+func (a *Agreement) Decisions() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.decisions
+}
+
+// Slots returns the number of slots decided by any process.
+func (a *Agreement) Slots() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return len(a.slots)
+}
+
+// CheckAgreement starts checking agreement of consensus algorithms by a new tracer.
+func (w *World) CheckAgreement() *Agreement {
+	a := NewAgreement()
+	w.AddTracer(a)
+	return a
+}
@@ -0,0 +1,38 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+func TestAgreement(t *testing.T) {
+	a := NewAgreement()
+	events := []trace.Event{
+		{Tick: 0, Kind: trace.Send, From: -1, To: 0},
+		{Tick: 1, Kind: trace.Send, From: 0, To: 1},
+		{Tick: 2, Kind: trace.Decide, From: 0, To: 0, Message: "PAXOS 0 5"},
+		{Tick: 3, Kind: trace.Decide, From: 1, To: 1, Message: "PAXOS 0 5"},
+		{Tick: 3, Kind: trace.Decide, From: 1, To: 1, Message: "PAXOS 1 7"},
+		{Tick: 4, Kind: trace.Send, From: 1, To: 1},
+		{Tick: 5, Kind: trace.Decide, From: 2, To: 2, Message: "PAXOS 1 8"},
+		{Tick: 6, Kind: trace.Decide, From: 2, To: 2, Message: "broken"},
+	}
+	for _, e := range events {
+		a.Record(e)
+	}
+	if got := a.Decisions(); got != 4 {
+		t.Errorf("Agreement.Decisions() = %v, want 4", got)
+	}
+	if got := a.Slots(); got != 2 {
+		t.Errorf("Agreement.Slots() = %v, want 2", got)
+	}
+	want := []Conflict{{
+		Decision: Decision{Tick: 5, Node: 2, Slot: 1, Value: 8},
+		Earlier:  Decision{Tick: 3, Node: 1, Slot: 1, Value: 7},
+	}}
+	if got := a.Conflicts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Agreement.Conflicts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,12 @@
+package world
+
+import (
+	"github.com/trmigor/distr-model/internal/broadcast"
+)
+
+// CheckBroadcast starts checking the properties of broadcast algorithms by a new tracer.
+func (w *World) CheckBroadcast() *broadcast.Checker {
+	c := broadcast.NewChecker()
+	w.AddTracer(c)
+	return c
+}
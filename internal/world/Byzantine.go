@@ -0,0 +1,22 @@
+package world
+
+import (
+	"fmt"
+
+	"github.com/trmigor/distr-model/internal/byzantine"
+)
+
+// SetByzantine makes the processes from the range Byzantine with the adversary strategy.
+func (w *World) SetByzantine(from, to int32, strategy string) error {
+	s, err := byzantine.New(strategy)
+	if err != nil {
+		return err
+	}
+	for i := from; i <= to; i++ {
+		if i < 0 || i >= int32(len(w.ProcessesList)) || w.ProcessesList[i] == nil {
+			return fmt.Errorf("no process %v", i)
+		}
+		w.Network.SetByzantine(i, s)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package world
+
+import (
+	"sort"
+	"time"
+
+	"github.com/trmigor/distr-model/internal/dashboard"
+)
+
+// dashboardPeriod is the least period of updates of the dashboard state in real time.
+const dashboardPeriod = 50 * time.Millisecond
+
+// Serve starts the dashboard of the world at the address, e.g. "localhost:8080", in background.
+// The page draws the network with the messages in flight and the sizes of the queues of the processes,
+// which are coloured by a field of their contexts. The state is published as the model goes on.
+func (w *World) Serve(addr string) (*dashboard.Server, error) {
+	s := dashboard.New()
+	if err := s.Start(addr); err != nil {
+		return nil, err
+	}
+	w.dashboard = s
+	w.publish(true)
+	return s, nil
+}
+
+// publish updates the state of the dashboard, if any, unless it has been updated recently.
+func (w *World) publish(force bool) {
+	if w.dashboard == nil || !force && time.Since(w.published) < dashboardPeriod {
+		return
+	}
+	w.published = time.Now()
+	var state dashboard.State
+	w.Network.Inspect(func() {
+		state = w.dashboardState()
+	})
+	w.dashboard.Update(state)
+}
+
+// checkpoint evaluates the properties and publishes the state of the dashboard.
+func (w *World) checkpoint() {
+	w.checkProperties()
+	w.publish(false)
+}
+
+// dashboardState returns the current state shown by the dashboard.
+// It reads the contexts of the processes, so it is called while no message is handled.
+func (w *World) dashboardState() dashboard.State {
+	res := dashboard.State{
+		Tick:     w.Network.Tick,
+		Nodes:    make([]dashboard.Node, 0, len(w.ProcessesList)),
+		Links:    make([]dashboard.Link, 0),
+		Messages: make([]dashboard.Message, 0),
+	}
+	if !w.Network.IsVirtual() {
+		res.TickMillis = w.Network.TickDuration.Milliseconds()
+	}
+	for _, p := range w.ProcessesList {
+		if p != nil {
+			res.Nodes = append(res.Nodes, dashboard.Node{
+				Node:   p.Node,
+				Queue:  p.MessagesQueue.Size(),
+				Fields: dashboard.Fields(p.Context),
+			})
+		}
+	}
+	g := w.Network.Links()
+	from := make([]int32, 0, len(g))
+	for n := range g {
+		from = append(from, n)
+	}
+	sort.Slice(from, func(i, j int) bool { return from[i] < from[j] })
+	for _, n := range from {
+		for _, to := range g.Neighbours(n) {
+			res.Links = append(res.Links, dashboard.Link{From: n, To: to, Latency: g[n][to]})
+		}
+	}
+	for _, f := range w.InFlight() {
+		res.Messages = append(res.Messages, dashboard.Message{
+			From:     f.Message.From,
+			To:       f.Message.To,
+			Node:     f.Node,
+			Message:  f.Message.String(),
+			Send:     f.Message.SendTime,
+			Delivery: f.Message.DeliveryTime,
+		})
+	}
+	return res
+}
@@ -0,0 +1,48 @@
+package world
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/dashboard"
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+func TestWorld_Serve(t *testing.T) {
+	received := make(map[int32]int64)
+	w := NewWithOptions(Options{Seed: 1})
+	w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+	line(w, 3, 2)
+	s, err := w.Serve("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("World.Serve() error = %v", err)
+	}
+	defer s.Close()
+	w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_INIT"))))
+	w.Wait(1)
+
+	state := w.dashboardState()
+	if state.Tick != 1 || len(state.Nodes) != 3 || len(state.Links) != 4 {
+		t.Fatalf("World.dashboardState() = %+v, want 3 nodes and 4 links at tick 1", state)
+	}
+	want := dashboard.Message{From: 0, To: 1, Node: 1, Message: "FLOOD_MSG", Send: 0, Delivery: 2}
+	if len(state.Messages) != 1 || state.Messages[0] != want {
+		t.Errorf("World.dashboardState() messages = %v, want %v", state.Messages, []dashboard.Message{want})
+	}
+	if state.Nodes[1].Queue != 1 || state.Nodes[0].Queue != 0 {
+		t.Errorf("World.dashboardState() nodes = %v, want a queued message at 1", state.Nodes)
+	}
+
+	w.Run()
+	w.Stop()
+	resp, err := http.Get("http://" + s.Addr() + "/state")
+	if err != nil {
+		t.Fatalf("GET /state error = %v", err)
+	}
+	defer resp.Body.Close()
+	var got dashboard.State
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || got.Tick != 6 || len(got.Messages) != 0 {
+		t.Errorf("GET /state after World.Stop() = %+v (%v), want the final state at tick 6", got, err)
+	}
+}
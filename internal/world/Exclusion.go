@@ -0,0 +1,107 @@
+package world
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/trmigor/distr-model/internal/metrics"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// Violation is a moment when several processes are in the critical section at once.
+type Violation struct {
+	Tick  int64
+	Nodes []int32
+}
+
+// Exclusion is a tracer checking mutual exclusion by the enter and exit events of the processes.
+// The messages sent by the processes to each other are taken from the metrics, so that the message complexity
+// of an algorithm can be estimated per entry.
+type Exclusion struct {
+	mutex      sync.Mutex
+	inside     map[int32]bool
+	violations []Violation
+	entries    int
+	metrics    *metrics.Collector
+}
+
+// NewExclusion creates a checker without events, counting messages by the collector.
+func NewExclusion(c *metrics.Collector) *Exclusion {
+	return &Exclusion{
+		inside:     make(map[int32]bool),
+		violations: make([]Violation, 0),
+		metrics:    c,
+	}
+}
+
+// Record implements trace.Tracer.
+func (x *Exclusion) Record(e trace.Event) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	switch e.Kind {
+	case trace.Enter:
+		x.inside[e.From] = true
+		x.entries++
+		if len(x.inside) > 1 {
+			x.violate(e.Tick)
+		}
+	case trace.Exit:
+		delete(x.inside, e.From)
+	}
+}
+
+// violate records the processes in the critical section, once for a tick.
+func (x *Exclusion) violate(tick int64) {
+	nodes := make([]int32, 0, len(x.inside))
+	for node := range x.inside {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+	if n := len(x.violations); n > 0 && x.violations[n-1].Tick == tick {
+		x.violations[n-1].Nodes = nodes
+		return
+	}
+	x.violations = append(x.violations, Violation{Tick: tick, Nodes: nodes})
+}
+
+// Violations returns the ticks at which several processes have been in the critical section.
+func (x *Exclusion) Violations() []Violation {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return append([]Violation{}, x.violations...)
+}
+
+// Entries returns the number of entries into the critical section.
+func (x *Exclusion) Entries() int {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return x.entries
+}
+
+// MessagesPerEntry returns the number of messages per entry into the critical section.
+func (x *Exclusion) MessagesPerEntry() float64 {
+	entries := x.Entries()
+	if entries == 0 {
+		return 0
+	}
+	return float64(x.metrics.Sent()) / float64(entries)
+}
+
+// AddTracer passes the network events to one more tracer.
+func (w *World) AddTracer(t trace.Tracer) {
+	switch old := w.Network.Tracer.(type) {
+	case nil:
+		w.Network.Tracer = t
+	case trace.Multi:
+		w.Network.Tracer = append(old, t)
+	default:
+		w.Network.Tracer = trace.Multi{old, t}
+	}
+}
+
+// CheckExclusion starts checking mutual exclusion of the critical section by a new tracer.
+func (w *World) CheckExclusion() *Exclusion {
+	x := NewExclusion(w.Network.Metrics())
+	w.AddTracer(x)
+	return x
+}
@@ -0,0 +1,54 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/metrics"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+func TestExclusion(t *testing.T) {
+	c := metrics.NewCollector()
+	x := NewExclusion(c)
+	events := []trace.Event{
+		{Tick: 0, Kind: trace.Send, From: -1, To: 0},
+		{Tick: 0, Kind: trace.Send, From: 0, To: 1},
+		{Tick: 1, Kind: trace.Enter, From: 1, To: 1},
+		{Tick: 1, Kind: trace.Send, From: 1, To: 1},
+		{Tick: 2, Kind: trace.Exit, From: 1, To: 1},
+		{Tick: 2, Kind: trace.Enter, From: 0, To: 0},
+		{Tick: 3, Kind: trace.Enter, From: 2, To: 2},
+		{Tick: 3, Kind: trace.Enter, From: 1, To: 1},
+		{Tick: 4, Kind: trace.Exit, From: 0, To: 0},
+		{Tick: 4, Kind: trace.Send, From: 2, To: 0},
+	}
+	for _, e := range events {
+		trace.Multi{c, x}.Record(e)
+	}
+	if got := x.Entries(); got != 4 {
+		t.Errorf("Exclusion.Entries() = %v, want 4", got)
+	}
+	if got := x.MessagesPerEntry(); got != 0.5 {
+		t.Errorf("Exclusion.MessagesPerEntry() = %v, want 0.5", got)
+	}
+	want := []Violation{{Tick: 3, Nodes: []int32{0, 1, 2}}}
+	if got := x.Violations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Exclusion.Violations() = %v, want %v", got, want)
+	}
+}
+
+func TestWorld_AddTracer(t *testing.T) {
+	w := NewWithOptions(Options{})
+	defer w.Stop()
+	a, b, c := trace.NewRecorder(), trace.NewRecorder(), trace.NewRecorder()
+	w.AddTracer(a)
+	w.AddTracer(b)
+	w.AddTracer(c)
+	w.Network.Record(trace.Event{Kind: trace.Enter})
+	for i, r := range []*trace.Recorder{a, b, c} {
+		if len(r.Events()) != 1 {
+			t.Errorf("World.AddTracer(): tracer %v got %v events, want 1", i, len(r.Events()))
+		}
+	}
+}
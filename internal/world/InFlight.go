@@ -0,0 +1,91 @@
+package world
+
+import (
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// InFlight is a message not yet handled with the node of the process it is queued at:
+// the receiver or, for routed messages, the next hop.
+type InFlight struct {
+	Node    int32
+	Message *messages.Message
+}
+
+// InFlight returns the messages not yet handled in order of nodes and delivery times.
+func (w *World) InFlight() []InFlight {
+	res := make([]InFlight, 0)
+	for _, p := range w.ProcessesList {
+		if p == nil {
+			continue
+		}
+		for _, m := range p.MessagesQueue.Messages() {
+			res = append(res, InFlight{Node: p.Node, Message: m})
+		}
+	}
+	return res
+}
+
+// Deliver handles the message by the process it is queued at regardless of its delivery time,
+// as if the messages were delayed arbitrarily, the time does not move. It returns false if there is no such message.
+// Deliver should be used only in virtual time.
+func (w *World) Deliver(f InFlight) bool {
+	p := w.process(f.Node)
+	if p == nil || !p.MessagesQueue.Remove(f.Message) {
+		return false
+	}
+	p.Handle(f.Message)
+	w.checkpoint()
+	return true
+}
+
+// Lose drops the message queued at the process as lost by the network.
+// It returns false if there is no such message.
+func (w *World) Lose(f InFlight) bool {
+	return w.drop(f, trace.ReasonLoss)
+}
+
+// Drop drops the message queued at the process as dropped by the user.
+// It returns false if there is no such message.
+func (w *World) Drop(f InFlight) bool {
+	return w.drop(f, trace.ReasonUser)
+}
+
+func (w *World) drop(f InFlight, reason string) bool {
+	p := w.process(f.Node)
+	if p == nil || !p.MessagesQueue.Remove(f.Message) {
+		return false
+	}
+	w.Network.RecordMessage(trace.Drop, f.Message, reason)
+	w.checkpoint()
+	return true
+}
+
+// Delay postpones the delivery of the message queued at the process by the number of ticks.
+// It returns false if there is no such message.
+func (w *World) Delay(f InFlight, ticks int64) bool {
+	p := w.process(f.Node)
+	if p == nil || !p.MessagesQueue.Remove(f.Message) {
+		return false
+	}
+	f.Message.DeliveryTime += ticks
+	p.MessagesQueue.Enqueue(f.Message)
+	return true
+}
+
+// BeforeStep sets the function called before every message handled in virtual time with the node
+// of the process it is queued at. The function may drop or delay pending messages, then it is called
+// again for the message to handle instead, if any. The dashboard, if served, shows the state
+// the function is called in. A nil function removes it.
+func (w *World) BeforeStep(hook func(node int32, m *messages.Message)) {
+	w.beforeStep = hook
+}
+
+// process returns the process of the node, nil if there is none.
+func (w *World) process(node int32) *process.Process {
+	if node < 0 || int(node) >= len(w.ProcessesList) {
+		return nil
+	}
+	return w.ProcessesList[node]
+}
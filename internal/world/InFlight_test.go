@@ -0,0 +1,94 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+func TestWorld_InFlight(t *testing.T) {
+	received := make(map[int32]int64)
+	w := NewWithOptions(Options{Seed: 1})
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+	line(w, 3, 2)
+	r := trace.NewRecorder()
+	w.AddTracer(r)
+	w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_INIT"))))
+
+	f := w.InFlight()
+	if len(f) != 1 || f[0].Node != 0 || f[0].Message.From != -1 {
+		t.Fatalf("World.InFlight() = %v, want the initial message at 0", f)
+	}
+	if !w.Deliver(f[0]) {
+		t.Fatalf("World.Deliver() = false, want true")
+	}
+	if w.Deliver(f[0]) {
+		t.Errorf("World.Deliver() of a handled message = true, want false")
+	}
+	f = w.InFlight()
+	if len(f) != 1 || f[0].Node != 1 || f[0].Message.From != 0 {
+		t.Fatalf("World.InFlight() = %v, want the message from 0 at 1", f)
+	}
+	if !w.Lose(f[0]) {
+		t.Fatalf("World.Lose() = false, want true")
+	}
+	if f = w.InFlight(); len(f) != 0 {
+		t.Errorf("World.InFlight() = %v, want none", f)
+	}
+	drops := make([]trace.Event, 0)
+	for _, e := range r.Events() {
+		if e.Kind == trace.Drop {
+			drops = append(drops, e)
+		}
+	}
+	if len(drops) != 1 || drops[0].Reason != trace.ReasonLoss || drops[0].To != 1 {
+		t.Errorf("World.Lose() recorded %v, want a loss of the message to 1", drops)
+	}
+	if _, ok := received[1]; ok || w.Network.Tick != 0 {
+		t.Errorf("World.Lose() delivered the message or moved the time to %v", w.Network.Tick)
+	}
+}
+
+func TestWorld_BeforeStep(t *testing.T) {
+	received := make(map[int32]int64)
+	w := NewWithOptions(Options{Seed: 1})
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+	line(w, 4, 2)
+	r := trace.NewRecorder()
+	w.AddTracer(r)
+	hooked := make([]int32, 0)
+	w.BeforeStep(func(node int32, m *messages.Message) {
+		hooked = append(hooked, node)
+		switch node {
+		case 1:
+			if m.DeliveryTime == 2 && !w.Delay(InFlight{Node: node, Message: m}, 3) {
+				t.Errorf("World.Delay() = false, want true")
+			}
+		case 3:
+			if !w.Drop(InFlight{Node: node, Message: m}) {
+				t.Errorf("World.Drop() = false, want true")
+			}
+		}
+	})
+	w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_INIT"))))
+	w.Wait(20)
+
+	if received[1] != 5 || received[2] != 7 {
+		t.Errorf("World.Delay() received = %v, want 1 at 5 and 2 at 7", received)
+	}
+	if _, ok := received[3]; ok {
+		t.Errorf("World.Drop() delivered the message to 3")
+	}
+	want := []int32{0, 1, 1, 0, 2, 1, 3}
+	if !reflect.DeepEqual(hooked, want) {
+		t.Errorf("World.BeforeStep() called for %v, want %v", hooked, want)
+	}
+	last := r.Events()[len(r.Events())-1]
+	if last.Kind != trace.Drop || last.Reason != trace.ReasonUser {
+		t.Errorf("World.Drop() recorded %v, want a drop by the user", last)
+	}
+}
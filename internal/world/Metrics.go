@@ -0,0 +1,22 @@
+package world
+
+import (
+	"github.com/trmigor/distr-model/internal/metrics"
+)
+
+// Metrics returns the metrics of the run counted by the network completed by the numbers of unhandled messages
+// and the greatest queue lengths of the processes.
+func (w *World) Metrics() *metrics.Metrics {
+	m := w.Network.Metrics().Metrics()
+	m.Ticks = w.Network.Tick
+	for _, dp := range w.ProcessesList {
+		if dp == nil {
+			continue
+		}
+		p := m.Process(dp.Node)
+		p.Unhandled = dp.Unhandled()
+		p.MaxQueue = dp.MessagesQueue.MaxSize()
+		m.Unhandled += p.Unhandled
+	}
+	return m
+}
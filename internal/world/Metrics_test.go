@@ -0,0 +1,57 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+)
+
+func TestWorld_Metrics(t *testing.T) {
+	received := make(map[int32]int64)
+	w := NewWithOptions(Options{Seed: 1})
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+	line(w, 3, 2)
+
+	w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_START"))))
+	w.Network.SendMessage(-1, 2, messages.NewMessageByArgs(messages.NewMessageArg([]byte("OTHER"))))
+	w.Network.SendMessage(-1, 7, messages.NewMessageByArgs(messages.NewMessageArg([]byte("OTHER"))))
+	w.Wait(10)
+
+	m := w.Metrics()
+	totals := []struct {
+		name      string
+		got, want int64
+	}{
+		{"Sent", m.Sent, 4},
+		{"Injected", m.Injected, 2},
+		{"Local", m.Local, 0},
+		{"Delivered", m.Delivered, 6},
+		{"Drops", m.Drops(), 1},
+		{"Unhandled", m.Unhandled, 1},
+		{"LastActivity", m.LastActivity, 6},
+		{"Ticks", m.Ticks, 10},
+	}
+	for _, tt := range totals {
+		if tt.got != tt.want {
+			t.Errorf("World.Metrics().%v = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if len(m.Processes) != 3 || m.Processes[1].Sent != 2 || m.Processes[2].Unhandled != 1 || m.Processes[0].MaxQueue != 1 {
+		t.Errorf("World.Metrics().Processes = %+v", m.Processes)
+	}
+	var bytes int64
+	ends := [][2]int32{{0, 1}, {1, 0}, {1, 2}, {2, 1}}
+	if len(m.Links) != len(ends) {
+		t.Fatalf("World.Metrics().Links = %+v, want %v links", m.Links, len(ends))
+	}
+	for i, l := range m.Links {
+		if l.From != ends[i][0] || l.To != ends[i][1] || l.Messages != 1 {
+			t.Errorf("World.Metrics().Links[%v] = %+v, want one message over %v", i, l, ends[i])
+		}
+		bytes += l.Bytes
+	}
+	if bytes != m.Bytes {
+		t.Errorf("World.Metrics(): links carried %v bytes, want %v", bytes, m.Bytes)
+	}
+}
@@ -0,0 +1,45 @@
+package world
+
+import (
+	"github.com/trmigor/distr-model/internal/property"
+)
+
+// AddProperty starts checking the global property in the states of the run: after every handled message
+// in virtual time and every tick in real time. Eventual properties which have not held by the end of the run
+// are violated when the world stops.
+func (w *World) AddProperty(p property.Property) {
+	if w.properties == nil {
+		w.properties = property.NewChecker()
+		w.AddTracer(w.properties)
+	}
+	w.properties.Add(p)
+}
+
+// Invariant starts checking that the predicate holds in every state of the run.
+func (w *World) Invariant(name string, p property.Predicate) {
+	w.AddProperty(property.Property{Name: name, Kind: property.Invariant, Predicate: p})
+}
+
+// Eventually starts checking that the predicate holds in some state not later than the deadline,
+// 0 means the end of the run.
+func (w *World) Eventually(name string, deadline int64, p property.Predicate) {
+	w.AddProperty(property.Property{Name: name, Kind: property.Eventually, Deadline: deadline, Predicate: p})
+}
+
+// Properties returns the outcomes of the properties with the counterexamples of the violated ones, if any.
+func (w *World) Properties() []property.Result {
+	if w.properties == nil {
+		return nil
+	}
+	return w.properties.Results()
+}
+
+// checkProperties evaluates the properties in the current state.
+// Processes running in real time do not handle messages meanwhile.
+func (w *World) checkProperties() {
+	if w.properties != nil {
+		w.Network.Inspect(func() {
+			w.properties.Check(w.Network.Tick, w.ProcessesList)
+		})
+	}
+}
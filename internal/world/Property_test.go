@@ -0,0 +1,84 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/property"
+)
+
+type flooded struct {
+	Got  bool
+	Tick int64
+}
+
+func TestWorld_Properties(t *testing.T) {
+	w := NewWithOptions(Options{})
+	w.RegisterWorkFunction([]byte("FLOOD"), func(dp *process.Process, m *messages.Message) bool {
+		m.Ptr = 0
+		if !dp.IsMyMessage([]byte("FLOOD"), m.GetString()) {
+			return false
+		}
+		c := process.Ctx[*flooded](dp, "Flood")
+		if c.Got {
+			return true
+		}
+		c.Got, c.Tick = true, dp.Network.Tick
+		for _, v := range dp.Neighbours() {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_MSG"))))
+		}
+		return true
+	})
+	line(w, 4, 2)
+	for _, p := range w.ProcessesList {
+		p.Context["Flood"] = &flooded{}
+	}
+	if w.Properties() != nil {
+		t.Errorf("World.Properties() = %v, want nil", w.Properties())
+	}
+	for _, p := range []struct {
+		kind      property.Kind
+		predicate string
+		deadline  int64
+	}{
+		{property.Invariant, "atmost 2 Flood.Got", 0},
+		{property.Eventually, "all Flood.Got", 0},
+		{property.Eventually, "all Flood.Got", 5},
+		{property.Eventually, "all Flood.Tick > 6", 0},
+	} {
+		predicate, err := property.Parse(p.predicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.kind == property.Invariant {
+			w.Invariant(p.predicate, predicate)
+		} else {
+			w.Eventually(p.predicate, p.deadline, predicate)
+		}
+	}
+	w.Invariant("before tick 100", func(ps []*process.Process) bool {
+		return w.Network.Tick < 100
+	})
+
+	w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_INIT"))))
+	w.Wait(20)
+	w.Stop()
+
+	want := []struct {
+		ok   bool
+		tick int64
+	}{{false, 4}, {true, 6}, {false, 5}, {false, 20}, {true, -1}}
+	got := w.Properties()
+	if len(got) != len(want) {
+		t.Fatalf("World.Properties() = %v, want %v results", got, len(want))
+	}
+	for i, r := range got {
+		if r.OK != want[i].ok || r.Tick != want[i].tick {
+			t.Errorf("World.Properties()[%v] = %v, want %+v", i, r, want[i])
+		}
+	}
+	if events := got[0].Events; len(events) == 0 || events[len(events)-1].Tick != 4 {
+		t.Errorf("World.Properties()[0].Events = %v, want the events up to tick 4", events)
+	}
+}
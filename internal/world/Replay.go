@@ -0,0 +1,67 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/trmigor/distr-model/internal/replay"
+)
+
+// Record starts recording the run: the values drawn from the network random generator, the order of handled
+// messages and the timer messages. It should be called before the model is launched.
+// In real time messages are handled one at a time while recorded.
+func (w *World) Record() *replay.Recorder {
+	r := replay.NewRecorder(w.Network.Rng)
+	w.Network.Rng = rand.New(r)
+	w.Network.Observe(r)
+	return r
+}
+
+// Replay makes the world perform the recorded run: the network draws the recorded values, processes handle
+// the recorded messages in the recorded order at the recorded ticks and timer messages are sent at the recorded
+// ticks instead of their periods. Messages not recorded are not handled. It should be called before the model
+// is launched, the model should be launched as in the recorded run. Replays are performed in virtual time.
+func (w *World) Replay(l *replay.Log) error {
+	if !w.Network.IsVirtual() {
+		return fmt.Errorf("replays are performed in virtual time")
+	}
+	w.player = replay.NewPlayer(l)
+	w.Network.Rng = rand.New(w.player)
+	return nil
+}
+
+// Diverged reports how the replay, if any, has diverged from the recorded run. It returns nil if it has not.
+func (w *World) Diverged() error {
+	if w.player == nil {
+		return nil
+	}
+	return w.player.Err()
+}
+
+// replayStep handles the next recorded message, if it is due at the current tick and queued at its process.
+func (w *World) replayStep() bool {
+	d, ok := w.player.Delivery()
+	if !ok || d.Tick > w.Network.Tick {
+		return false
+	}
+	p := w.process(d.Node)
+	if p == nil {
+		return false
+	}
+	ms := p.MessagesQueue.Messages()
+	i := w.player.Find(ms)
+	if i < 0 || !p.MessagesQueue.Remove(ms[i]) {
+		return false
+	}
+	w.player.Delivered()
+	p.Handle(ms[i])
+	w.checkpoint()
+	return true
+}
+
+// replayTimers sends the recorded timer messages due at the current tick.
+func (w *World) replayTimers() {
+	for t, ok := w.player.Timer(w.Network.Tick); ok; t, ok = w.player.Timer(w.Network.Tick) {
+		w.Network.SendTimer(t.Current)
+	}
+}
@@ -0,0 +1,87 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/replay"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// pings sends PING messages to all neighbours on every timer message, counting received ones.
+func pings(received map[int32]int) process.WorkFunction {
+	return func(dp *process.Process, m *messages.Message) bool {
+		m.Ptr = 0
+		switch string(m.GetString()) {
+		case "*TIME":
+			for _, v := range dp.Neighbours() {
+				dp.Network.SendMessage(dp.Node, v, messages.NewMessageByArgs(messages.NewMessageArg([]byte("PING"))))
+			}
+		case "PING":
+			received[dp.Node]++
+		default:
+			return false
+		}
+		return true
+	}
+}
+
+// pinging runs pings on a line of processes with lossy links, returning its events and received messages.
+func pinging(w *World) ([]trace.Event, map[int32]int) {
+	received := make(map[int32]int)
+	r := trace.NewRecorder()
+	w.AddTracer(r)
+	w.RegisterWorkFunction([]byte("*TIME"), pings(received))
+	w.RegisterWorkFunction([]byte("PING"), pings(received))
+	for i := int32(0); i < 4; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte("*TIME"))
+		w.AssignWorkFunction(i, []byte("PING"))
+	}
+	for i := int32(0); i < 3; i++ {
+		w.Network.CreateLink(i, i+1, true, 2)
+	}
+	w.Network.SetErrorRate(0.5)
+	w.LaunchTimer(3)
+	w.Wait(20)
+	w.Stop()
+	return r.Events(), received
+}
+
+func TestWorld_Replay(t *testing.T) {
+	w := NewWithOptions(Options{Seed: 7})
+	recorder := w.Record()
+	events, received := pinging(w)
+	l := recorder.Log()
+	if len(l.Draws) == 0 || len(l.Deliveries) == 0 || len(l.Timers) != 7 {
+		t.Fatalf("World.Record() = %v draws, %v deliveries, %v timers", len(l.Draws), len(l.Deliveries), len(l.Timers))
+	}
+
+	w = NewWithOptions(Options{Seed: 8})
+	if err := w.Replay(l); err != nil {
+		t.Fatalf("World.Replay() error = %v", err)
+	}
+	replayed, got := pinging(w)
+	if err := w.Diverged(); err != nil {
+		t.Errorf("World.Diverged() = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(replayed, events) || !reflect.DeepEqual(got, received) {
+		t.Errorf("World.Replay() received %v, want %v", got, received)
+	}
+
+	l.Deliveries[len(l.Deliveries)/2].From = 3
+	w = NewWithOptions(Options{Seed: 7})
+	w.Replay(l)
+	pinging(w)
+	if w.Diverged() == nil {
+		t.Errorf("World.Diverged() = nil, want the missing delivery")
+	}
+
+	w = New()
+	defer w.Stop()
+	if w.Replay(&replay.Log{}) == nil {
+		t.Errorf("World.Replay() in real time error = nil")
+	}
+}
@@ -0,0 +1,50 @@
+package world
+
+import (
+	"sort"
+
+	"github.com/trmigor/distr-model/internal/network"
+	"github.com/trmigor/distr-model/internal/routing"
+)
+
+// SetRouting makes the network forward messages to processes without links from their senders
+// by the router with the name, "none" switches routing off.
+// The "tables" router uses the routing tables kept in the process contexts by routing protocols.
+func (w *World) SetRouting(name string) error {
+	if name == "none" {
+		w.Network.Router = nil
+		return nil
+	}
+	r, err := network.NewRouter(name, w.Network, w.table)
+	if err != nil {
+		return err
+	}
+	w.Network.Router = r
+	return nil
+}
+
+// CheckRouting starts keeping the routing tables built by routing protocols by a new tracer.
+func (w *World) CheckRouting() *routing.Checker {
+	c := routing.NewChecker()
+	w.AddTracer(c)
+	return c
+}
+
+// table returns the routing table of the process: its first context implementing network.Table by key order.
+func (w *World) table(node int32) network.Table {
+	if node < 0 || node >= int32(len(w.ProcessesList)) || w.ProcessesList[node] == nil {
+		return nil
+	}
+	dp := w.ProcessesList[node]
+	keys := make([]string, 0, len(dp.Context))
+	for key := range dp.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if t, ok := dp.Context[key].(network.Table); ok {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package world
+
+import (
+	"time"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/network"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+// timer sends timer messages in virtual time.
+type timer struct {
+	period  int64
+	next    int64
+	current int32
+}
+
+// LaunchTimer starts sending timer messages to all processes every period ticks.
+func (w *World) LaunchTimer(period int) {
+	if !w.Network.IsVirtual() {
+		go network.TimerSender(w.Network, period)
+		return
+	}
+	t := &timer{period: int64(period), next: w.Network.Tick}
+	w.timers = append(w.timers, t)
+	w.fireTimers()
+}
+
+func (w *World) fireTimers() {
+	if w.player != nil {
+		w.replayTimers()
+		return
+	}
+	for _, t := range w.timers {
+		for t.next <= w.Network.Tick {
+			w.Network.SendTimer(t.current)
+			t.current++
+			t.next += t.period
+		}
+	}
+}
+
+// remaining truncates the number of ticks according to the time limit.
+func (w *World) remaining(ticks int64) int64 {
+	if w.MaxTicks > 0 && w.Network.Tick+ticks > w.MaxTicks {
+		ticks = w.MaxTicks - w.Network.Tick
+	}
+	if ticks < 0 {
+		return 0
+	}
+	return ticks
+}
+
+// Expired reports whether the time limit is reached.
+func (w *World) Expired() bool {
+	return w.MaxTicks > 0 && w.Network.Tick >= w.MaxTicks
+}
+
+// next returns the process with the earliest pending message and the message itself.
+// Messages with the same delivery time are taken in order of process nodes.
+func (w *World) next() (*process.Process, *messages.Message) {
+	var res *process.Process
+	var first *messages.Message
+	for _, p := range w.ProcessesList {
+		if p == nil || p.MessagesQueue.Size() == 0 {
+			continue
+		}
+		m := p.MessagesQueue.Peek()
+		if first == nil || m.DeliveryTime < first.DeliveryTime {
+			res, first = p, m
+		}
+	}
+	return res, first
+}
+
+// Step handles the earliest pending message, if it is due at the current tick, or the next recorded one in replays.
+// It returns false if there is no such message.
+// Step should be used only in virtual time.
+func (w *World) Step() bool {
+	if w.player != nil {
+		return w.replayStep()
+	}
+	p, m := w.next()
+	if m == nil || m.DeliveryTime > w.Network.Tick {
+		return false
+	}
+	for w.beforeStep != nil {
+		w.publish(true)
+		w.beforeStep(p.Node, m)
+		q, n := w.next()
+		if n == nil || n.DeliveryTime > w.Network.Tick {
+			return false
+		}
+		if n == m {
+			break
+		}
+		p, m = q, n
+	}
+	p.Handle(p.MessagesQueue.Dequeue())
+	w.checkpoint()
+	return true
+}
+
+// Wait performs the model for the given number of ticks.
+// In virtual time all the messages due at the final tick are handled as well.
+func (w *World) Wait(ticks int64) {
+	ticks = w.remaining(ticks)
+	w.checkpoint()
+	if !w.Network.IsVirtual() {
+		if w.properties == nil && w.dashboard == nil {
+			time.Sleep(time.Duration(ticks) * w.Network.TickDuration)
+			return
+		}
+		// The checks are performed every tick and more often while the dashboard is served.
+		nap := w.Network.TickDuration
+		if w.dashboard != nil && nap > dashboardPeriod {
+			nap = dashboardPeriod
+		}
+		end := time.Now().Add(time.Duration(ticks) * w.Network.TickDuration)
+		for left := time.Until(end); left > 0; left = time.Until(end) {
+			if left < nap {
+				nap = left
+			}
+			time.Sleep(nap)
+			w.checkpoint()
+		}
+		return
+	}
+
+	end := w.Network.Tick + ticks
+	for {
+		for w.Step() {
+		}
+		if w.Network.Tick >= end {
+			return
+		}
+		w.Network.Tick = w.nextTick(end)
+		w.checkpoint()
+		w.fireTimers()
+	}
+}
+
+// nextTick returns the tick of the next event, recorded one in replays, but not later than end.
+func (w *World) nextTick(end int64) int64 {
+	res := end
+	if w.player != nil {
+		if t, ok := w.player.Next(); ok && t < res {
+			res = t
+		}
+	} else {
+		if _, m := w.next(); m != nil && m.DeliveryTime < res {
+			res = m.DeliveryTime
+		}
+		for _, t := range w.timers {
+			if t.next < res {
+				res = t.next
+			}
+		}
+	}
+	if res <= w.Network.Tick {
+		res = w.Network.Tick + 1
+	}
+	return res
+}
+
+// Pending returns the number of messages not yet handled by processes.
+func (w *World) Pending() int {
+	res := 0
+	for _, p := range w.ProcessesList {
+		if p != nil {
+			res += p.MessagesQueue.Size()
+		}
+	}
+	return res
+}
+
+// Run performs the model until there are no pending messages or the time limit is reached.
+// Timer messages are not taken into account.
+// It returns false if the time limit is reached with pending messages.
+func (w *World) Run() bool {
+	for w.Pending() > 0 {
+		if w.Expired() {
+			return false
+		}
+		if !w.Network.IsVirtual() {
+			time.Sleep(w.Network.TickDuration / 10)
+			w.publish(false)
+			continue
+		}
+		if w.player != nil {
+			// Messages not recorded are not handled, recorded ones missing mean the replay has diverged.
+			t, ok := w.player.Next()
+			if !ok || t <= w.Network.Tick && !w.Step() {
+				return false
+			}
+			w.Wait(t - w.Network.Tick)
+			continue
+		}
+		_, m := w.next()
+		w.Wait(m.DeliveryTime - w.Network.Tick)
+	}
+	return true
+}
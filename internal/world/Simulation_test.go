@@ -0,0 +1,132 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/trace"
+)
+
+// flood forwards FLOOD messages to all neighbours once, remembering the tick of the first receipt.
+func flood(received map[int32]int64) process.WorkFunction {
+	return func(dp *process.Process, m *messages.Message) bool {
+		m.Ptr = 0
+		if !dp.IsMyMessage([]byte("FLOOD"), m.GetString()) {
+			return false
+		}
+		if _, ok := received[dp.Node]; ok {
+			return true
+		}
+		received[dp.Node] = dp.Network.Tick
+		for _, v := range dp.Neighbours() {
+			dp.Network.SendMessage(dp.Node, v, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_MSG"))))
+		}
+		return true
+	}
+}
+
+func line(w *World, n int32, latency int32) {
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte("FLOOD"))
+	}
+	for i := int32(0); i+1 < n; i++ {
+		w.Network.CreateLink(i, i+1, true, latency)
+	}
+}
+
+func TestWorld_Wait(t *testing.T) {
+	received := make(map[int32]int64)
+	w := NewWithOptions(Options{Seed: 1})
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+	line(w, 5, 2)
+
+	w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_START"))))
+	w.Wait(5)
+	if w.Network.Tick != 5 {
+		t.Errorf("World.Wait(): tick = %v, want 5", w.Network.Tick)
+	}
+	if len(received) != 3 {
+		t.Errorf("World.Wait(): %v processes reached, want 3", len(received))
+	}
+	w.Wait(10)
+	for i := int32(0); i < 5; i++ {
+		if received[i] != int64(2*i) {
+			t.Errorf("World.Wait(): process %v reached at %v, want %v", i, received[i], 2*i)
+		}
+	}
+}
+
+func TestWorld_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxTicks int64
+		want     bool
+		wantTick int64
+	}{
+		{"Unlimited", 0, true, 10},
+		{"Limited", 5, false, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := make(map[int32]int64)
+			w := NewWithOptions(Options{MaxTicks: tt.maxTicks})
+			defer w.Stop()
+			w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+			line(w, 5, 2)
+			w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_START"))))
+			if got := w.Run(); got != tt.want {
+				t.Errorf("World.Run() = %v, want %v", got, tt.want)
+			}
+			if w.Network.Tick != tt.wantTick {
+				t.Errorf("World.Run(): tick = %v, want %v", w.Network.Tick, tt.wantTick)
+			}
+		})
+	}
+}
+
+func TestWorld_LaunchTimer(t *testing.T) {
+	w := NewWithOptions(Options{})
+	defer w.Stop()
+	ticks := make([]int64, 0)
+	w.RegisterWorkFunction([]byte("TIME"), func(dp *process.Process, m *messages.Message) bool {
+		ticks = append(ticks, dp.Network.Tick)
+		return true
+	})
+	w.CreateProcess(0)
+	w.AssignWorkFunction(0, []byte("TIME"))
+	w.LaunchTimer(3)
+	w.Wait(7)
+	if want := []int64{0, 3, 6}; len(ticks) != len(want) || ticks[0] != want[0] || ticks[1] != want[1] || ticks[2] != want[2] {
+		t.Errorf("World.LaunchTimer(): timer messages at %v, want %v", ticks, want)
+	}
+}
+
+func TestWorld_Determinism(t *testing.T) {
+	run := func() []trace.Event {
+		received := make(map[int32]int64)
+		w := NewWithOptions(Options{Seed: 42})
+		defer w.Stop()
+		rec := trace.NewRecorder()
+		w.Network.Tracer = rec
+		w.RegisterWorkFunction([]byte("FLOOD"), flood(received))
+		line(w, 6, 1)
+		w.Network.AddLinksAllToAll(true, 1)
+		w.Network.SetErrorRate(0.3)
+		w.Network.SendMessage(-1, 0, messages.NewMessageByArgs(messages.NewMessageArg([]byte("FLOOD_START"))))
+		w.Run()
+		return rec.Events()
+	}
+	first, second := run(), run()
+	if len(first) != len(second) {
+		t.Fatalf("runs with the same seed differ: %v and %v events", len(first), len(second))
+	}
+	for i := range first {
+		if !reflect.DeepEqual(first[i], second[i]) {
+			t.Fatalf("runs with the same seed differ at event %v: %v and %v", i, first[i], second[i])
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package world
+
+import (
+	"github.com/trmigor/distr-model/internal/errors"
+	"github.com/trmigor/distr-model/internal/snapshot"
+)
+
+// EnableSnapshots makes all the processes, including the ones created later, take part in Chandy–Lamport snapshots.
+// The network is switched to FIFO mode required by the algorithm.
+func (w *World) EnableSnapshots() {
+	if w.snapshots != nil {
+		return
+	}
+	w.snapshots = snapshot.NewCollector()
+	w.Network.FIFO = true
+	for _, p := range w.ProcessesList {
+		if p != nil {
+			w.snapshots.Attach(p)
+		}
+	}
+}
+
+// Snapshot starts a global snapshot at the process with given node, enabling snapshots if needed.
+// Processes can start snapshots themselves by sending *SNAPSHOT message to themselves.
+func (w *World) Snapshot(node int32) errors.ErrorCode {
+	w.EnableSnapshots()
+	return w.Network.SendMessage(-1, node, snapshot.InitiateMessage())
+}
+
+// Snapshots returns the global snapshots started so far.
+func (w *World) Snapshots() []*snapshot.Global {
+	if w.snapshots == nil {
+		return nil
+	}
+	return w.snapshots.Snapshots()
+}
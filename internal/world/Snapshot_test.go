@@ -0,0 +1,77 @@
+package world
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+)
+
+type account struct {
+	Money int32
+}
+
+// bank passes half of the money to the next process on every BANK_MONEY message until its hops are exhausted.
+func bank(dp *process.Process, m *messages.Message) bool {
+	m.Ptr = 0
+	if !dp.IsMyMessage([]byte("BANK"), m.GetString()) {
+		return false
+	}
+	amount, hops := m.GetInt32(), m.GetInt32()
+	a := dp.Context["Account"].(*account)
+	a.Money += amount
+	if hops > 0 {
+		half := a.Money / 2
+		a.Money -= half
+		next := (dp.Node + 1) % int32(len(dp.Network.QueueMap))
+		dp.Network.SendMessage(dp.Node, next, messages.NewMessageByArgs(
+			messages.NewMessageArg([]byte("BANK_MONEY")), messages.NewMessageArg(half), messages.NewMessageArg(hops-1)))
+	}
+	return true
+}
+
+func TestWorld_Snapshot(t *testing.T) {
+	const n, initial = 5, 100
+	for _, tick := range []int64{0, 3, 7, 12} {
+		t.Run(fmt.Sprint(tick), func(t *testing.T) {
+			w := NewWithOptions(Options{Seed: tick})
+			defer w.Stop()
+			w.RegisterWorkFunction([]byte("BANK"), bank)
+			for i := int32(0); i < n; i++ {
+				w.CreateProcess(i)
+				w.ProcessesList[i].Context["Account"] = &account{Money: initial}
+				w.AssignWorkFunction(i, []byte("BANK"))
+			}
+			w.Network.AddLinksAllToAll(true, 2)
+			for i := int32(0); i < n; i++ {
+				w.Network.SendMessage(-1, i, messages.NewMessageByArgs(
+					messages.NewMessageArg([]byte("BANK_MONEY")), messages.NewMessageArg(int32(0)), messages.NewMessageArg(int32(10))))
+			}
+			w.Wait(tick)
+			w.Snapshot(int32(tick % n))
+			w.Run()
+
+			snapshots := w.Snapshots()
+			if len(snapshots) != 1 || !snapshots[0].Complete {
+				t.Fatalf("World.Snapshots() = %+v, want one complete snapshot", snapshots)
+			}
+			var total int32
+			for _, l := range snapshots[0].Processes {
+				total += l.Context["Account"].(*account).Money
+				for _, ms := range l.Channels {
+					for _, s := range ms {
+						var amount, hops int32
+						if _, err := fmt.Sscanf(s, "BANK_MONEY %d %d", &amount, &hops); err != nil {
+							t.Fatalf("unexpected message %q in channel: %v", s, err)
+						}
+						total += amount
+					}
+				}
+			}
+			if total != n*initial {
+				t.Errorf("World.Snapshot(): recorded total = %v, want %v", total, n*initial)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package world
+
+import (
+	"github.com/trmigor/distr-model/internal/termination"
+)
+
+// DetectTermination layers the termination detection algorithm over the working functions
+// of all the processes, including the ones created later.
+func (w *World) DetectTermination(algorithm string) error {
+	d, err := termination.New(algorithm)
+	if err != nil {
+		return err
+	}
+	w.termination = d
+	for _, p := range w.ProcessesList {
+		if p != nil {
+			d.Attach(p)
+		}
+	}
+	return nil
+}
+
+// Termination returns the comparison of the detected termination with the true one
+// and false if termination detection is not enabled.
+func (w *World) Termination() (termination.Report, bool) {
+	if w.termination == nil {
+		return termination.Report{}, false
+	}
+	return w.termination.Report(), true
+}
@@ -0,0 +1,90 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/termination"
+)
+
+func spread(hops int32) *messages.Message {
+	return messages.NewMessageByArgs(messages.NewMessageArg([]byte("SPREAD_MSG")), messages.NewMessageArg(hops))
+}
+
+// diffusing creates a world of n processes on a random connected graph with a ring,
+// in which every message of the computation is passed on to some of the neighbours until its hops are exhausted.
+func diffusing(n int32, rng *rand.Rand) *World {
+	w := NewWithOptions(Options{Seed: rng.Int63(), MaxTicks: 100000})
+	w.RegisterWorkFunction([]byte("SPREAD"), func(dp *process.Process, m *messages.Message) bool {
+		m.Ptr = 0
+		if !dp.IsMyMessage([]byte("SPREAD"), m.GetString()) {
+			return false
+		}
+		hops := m.GetInt32()
+		if hops == 0 {
+			return true
+		}
+		for i, v := range dp.Neighbours() {
+			if (int32(i)+hops+dp.Node)%3 != 0 {
+				dp.Network.SendMessage(dp.Node, v, spread(hops-1))
+			}
+		}
+		return true
+	})
+	for i := int32(0); i < n; i++ {
+		w.CreateProcess(i)
+		w.AssignWorkFunction(i, []byte("SPREAD"))
+	}
+	for i := int32(0); i < n; i++ {
+		if n > 1 {
+			w.Network.CreateLink(i, (i+1)%n, true, 1+rng.Int31n(9))
+		}
+		if j := rng.Int31n(n); j != i && rng.Intn(2) == 0 {
+			w.Network.CreateLink(i, j, true, 1+rng.Int31n(9))
+		}
+	}
+	return w
+}
+
+func TestWorld_DetectTermination(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, algorithm := range termination.Algorithms() {
+		for _, n := range []int32{1, 2, 5, 12} {
+			for _, starts := range []int{1, 3} {
+				t.Run(fmt.Sprintf("%v/%v/%v", algorithm, n, starts), func(t *testing.T) {
+					w := diffusing(n, rng)
+					defer w.Stop()
+					if err := w.DetectTermination(algorithm); err != nil {
+						t.Fatalf("World.DetectTermination() error = %v", err)
+					}
+					for i := 0; i < starts; i++ {
+						w.Network.SendMessage(-1, rng.Int31n(n), spread(4))
+						w.Wait(rng.Int63n(20))
+					}
+					w.Run()
+					r, ok := w.Termination()
+					if !ok {
+						t.Fatalf("World.Termination() is not enabled")
+					}
+					if !r.Detected || r.Premature || r.Detection < r.Quiescence {
+						t.Errorf("World.Termination() = %+v", r)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestWorld_DetectTermination_Unknown(t *testing.T) {
+	w := New()
+	defer w.Stop()
+	if err := w.DetectTermination("UNKNOWN"); err == nil {
+		t.Errorf("World.DetectTermination() error = nil")
+	}
+	if _, ok := w.Termination(); ok {
+		t.Errorf("World.Termination() is enabled")
+	}
+}
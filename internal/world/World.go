@@ -6,10 +6,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/trmigor/distr-model/internal/clock"
+	"github.com/trmigor/distr-model/internal/dashboard"
 	"github.com/trmigor/distr-model/internal/errors"
 	"github.com/trmigor/distr-model/internal/messages"
 	"github.com/trmigor/distr-model/internal/network"
 	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/property"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/internal/replay"
+	"github.com/trmigor/distr-model/internal/scenario"
+	"github.com/trmigor/distr-model/internal/snapshot"
+	"github.com/trmigor/distr-model/internal/termination"
 )
 
 // World represents the whole distributed system.
@@ -17,34 +25,96 @@ type World struct {
 	Network       *network.Network
 	ProcessesList []*process.Process
 	Associates    map[string]process.WorkFunction
+	MaxTicks      int64
+	timers        []*timer
+	snapshots     *snapshot.Collector
+	termination   *termination.Detector
+	properties    *property.Checker
+	player        *replay.Player
+	beforeStep    func(node int32, m *messages.Message)
+	dashboard     *dashboard.Server
+	published     time.Time
+}
+
+// Options configures a world.
+type Options struct {
+	// Seed initialises the network random number generator, 0 means a time-based seed.
+	Seed int64
+	// Speed is the number of ticks per second of real time, 0 means virtual time.
+	Speed float64
+	// MaxTicks limits the model time, 0 means no limit.
+	MaxTicks int64
+	// Clocks is the kind of logical clocks maintained by the network for all the processes.
+	Clocks clock.Kind
 }
 
 // New creates a new instance of a world.
+// Its time goes in real time, one tick per second.
 func New() *World {
+	return NewWithOptions(Options{Speed: 1})
+}
+
+// NewWithOptions creates a new instance of a world configured by options.
+func NewWithOptions(o Options) *World {
+	var nl *network.Network
+	if o.Speed > 0 {
+		nl = network.New()
+		nl.SetSpeed(o.Speed)
+	} else {
+		nl = network.NewVirtual()
+	}
+	if o.Seed != 0 {
+		nl.Seed(o.Seed)
+	}
+	if o.Clocks != clock.None {
+		nl.EnableClocks(o.Clocks)
+	}
 	return &World{
-		Network:       network.New(),
+		Network:       nl,
 		ProcessesList: make([]*process.Process, 0),
 		Associates:    make(map[string]process.WorkFunction),
+		MaxTicks:      o.MaxTicks,
+		timers:        make([]*timer, 0),
 	}
 }
 
 // CreateProcess creates a new process for acquired node.
+// In virtual time processes have no worker goroutines, their messages are handled by the world.
 func (w *World) CreateProcess(node int32) int32 {
-	p := process.New(node)
+	var p *process.Process
+	if w.Network.IsVirtual() {
+		p = process.NewPassive(node)
+	} else {
+		p = process.New(node)
+	}
 	if node >= int32(len(w.ProcessesList)) {
 		w.ProcessesList = append(w.ProcessesList, make([]*process.Process, int(node)-len(w.ProcessesList)+1)...)
 	}
 	w.ProcessesList[node] = p
 	w.Network.RegisterProcess(node, p)
+	if w.snapshots != nil {
+		w.snapshots.Attach(p)
+	}
+	if w.termination != nil {
+		w.termination.Attach(p)
+	}
 	return node
 }
 
+// Nodes returns the sorted nodes of all the processes.
+func (w *World) Nodes() []int32 {
+	return w.Network.Nodes()
+}
+
 // RegisterWorkFunction registers a process working function.
 func (w *World) RegisterWorkFunction(function []byte, wf process.WorkFunction) {
 	w.Associates[string(function)] = wf
 }
 
 // AssignWorkFunction assigns a new function for the process with given node.
+// Functions registered in the world take precedence over the ones from the registry.
+// Contexts of a registry algorithm are created for the process unless it already has them.
+// The network is switched to FIFO mode if the algorithm requires it.
 func (w *World) AssignWorkFunction(node int32, function []byte) errors.ErrorCode {
 	if node < 0 || node >= int32(len(w.ProcessesList)) {
 		return errors.ItemNotFound
@@ -56,21 +126,57 @@ func (w *World) AssignWorkFunction(node int32, function []byte) errors.ErrorCode
 
 	it, ok := w.Associates[string(function)]
 	if !ok {
-		return errors.ItemNotFound
+		a, found := registry.Lookup(string(function))
+		if !found {
+			return errors.ItemNotFound
+		}
+		it = a.Work
+		if a.FIFO {
+			w.Network.FIFO = true
+		}
+		for key, create := range a.Contexts {
+			if _, ok := dp.Context[key]; !ok {
+				dp.Context[key] = create(dp.Node)
+			}
+		}
 	}
 
 	dp.RegisterWorkFunction(function, it)
 	return errors.OK
 }
 
+// SetContext sets the fields of the context of the process with given node.
+// A known context absent in the process is created first, so contexts can be initialised before work functions are assigned.
+func (w *World) SetContext(node int32, key string, fields map[string]string) error {
+	if node < 0 || node >= int32(len(w.ProcessesList)) || w.ProcessesList[node] == nil {
+		return fmt.Errorf("no process %v", node)
+	}
+	dp := w.ProcessesList[node]
+	if _, ok := dp.Context[key]; !ok {
+		create, found := registry.Context(key)
+		if !found {
+			return fmt.Errorf("unknown context %v", key)
+		}
+		dp.Context[key] = create(node)
+	}
+	return dp.SetContextFields(key, fields)
+}
+
 // ParseConfig parses the configuration file and launches the model.
 func (w *World) ParseConfig(name []byte) bool {
 	data, err := ioutil.ReadFile(string(name))
 	if err != nil {
 		return false
 	}
+	return w.LoadConfig(data)
+}
 
+// LoadConfig parses the configuration in the config.data syntax and launches the model.
+func (w *World) LoadConfig(data []byte) bool {
 	bidirected := 1
+	var bandwidth int64
+	var buffer int
+	limited := false
 	timeout := 0
 
 	dataLines := strings.Split(string(data), "\n")
@@ -85,6 +191,15 @@ func (w *World) ParseConfig(name []byte) bool {
 		var errorRate float64
 		var id, msg []byte
 
+		link := func(from scenario.Endpoint, to scenario.Endpoint) {
+			b := bidirected != 0
+			l := scenario.Link{From: from, To: to, Latency: &latency, Bidirected: &b}
+			if limited {
+				l.Bandwidth, l.Buffer = &bandwidth, &buffer
+			}
+			w.link(&l)
+		}
+
 		if read, err := fmt.Sscanf(dataLines[i], "processes %d %d", &startprocess, &endprocess); err == nil && read == 2 {
 			for i := startprocess; i <= endprocess; i++ {
 				w.CreateProcess(i)
@@ -96,13 +211,37 @@ func (w *World) ParseConfig(name []byte) bool {
 			continue
 		}
 
+		if read, err := fmt.Sscanf(dataLines[i], "bandwidth %d", &bandwidth); err == nil && read == 1 {
+			limited = true
+			continue
+		}
+
+		if read, err := fmt.Sscanf(dataLines[i], "buffer %d", &buffer); err == nil && read == 1 {
+			limited = true
+			continue
+		}
+
 		if read, err := fmt.Sscanf(dataLines[i], "errorRate %f", &errorRate); err == nil && read == 1 {
 			w.Network.SetErrorRate(errorRate)
 			continue
 		}
 
+		if read, err := fmt.Sscanf(dataLines[i], "byzantine %d %d strategy %s", &startprocess, &endprocess, &id); err == nil && read == 3 {
+			if w.SetByzantine(startprocess, endprocess, string(id)) != nil {
+				return false
+			}
+			continue
+		}
+
+		if read, err := fmt.Sscanf(dataLines[i], "byzantine %d strategy %s", &startprocess, &id); err == nil && read == 2 {
+			if w.SetByzantine(startprocess, startprocess, string(id)) != nil {
+				return false
+			}
+			continue
+		}
+
 		if read, err := fmt.Sscanf(dataLines[i], "link from all to all latency %d", &latency); (err == nil && read == 1) || dataLines[i] == "link from all to all" {
-			w.Network.AddLinksAllToAll(bidirected != 0, latency)
+			link(scenario.All(), scenario.All())
 			continue
 		}
 
@@ -116,6 +255,22 @@ func (w *World) ParseConfig(name []byte) bool {
 			continue
 		}
 
+		if strings.HasPrefix(dataLines[i], "context ") {
+			c, err := scenario.ParseContext(dataLines[i])
+			if err != nil || w.setContexts(c) != nil {
+				return false
+			}
+			continue
+		}
+
+		if strings.HasPrefix(dataLines[i], "invariant ") || strings.HasPrefix(dataLines[i], "eventually ") {
+			p, err := scenario.ParseProperty(dataLines[i])
+			if err != nil || w.addProperty(p) != nil {
+				return false
+			}
+			continue
+		}
+
 		if read, err := fmt.Sscanf(dataLines[i], "send from %d to %d %s %d", &from, &to, &msg, &arg); read == 4 && err == nil {
 			w.Network.SendMessage(from, to, messages.NewMessageByArgs(messages.NewMessageArg(msg), messages.NewMessageArg(arg)))
 			continue
@@ -127,42 +282,66 @@ func (w *World) ParseConfig(name []byte) bool {
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "wait %d", &timeout); read == 1 && err == nil {
-			time.Sleep(time.Duration(timeout) * time.Second)
+			w.Wait(int64(timeout))
+			continue
+		}
+
+		if read, err := fmt.Sscanf(dataLines[i], "termination %s", &id); read == 1 && err == nil {
+			if w.DetectTermination(string(id)) != nil {
+				return false
+			}
+			continue
+		}
+
+		if read, err := fmt.Sscanf(dataLines[i], "unlink from %d to %d", &from, &to); read == 2 && err == nil {
+			w.Network.RemoveLink(from, to, bidirected != 0)
+			continue
+		}
+
+		if read, err := fmt.Sscanf(dataLines[i], "routing %s", &id); read == 1 && err == nil {
+			if w.SetRouting(string(id)) != nil {
+				return false
+			}
+			continue
+		}
+
+		if read, err := fmt.Sscanf(dataLines[i], "snapshot %d", &from); read == 1 && err == nil {
+			w.Snapshot(from)
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "launch timer %d", &timer); read == 1 && err == nil {
-			go network.TimerSender(w.Network, timer)
+			w.LaunchTimer(timer)
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "link from %d to %d latency %d", &from, &to, &latency); err == nil && read == 3 {
-			w.Network.CreateLink(from, to, bidirected != 0, latency)
+			link(scenario.Node(from), scenario.Node(to))
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "link from %d to %d", &from, &to); err == nil && read == 2 {
-			w.Network.CreateLink(from, to, bidirected != 0, latency)
+			link(scenario.Node(from), scenario.Node(to))
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "link from %d to all latency %d", &from, &latency); err == nil && read == 2 {
-			w.Network.AddLinksToAll(from, bidirected != 0, latency)
+			link(scenario.Node(from), scenario.All())
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "link from %d to all", &from); err == nil && read == 1 {
-			w.Network.AddLinksToAll(from, bidirected != 0, latency)
+			link(scenario.Node(from), scenario.All())
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "link from all to %d latency %d", &to, &latency); err == nil && read == 2 {
-			w.Network.AddLinksFromAll(to, bidirected != 0, latency)
+			link(scenario.All(), scenario.Node(to))
 			continue
 		}
 
 		if read, err := fmt.Sscanf(dataLines[i], "link from all to %d", &to); err == nil && read == 1 {
-			w.Network.AddLinksFromAll(to, bidirected != 0, latency)
+			link(scenario.All(), scenario.Node(to))
 			continue
 		}
 
@@ -172,8 +351,174 @@ func (w *World) ParseConfig(name []byte) bool {
 	return true
 }
 
-// Stop terminates the model work.
-// It should be called at the end of model usage.
+// ParseScenario reads a JSON or YAML scenario file and launches the model.
+func (w *World) ParseScenario(name []byte) bool {
+	s, err := scenario.Load(string(name))
+	if err != nil {
+		return false
+	}
+	return w.ApplyScenario(s)
+}
+
+// ApplyScenario launches the model described by a structured scenario.
+// The model is prepared first, then the schedule is performed.
+func (w *World) ApplyScenario(s *scenario.Scenario) bool {
+	if !w.Prepare(s) {
+		return false
+	}
+
+	for _, st := range s.Schedule {
+		switch {
+		case st.Send != nil:
+			w.send(st.Send)
+		case st.Wait != nil:
+			w.Wait(int64(*st.Wait))
+		case st.Timer > 0:
+			w.LaunchTimer(st.Timer)
+		case st.Link != nil:
+			w.link(st.Link)
+		case st.Unlink != nil:
+			w.unlink(st.Unlink)
+		case st.Snapshot != nil:
+			w.Snapshot(*st.Snapshot)
+		case st.ErrorRate != nil:
+			w.Network.SetErrorRate(*st.ErrorRate)
+		}
+	}
+	return true
+}
+
+// Prepare sets up the model described by a structured scenario without performing its schedule:
+// processes, termination detection, routing, faults, links, assignments, contexts and properties are set up,
+// then initial messages are sent and the properties are checked in the initial state.
+func (w *World) Prepare(s *scenario.Scenario) bool {
+	for _, r := range s.Processes {
+		for i := r.From; i <= r.To; i++ {
+			w.CreateProcess(i)
+		}
+	}
+
+	if s.Termination != "" && w.DetectTermination(s.Termination) != nil {
+		return false
+	}
+
+	if s.Routing != "" && w.SetRouting(s.Routing) != nil {
+		return false
+	}
+
+	if s.Faults != nil {
+		w.Network.SetErrorRate(s.Faults.ErrorRate)
+		for _, b := range s.Faults.Byzantine {
+			if w.SetByzantine(b.From, b.To, b.Strategy) != nil {
+				return false
+			}
+		}
+	}
+
+	for i := range s.Links {
+		w.link(&s.Links[i])
+	}
+
+	for _, a := range s.Assignments {
+		for i := a.From; i <= a.To; i++ {
+			if w.AssignWorkFunction(i, []byte(a.Function)) != errors.OK {
+				return false
+			}
+		}
+	}
+
+	for i := range s.Contexts {
+		if w.setContexts(&s.Contexts[i]) != nil {
+			return false
+		}
+	}
+
+	for i := range s.Properties {
+		if w.addProperty(&s.Properties[i]) != nil {
+			return false
+		}
+	}
+
+	for i := range s.Messages {
+		w.send(&s.Messages[i])
+	}
+	w.checkpoint()
+	return true
+}
+
+// link creates or changes the links described by the scenario link and sets their capacity, if any.
+func (w *World) link(l *scenario.Link) {
+	switch {
+	case l.From.All && l.To.All:
+		w.Network.AddLinksAllToAll(l.IsBidirected(), l.Cost())
+	case l.From.All:
+		w.Network.AddLinksFromAll(l.To.Node, l.IsBidirected(), l.Cost())
+	case l.To.All:
+		w.Network.AddLinksToAll(l.From.Node, l.IsBidirected(), l.Cost())
+	default:
+		w.Network.CreateLink(l.From.Node, l.To.Node, l.IsBidirected(), l.Cost())
+	}
+	bandwidth, buffer, ok := l.Capacity()
+	if !ok {
+		return
+	}
+	c := network.Capacity{Bandwidth: bandwidth, Buffer: buffer}
+	w.links(l, func(from int32, to int32) {
+		w.Network.SetCapacity(from, to, l.IsBidirected(), c)
+	})
+}
+
+// unlink removes the links described by the scenario link.
+func (w *World) unlink(l *scenario.Link) {
+	w.links(l, func(from int32, to int32) {
+		w.Network.RemoveLink(from, to, l.IsBidirected())
+	})
+}
+
+// links calls the function for all the pairs of processes described by the scenario link.
+func (w *World) links(l *scenario.Link, f func(from int32, to int32)) {
+	if !l.From.All && !l.To.All {
+		f(l.From.Node, l.To.Node)
+		return
+	}
+	for _, from := range w.Nodes() {
+		for _, to := range w.Nodes() {
+			if from != to && (l.From.All || l.From.Node == from) && (l.To.All || l.To.Node == to) {
+				f(from, to)
+			}
+		}
+	}
+}
+
+func (w *World) setContexts(c *scenario.Context) error {
+	values := c.Values()
+	for i := c.From; i <= c.To; i++ {
+		if err := w.SetContext(i, c.Name, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *World) addProperty(p *scenario.Property) error {
+	res, err := p.Property()
+	if err != nil {
+		return err
+	}
+	w.AddProperty(res)
+	return nil
+}
+
+func (w *World) send(m *scenario.Send) {
+	args := []*messages.MessageArg{messages.NewMessageArg([]byte(m.Type))}
+	if m.Arg != nil {
+		args = append(args, messages.NewMessageArg(*m.Arg))
+	}
+	w.Network.SendMessage(m.From, m.To, messages.NewMessageByArgs(args...))
+}
+
+// Stop terminates the model work and the checks of the properties and publishes the final state
+// to the dashboard, which goes on serving. It should be called at the end of model usage.
 func (w *World) Stop() {
 	w.Network.Stop()
 	for _, p := range w.ProcessesList {
@@ -181,4 +526,9 @@ func (w *World) Stop() {
 			p.Stop()
 		}
 	}
+	if w.properties != nil {
+		w.checkProperties()
+		w.properties.Finish(w.Network.Tick, w.ProcessesList)
+	}
+	w.publish(true)
 }
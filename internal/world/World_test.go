@@ -6,7 +6,10 @@ import (
 
 	"github.com/trmigor/distr-model/internal/errors"
 	"github.com/trmigor/distr-model/internal/messages"
+	"github.com/trmigor/distr-model/internal/network"
 	"github.com/trmigor/distr-model/internal/process"
+	"github.com/trmigor/distr-model/internal/registry"
+	"github.com/trmigor/distr-model/user/context"
 )
 
 func TestWorld_CreateProcess(t *testing.T) {
@@ -97,6 +100,80 @@ func TestWorld_AssignWorkFunction(t *testing.T) {
 	}
 }
 
+func TestWorld_AssignWorkFunction_Registry(t *testing.T) {
+	registry.Register(registry.Algorithm{
+		Name: "WORLD_TEST",
+		Work: func(context *process.Process, m *messages.Message) bool {
+			return false
+		},
+		Contexts: map[string]context.Factory{
+			"WorldTest": func(node int32) context.Context { return node + 1 },
+		},
+	})
+	w := New()
+	defer w.Stop()
+	w.CreateProcess(0)
+	w.CreateProcess(1)
+	w.ProcessesList[1].Context["WorldTest"] = 2
+	for node, want := range []context.Context{int32(1), 2} {
+		if got := w.AssignWorkFunction(int32(node), []byte("WORLD_TEST")); got != errors.OK {
+			t.Fatalf("World.AssignWorkFunction() = %v, want %v", got, errors.OK)
+		}
+		if got := w.ProcessesList[node].Context["WorldTest"]; got != want {
+			t.Errorf("Process.Context[WorldTest] = %v, want %v", got, want)
+		}
+	}
+}
+
+type worldContext struct {
+	X int
+}
+
+func TestWorld_SetContext(t *testing.T) {
+	registry.Register(registry.Algorithm{
+		Name: "WORLD_CONTEXT",
+		Work: func(context *process.Process, m *messages.Message) bool {
+			return false
+		},
+		Contexts: map[string]context.Factory{
+			"WorldContext": func(int32) context.Context { return &worldContext{} },
+		},
+	})
+	tests := []struct {
+		name    string
+		node    int32
+		key     string
+		fields  map[string]string
+		want    int
+		wantErr bool
+	}{
+		{"Valid", 0, "WorldContext", map[string]string{"X": "7"}, 7, false},
+		{"NoProcess", 2, "WorldContext", map[string]string{"X": "7"}, 0, true},
+		{"UnknownContext", 0, "None", nil, 0, true},
+		{"NoField", 0, "WorldContext", map[string]string{"Y": "7"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New()
+			defer w.Stop()
+			w.CreateProcess(0)
+			err := w.SetContext(tt.node, tt.key, tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("World.SetContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if w.AssignWorkFunction(0, []byte("WORLD_CONTEXT")) != errors.OK {
+				t.Fatalf("World.AssignWorkFunction() failed")
+			}
+			if got := process.Ctx[*worldContext](w.ProcessesList[0], tt.key).X; got != tt.want {
+				t.Errorf("World.SetContext(): X = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestWorld_ParseConfig(t *testing.T) {
 	type args struct {
 		name []byte
@@ -115,6 +192,8 @@ func TestWorld_ParseConfig(t *testing.T) {
 		{"AllToAllLatency", args{[]byte("../../test/data/config/AllToAllLatency.data")}, true},
 		{"SetProcesses", args{[]byte("../../test/data/config/SetProcesses.data")}, true},
 		{"SetProcessesInvalid", args{[]byte("../../test/data/config/SetProcessesInvalid.data")}, false},
+		{"Context", args{[]byte("../../test/data/config/Context.data")}, true},
+		{"ContextInvalid", args{[]byte("../../test/data/config/ContextInvalid.data")}, false},
 		{"SendMsgArg", args{[]byte("../../test/data/config/SendMsgArg.data")}, true},
 		{"SendMsg", args{[]byte("../../test/data/config/SendMsg.data")}, true},
 		{"Wait", args{[]byte("../../test/data/config/Wait.data")}, true},
@@ -125,6 +204,16 @@ func TestWorld_ParseConfig(t *testing.T) {
 		{"LinkToAll", args{[]byte("../../test/data/config/LinkToAll.data")}, true},
 		{"LinkFromAllLatency", args{[]byte("../../test/data/config/LinkFromAllLatency.data")}, true},
 		{"LinkFromAll", args{[]byte("../../test/data/config/LinkFromAll.data")}, true},
+		{"Termination", args{[]byte("../../test/data/config/Termination.data")}, true},
+		{"TerminationInvalid", args{[]byte("../../test/data/config/TerminationInvalid.data")}, false},
+		{"Byzantine", args{[]byte("../../test/data/config/Byzantine.data")}, true},
+		{"ByzantineInvalid", args{[]byte("../../test/data/config/ByzantineInvalid.data")}, false},
+		{"Routing", args{[]byte("../../test/data/config/Routing.data")}, true},
+		{"RoutingInvalid", args{[]byte("../../test/data/config/RoutingInvalid.data")}, false},
+		{"Unlink", args{[]byte("../../test/data/config/Unlink.data")}, true},
+		{"Capacity", args{[]byte("../../test/data/config/Capacity.data")}, true},
+		{"Property", args{[]byte("../../test/data/config/Property.data")}, true},
+		{"PropertyInvalid", args{[]byte("../../test/data/config/PropertyInvalid.data")}, false},
 		{"Unknown", args{[]byte("../../test/data/config/Unknown.data")}, true},
 	}
 	for _, tt := range tests {
@@ -148,3 +237,83 @@ func TestWorld_ParseConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestWorld_ParseScenario(t *testing.T) {
+	type args struct {
+		name []byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"InvalidFile", args{[]byte("../../test/data/scenario/InvalidFile.yaml")}, false},
+		{"YAML", args{[]byte("../../test/data/scenario/Valid.yaml")}, true},
+		{"JSON", args{[]byte("../../test/data/scenario/Valid.json")}, true},
+		{"UnknownField", args{[]byte("../../test/data/scenario/UnknownField.json")}, false},
+		{"SetProcessesInvalid", args{[]byte("../../test/data/scenario/SetProcessesInvalid.yaml")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New()
+			defer w.Stop()
+			f := func(context *process.Process, m *messages.Message) bool {
+				return true
+			}
+			w.RegisterWorkFunction([]byte("SETX"), f)
+			if got := w.ParseScenario(tt.args.name); got != tt.want {
+				t.Errorf("World.ParseScenario() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorld_ApplyScenario(t *testing.T) {
+	w := New()
+	defer w.Stop()
+	w.RegisterWorkFunction([]byte("SETX"), func(context *process.Process, m *messages.Message) bool {
+		return true
+	})
+	if !w.ParseScenario([]byte("../../test/data/scenario/Valid.yaml")) {
+		t.Fatalf("World.ParseScenario() = false")
+	}
+	if len(w.ProcessesList) != 4 {
+		t.Errorf("World.ApplyScenario(): %v processes created, want 4", len(w.ProcessesList))
+	}
+	if w.Network.ErrorRate != 0.1 {
+		t.Errorf("World.ApplyScenario(): error rate %v, want 0.1", w.Network.ErrorRate)
+	}
+	links := []struct {
+		from, to, want int32
+	}{
+		{0, 3, 2}, {3, 0, 2}, {1, 2, 1}, {2, 1, -1}, {1, 3, 4}, {0, 1, -1}, {1, 0, -1},
+	}
+	for _, l := range links {
+		if got := w.Network.GetLink(l.from, l.to); got != l.want {
+			t.Errorf("World.ApplyScenario(): link %v->%v = %v, want %v", l.from, l.to, got, l.want)
+		}
+	}
+	if got, want := w.Network.GetCapacity(1, 2), (network.Capacity{Bandwidth: 10, Buffer: 3}); got != want {
+		t.Errorf("World.ApplyScenario(): capacity of link 1->2 = %v, want %v", got, want)
+	}
+}
+
+func TestWorld_ParseConfig_Capacity(t *testing.T) {
+	w := New()
+	defer w.Stop()
+	if !w.ParseConfig([]byte("../../test/data/config/Capacity.data")) {
+		t.Fatalf("World.ParseConfig() = false")
+	}
+	limited := network.Capacity{Bandwidth: 10, Buffer: 2}
+	links := []struct {
+		from, to int32
+		want     network.Capacity
+	}{
+		{0, 1, limited}, {1, 0, limited}, {2, 3, limited}, {3, 0, limited}, {1, 2, network.Capacity{}},
+	}
+	for _, l := range links {
+		if got := w.Network.GetCapacity(l.from, l.to); got != l.want {
+			t.Errorf("Network.GetCapacity(%v, %v) = %v, want %v", l.from, l.to, got, l.want)
+		}
+	}
+}
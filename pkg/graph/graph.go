@@ -0,0 +1,188 @@
+package graph
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Graph is a weighted directed graph: Graph[from][to] is a weight of the edge.
+type Graph map[int32]map[int32]int32
+
+// New creates an empty graph
+func New() Graph {
+	return make(Graph)
+}
+
+// AddEdge adds an edge or updates its weight
+func (g Graph) AddEdge(from int32, to int32, weight int32) {
+	if _, ok := g[from]; !ok {
+		g[from] = make(map[int32]int32)
+	}
+	g[from][to] = weight
+}
+
+// HasEdge checks whether the graph contains the edge
+func (g Graph) HasEdge(from int32, to int32) bool {
+	_, ok := g[from][to]
+	return ok
+}
+
+// Neighbours returns the sorted list of vertices adjacent to the vertex
+func (g Graph) Neighbours(from int32) []int32 {
+	res := make([]int32, 0, len(g[from]))
+	for v := range g[from] {
+		res = append(res, v)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// Hops returns the number of edges on the shortest path from the vertex to every reachable vertex
+func (g Graph) Hops(from int32) map[int32]int {
+	res := map[int32]int{from: 0}
+	queue := []int32{from}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, u := range g.Neighbours(v) {
+			if _, ok := res[u]; !ok {
+				res[u] = res[v] + 1
+				queue = append(queue, u)
+			}
+		}
+	}
+	return res
+}
+
+// Diameter returns the greatest number of hops between two vertices of the list.
+// Pairs of mutually unreachable vertices are skipped, the second result is false if there were any.
+func (g Graph) Diameter(vertices []int32) (int, bool) {
+	res, connected := 0, true
+	for _, v := range vertices {
+		hops := g.Hops(v)
+		for _, u := range vertices {
+			d, ok := hops[u]
+			if !ok {
+				connected = false
+				continue
+			}
+			if d > res {
+				res = d
+			}
+		}
+	}
+	return res, connected
+}
+
+// MinimumSpanningTree returns the weight of the minimum spanning tree over the vertices of the list.
+// Edges are treated as undirected, the lighter direction is taken if the weights differ.
+// The second result is false if the vertices are not connected.
+func (g Graph) MinimumSpanningTree(vertices []int32) (int64, bool) {
+	type edge struct {
+		from, to, weight int32
+	}
+	in := make(map[int32]bool, len(vertices))
+	for _, v := range vertices {
+		in[v] = true
+	}
+	edges := make([]edge, 0)
+	for _, v := range vertices {
+		for _, u := range g.Neighbours(v) {
+			if in[u] && u != v {
+				edges = append(edges, edge{v, u, g[v][u]})
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].weight < edges[j].weight })
+
+	parent := make(map[int32]int32, len(vertices))
+	var find func(v int32) int32
+	find = func(v int32) int32 {
+		if p, ok := parent[v]; ok && p != v {
+			parent[v] = find(p)
+			return parent[v]
+		}
+		return v
+	}
+	var res int64
+	joined := 1
+	for _, e := range edges {
+		a, b := find(e.from), find(e.to)
+		if a != b {
+			parent[a] = b
+			res += int64(e.weight)
+			joined++
+		}
+	}
+	return res, joined >= len(in) || len(in) == 0
+}
+
+// distance is a vertex with the weight of a path to it, an item of the Dijkstra heap
+type distance struct {
+	vertex int32
+	weight int64
+}
+
+// distances is a min-heap of distances ordered by weights, then by vertices
+type distances []distance
+
+func (d distances) Len() int { return len(d) }
+func (d distances) Less(i, j int) bool {
+	if d[i].weight == d[j].weight {
+		return d[i].vertex < d[j].vertex
+	}
+	return d[i].weight < d[j].weight
+}
+func (d distances) Swap(i, j int)       { d[i], d[j] = d[j], d[i] }
+func (d *distances) Push(x interface{}) { *d = append(*d, x.(distance)) }
+func (d *distances) Pop() interface{} {
+	old := *d
+	res := old[len(old)-1]
+	*d = old[:len(old)-1]
+	return res
+}
+
+// ShortestPaths returns the weights of the lightest paths from the vertex to every reachable vertex
+// and the previous vertices on them. Weights must not be negative.
+// Vertices are visited in order of weights, then of numbers, so the paths are deterministic.
+func (g Graph) ShortestPaths(from int32) (map[int32]int64, map[int32]int32) {
+	dist := map[int32]int64{from: 0}
+	prev := make(map[int32]int32)
+	done := make(map[int32]bool)
+	queue := &distances{{from, 0}}
+	for queue.Len() > 0 {
+		d := heap.Pop(queue).(distance)
+		if done[d.vertex] {
+			continue
+		}
+		done[d.vertex] = true
+		for _, u := range g.Neighbours(d.vertex) {
+			w := d.weight + int64(g[d.vertex][u])
+			if old, ok := dist[u]; !ok || w < old {
+				dist[u] = w
+				prev[u] = d.vertex
+				heap.Push(queue, distance{u, w})
+			}
+		}
+	}
+	return dist, prev
+}
+
+// ShortestPath returns the lightest path from the vertex to the other one, including both of them, and its weight.
+// The third result is false if the other vertex is unreachable.
+func (g Graph) ShortestPath(from int32, to int32) ([]int32, int64, bool) {
+	dist, prev := g.ShortestPaths(from)
+	w, ok := dist[to]
+	if !ok {
+		return nil, 0, false
+	}
+	res := []int32{to}
+	for v := to; v != from; {
+		v = prev[v]
+		res = append(res, v)
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res, w, true
+}
@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func line(n int32) Graph {
+	g := New()
+	for i := int32(0); i+1 < n; i++ {
+		g.AddEdge(i, i+1, 1)
+		g.AddEdge(i+1, i, 1)
+	}
+	return g
+}
+
+func TestGraph_AddEdge(t *testing.T) {
+	g := New()
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(0, 1, 7)
+	if !g.HasEdge(0, 1) || g[0][1] != 7 {
+		t.Errorf("Graph.AddEdge(): edge is not updated")
+	}
+	if g.HasEdge(1, 0) {
+		t.Errorf("Graph.AddEdge(): reverse edge is added")
+	}
+}
+
+func TestGraph_Neighbours(t *testing.T) {
+	g := New()
+	g.AddEdge(0, 3, 1)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 1)
+	if got, want := g.Neighbours(0), []int32{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Graph.Neighbours() = %v, want %v", got, want)
+	}
+	if got := g.Neighbours(5); len(got) != 0 {
+		t.Errorf("Graph.Neighbours() = %v, want empty", got)
+	}
+}
+
+func TestGraph_Hops(t *testing.T) {
+	g := line(4)
+	g.AddEdge(5, 0, 1)
+	want := map[int32]int{0: 0, 1: 1, 2: 2, 3: 3}
+	if got := g.Hops(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Graph.Hops() = %v, want %v", got, want)
+	}
+}
+
+func TestGraph_Diameter(t *testing.T) {
+	tests := []struct {
+		name          string
+		graph         Graph
+		vertices      []int32
+		want          int
+		wantConnected bool
+	}{
+		{"Line", line(5), []int32{0, 1, 2, 3, 4}, 4, true},
+		{"Single", New(), []int32{0}, 0, true},
+		{"Disconnected", line(3), []int32{0, 1, 2, 7}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, connected := tt.graph.Diameter(tt.vertices)
+			if got != tt.want || connected != tt.wantConnected {
+				t.Errorf("Graph.Diameter() = %v, %v, want %v, %v", got, connected, tt.want, tt.wantConnected)
+			}
+		})
+	}
+}
+
+func TestGraph_MinimumSpanningTree(t *testing.T) {
+	square := New()
+	for _, e := range [][3]int32{{0, 1, 1}, {1, 2, 2}, {2, 3, 3}, {3, 0, 4}, {0, 2, 5}} {
+		square.AddEdge(e[0], e[1], e[2])
+		square.AddEdge(e[1], e[0], e[2])
+	}
+	tests := []struct {
+		name          string
+		graph         Graph
+		vertices      []int32
+		want          int64
+		wantConnected bool
+	}{
+		{"Line", line(5), []int32{0, 1, 2, 3, 4}, 4, true},
+		{"Square", square, []int32{0, 1, 2, 3}, 6, true},
+		{"Subset", square, []int32{0, 2, 3}, 7, true},
+		{"Single", New(), []int32{0}, 0, true},
+		{"Disconnected", line(3), []int32{0, 1, 2, 7}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, connected := tt.graph.MinimumSpanningTree(tt.vertices)
+			if got != tt.want || connected != tt.wantConnected {
+				t.Errorf("Graph.MinimumSpanningTree() = %v, %v, want %v, %v", got, connected, tt.want, tt.wantConnected)
+			}
+		})
+	}
+}
+
+func TestGraph_ShortestPath(t *testing.T) {
+	g := New()
+	for _, e := range [][3]int32{{0, 1, 1}, {1, 2, 1}, {0, 2, 5}, {2, 3, 1}, {0, 4, 2}, {4, 3, 1}, {3, 5, 0}} {
+		g.AddEdge(e[0], e[1], e[2])
+		g.AddEdge(e[1], e[0], e[2])
+	}
+	g.AddEdge(6, 0, 1)
+	tests := []struct {
+		name     string
+		from, to int32
+		want     []int32
+		weight   int64
+		wantOk   bool
+	}{
+		{"Self", 0, 0, []int32{0}, 0, true},
+		{"Neighbour", 0, 1, []int32{0, 1}, 1, true},
+		{"Lighter", 0, 2, []int32{0, 1, 2}, 2, true},
+		{"Tie", 0, 3, []int32{0, 1, 2, 3}, 3, true},
+		{"Zero", 0, 5, []int32{0, 1, 2, 3, 5}, 3, true},
+		{"Directed", 0, 6, nil, 0, false},
+		{"Unknown", 0, 9, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, weight, ok := g.ShortestPath(tt.from, tt.to)
+			if !reflect.DeepEqual(got, tt.want) || weight != tt.weight || ok != tt.wantOk {
+				t.Errorf("Graph.ShortestPath() = %v, %v, %v, want %v, %v, %v", got, weight, ok, tt.want, tt.weight, tt.wantOk)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 type Item struct {
 	Value    interface{} // The value of the item; arbitrary.
 	Priority int         // The priority of the item in the queue.
+	Order    int         // The order of the item among items of the same priority.
 	// The index is needed by update and is maintained by the heap.Interface methods.
 	Index int // The index of the item in the heap.
 }
@@ -21,8 +22,12 @@ func (pq PriorityQueue) Len() int {
 }
 
 // Less returns is the j-th element priority of the pq PriorityQueue less than i-th element priority
+// Items of the same priority are ordered by their Order
 func (pq PriorityQueue) Less(i, j int) bool {
 	// We want Pop to give us the highest, not lowest, priority so we use "greater than" here.
+	if pq[i].Priority == pq[j].Priority {
+		return pq[i].Order < pq[j].Order
+	}
 	return pq[i].Priority > pq[j].Priority
 }
 
@@ -53,8 +58,7 @@ func (pq *PriorityQueue) Pop() interface{} {
 
 // Top gets the highest-priority item from the PriorityQueue
 func (pq *PriorityQueue) Top() interface{} {
-	queue := *pq
-	return (queue)[len(queue)-1]
+	return (*pq)[0]
 }
 
 // update modifies the priority and value of an Item in the queue.
@@ -38,6 +38,8 @@ func TestPriorityQueue_Less(t *testing.T) {
 		{"Less", PriorityQueue{&Item{Priority: 1}, &Item{Priority: 0}}, args{0, 1}, true},
 		{"Equal", PriorityQueue{&Item{Priority: 0}, &Item{Priority: 0}}, args{0, 1}, false},
 		{"LeGreaterss", PriorityQueue{&Item{Priority: 0}, &Item{Priority: 1}}, args{0, 1}, false},
+		{"EarlierOrder", PriorityQueue{&Item{Priority: 0, Order: 0}, &Item{Priority: 0, Order: 1}}, args{0, 1}, true},
+		{"LaterOrder", PriorityQueue{&Item{Priority: 0, Order: 1}, &Item{Priority: 0, Order: 0}}, args{0, 1}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1,18 +1,18 @@
 package context
 
 // Context represents user-defined context for a process.
+// Contexts are usually pointers to structures, so that work functions can change them in place.
 type Context interface{}
 
+// Factory creates a new context for the process with the given node.
+// Each process gets its own context, so factories must not return shared values.
+type Factory func(node int32) Context
+
 // Common is a common context without any fields.
 type Common struct{}
 
-// SetX is a context for setting one value algorithm.
-type SetX struct {
-	X int
-}
-
-// Contexts is a map of all contexts available to processes.
-var Contexts = map[string]Context{
-	"Common": Common{},
-	"SetX":   SetX{},
+// Contexts is a map of factories of contexts available to all processes.
+// Contexts of registered algorithms are added when their work functions are assigned.
+var Contexts = map[string]Factory{
+	"Common": func(int32) Context { return &Common{} },
 }